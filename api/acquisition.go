@@ -1,9 +1,7 @@
 package api
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
 	"time"
 )
 
@@ -28,18 +26,18 @@ type Acquisition struct {
 	Permissions []*Permission `json:"permissions,omitempty"`
 }
 
-func (c *Client) GetAllAcquisitions() ([]*Acquisition, *http.Response, error) {
-	var aerr *Error
-	var acquisitions []*Acquisition
-	resp, err := c.New().Get("acquisitions").Receive(&acquisitions, &aerr)
-	return acquisitions, resp, Coalesce(err, aerr)
+func (c *Client) GetAllAcquisitions(options ...*ListOptions) ([]*Acquisition, *http.Response, error) {
+	acquisitions := []*Acquisition{}
+	it := c.IterateAcquisitions(options...)
+	err := it.collect(func(x interface{}) { acquisitions = append(acquisitions, x.(*Acquisition)) })
+	return acquisitions, it.lastResponse, err
 }
 
 func (c *Client) GetAcquisition(id string) (*Acquisition, *http.Response, error) {
 	var aerr *Error
 	var acquisition *Acquisition
 	resp, err := c.New().Get("acquisitions/"+id).Receive(&acquisition, &aerr)
-	return acquisition, resp, Coalesce(err, aerr)
+	return acquisition, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddAcquisition(acquisition *Acquisition) (string, *http.Response, error) {
@@ -53,150 +51,134 @@ func (c *Client) AddAcquisition(acquisition *Acquisition) (string, *http.Respons
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddAcquisitionNote(id, text string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
-
-	note := &Note{
-		Text: text,
-	}
-
-	resp, err := c.New().Post("acquisitions/"+id+"/notes").BodyJSON(note).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying acquisition " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
-
-	return resp, Coalesce(err, aerr)
+	return c.Container(AcquisitionContainer, id).AddNote(text)
 }
 
 func (c *Client) AddAcquisitionTag(id, tag string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
+	return c.Container(AcquisitionContainer, id).AddTag(tag)
+}
 
-	var tagDoc interface{}
-	tagDoc = map[string]interface{}{
-		"value": tag,
-	}
+func (c *Client) DeleteAcquisitionTag(id, tag string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).DeleteTag(tag)
+}
 
-	resp, err := c.New().Post("acquisitions/"+id+"/tags").BodyJSON(tagDoc).Receive(&response, &aerr)
+func (c *Client) RenameAcquisitionTag(id, tag, newTag string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).RenameTag(tag, newTag)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying acquisition " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+func (c *Client) ModifyAcquisitionNote(id, noteId, text string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).ModifyNote(noteId, text)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteAcquisitionNote(id, noteId string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).DeleteNote(noteId)
 }
 
 func (c *Client) ModifyAcquisition(id string, acquisition *Acquisition) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
+	return c.Container(AcquisitionContainer, id).Modify(acquisition)
+}
 
-	resp, err := c.New().Put("acquisitions/"+id).BodyJSON(acquisition).Receive(&response, &aerr)
+// ModifyAcquisitionIfUnchanged changes an acquisition, as ModifyAcquisition does, unless it was modified since the given time. See Container.ModifyIfUnchanged.
+func (c *Client) ModifyAcquisitionIfUnchanged(id string, modified *time.Time, acquisition *Acquisition) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).ModifyIfUnchanged(modified, acquisition)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying acquisition " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+// UpdateAcquisition reads an acquisition, passes it to merge, and makes the changes merge returns, reading and merging again
+// if someone else modified the acquisition in between. See Container.Update.
+func (c *Client) UpdateAcquisition(id string, merge func(acquisition *Acquisition) (*Acquisition, error), options *UpdateOptions) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).Update(func() interface{} { return &Acquisition{} }, func(current interface{}) (interface{}, error) {
+		return merge(current.(*Acquisition))
+	}, options)
+}
 
-	return resp, Coalesce(err, aerr)
+// MoveAcquisition gives an acquisition a new session. See Move.
+func (c *Client) MoveAcquisition(id, sessionId string, options *MoveOptions) (*MoveReport, error) {
+	return c.Move(c.Container(AcquisitionContainer, id), sessionId, options)
+}
+
+// CopyAcquisition copies an acquisition to a session. See Copy.
+func (c *Client) CopyAcquisition(id, sessionId string, options *CopyOptions) (string, error) {
+	return c.Copy(c.Container(AcquisitionContainer, id), sessionId, options)
 }
 
 func (c *Client) SetAcquisitionInfo(id string, set map[string]interface{}) (*http.Response, error) {
-	url := "acquisitions/" + id + "/info"
-	return c.setInfo(url, set, false)
+	return c.Container(AcquisitionContainer, id).SetInfo(set)
 }
 
 func (c *Client) ReplaceAcquisitionInfo(id string, replace map[string]interface{}) (*http.Response, error) {
-	url := "acquisitions/" + id + "/info"
-	return c.replaceInfo(url, replace, false)
+	return c.Container(AcquisitionContainer, id).ReplaceInfo(replace)
 }
 
 func (c *Client) DeleteAcquisitionInfoFields(id string, keys []string) (*http.Response, error) {
-	url := "acquisitions/" + id + "/info"
-	return c.deleteInfoFields(url, keys, false)
+	return c.Container(AcquisitionContainer, id).DeleteInfoFields(keys)
 }
 
-func (c *Client) DeleteAcquisition(id string) (*http.Response, error) {
-	var aerr *Error
-	var response *DeletedResponse
-
-	resp, err := c.New().Delete("acquisitions/"+id).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.DeletedCount != 1 {
-		return resp, errors.New("Deleting acquisition " + id + " returned " + strconv.Itoa(response.DeletedCount) + " instead of 1")
-	}
+// SyncAcquisitionInfo changes an acquisition's info to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncAcquisitionInfo(id string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(AcquisitionContainer, id).SyncInfo(desired)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteAcquisition(id string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).Delete()
 }
 
 func (c *Client) UploadToAcquisition(id string, files ...*UploadSource) (chan int64, chan error) {
-	url := "acquisitions/" + id + "/files"
-	return c.UploadSimple(url, nil, files...)
+	return c.Container(AcquisitionContainer, id).Upload(files...)
 }
 
 func (c *Client) ModifyAcquisitionFile(id string, filename string, attributes *FileFields) (*http.Response, *ModifiedAndJobsResponse, error) {
-	url := "acquisitions/" + id + "/files/" + filename
-	return c.modifyFileAttrs(url, attributes)
+	return c.Container(AcquisitionContainer, id).ModifyFile(filename, attributes)
 }
 
 func (c *Client) DeleteAcquisitionFile(id string, filename string) (*http.Response, error) {
-	url := "acquisitions/" + id + "/files/" + filename
-	return c.deleteFile(url)
+	return c.Container(AcquisitionContainer, id).DeleteFile(filename)
 }
 
 func (c *Client) SetAcquisitionFileInfo(id string, filename string, set map[string]interface{}) (*http.Response, error) {
-	url := "acquisitions/" + id + "/files/" + filename + "/info"
-	return c.setInfo(url, set, true)
+	return c.Container(AcquisitionContainer, id).SetFileInfo(filename, set)
 }
 
 func (c *Client) ReplaceAcquisitionFileInfo(id string, filename string, replace map[string]interface{}) (*http.Response, error) {
-	url := "acquisitions/" + id + "/files/" + filename + "/info"
-	return c.replaceInfo(url, replace, true)
+	return c.Container(AcquisitionContainer, id).ReplaceFileInfo(filename, replace)
 }
 
 func (c *Client) DeleteAcquisitionFileInfoFields(id string, filename string, keys []string) (*http.Response, error) {
-	url := "acquisitions/" + id + "/files/" + filename + "/info"
-	return c.deleteInfoFields(url, keys, true)
+	return c.Container(AcquisitionContainer, id).DeleteFileInfoFields(filename, keys)
+}
+
+// SyncAcquisitionFileInfo changes the info of an acquisition's file to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncAcquisitionFileInfo(id string, filename string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(AcquisitionContainer, id).SyncFileInfo(filename, desired)
+}
+
+func (c *Client) AddAcquisitionFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).AddFileTag(filename, tag)
+}
+
+func (c *Client) DeleteAcquisitionFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).DeleteFileTag(filename, tag)
+}
+
+func (c *Client) RenameAcquisitionFileTag(id string, filename string, tag string, newTag string) (*http.Response, error) {
+	return c.Container(AcquisitionContainer, id).RenameFileTag(filename, tag, newTag)
 }
 
 func (c *Client) DownloadFromAcquisition(id string, filename string, destination *DownloadSource) (chan int64, chan error) {
-	url := "acquisitions/" + id + "/files/" + filename
-	return c.DownloadSimple(url, destination)
+	return c.Container(AcquisitionContainer, id).Download(filename, destination)
 }
 
 func (c *Client) GetAcquisitionDownloadUrl(id string, filename string) (string, *http.Response, error) {
-	return c.GetTicketDownloadUrl("acquisitions", id, filename)
+	return c.Container(AcquisitionContainer, id).DownloadUrl(filename)
 }
 
-// No progress reporting
 func (c *Client) UploadFileToAcquisition(id string, path string) error {
-	src := CreateUploadSourceFromFilenames(path)
-	progress, result := c.UploadToAcquisition(id, src...)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(AcquisitionContainer, id).UploadFile(path)
 }
 
-// No progress reporting
 func (c *Client) DownloadFileFromAcquisition(id, name string, path string) error {
-	src := CreateDownloadSourceFromFilename(path)
-	progress, result := c.DownloadFromAcquisition(id, name, src)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(AcquisitionContainer, id).DownloadFile(name, path)
 }
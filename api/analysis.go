@@ -78,7 +78,7 @@ type AnalysisListItem struct {
 }
 
 // This may be cleaner if we had an abstract container struct
-func (c *Client) GetAnalyses(cont_name string, cid string, sub_cont string) ([]*AnalysisListItem, *http.Response, error) {
+func (c *Client) GetAnalyses(cont_name string, cid string, sub_cont string, options ...*ListOptions) ([]*AnalysisListItem, *http.Response, error) {
 	var aerr *Error
 	var analyses []*AnalysisListItem
 	var url string
@@ -90,8 +90,8 @@ func (c *Client) GetAnalyses(cont_name string, cid string, sub_cont string) ([]*
 		url = cont_name + "/" + cid + "/" + sub_cont + "/analyses"
 	}
 
-	resp, err := c.New().Get(url).Receive(&analyses, &aerr)
-	return analyses, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath(url, options)).Receive(&analyses, &aerr)
+	return analyses, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) GetAnalysis(id string) (*Analysis, *http.Response, error) {
@@ -100,7 +100,7 @@ func (c *Client) GetAnalysis(id string) (*Analysis, *http.Response, error) {
 
 	// inflate_job flag is set to avoid a dynamic type on Analysis.Job
 	resp, err := c.New().Get("analyses/"+id+"?inflate_job=true").Receive(&analysis, &aerr)
-	return analysis, resp, Coalesce(err, aerr)
+	return analysis, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddSessionAnalysis(sessionId string, analysis *Analysis, job *Job) (string, *http.Response, error) {
@@ -121,7 +121,7 @@ func (c *Client) AddSessionAnalysis(sessionId string, analysis *Analysis, job *J
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, c.unsupported(AnalysisJobs, CoalesceResponse(resp, err, aerr))
 }
 
 func (c *Client) AddSessionAnalysisNote(sessionId string, analysisId string, text string) (*http.Response, error) {
@@ -140,7 +140,7 @@ func (c *Client) AddSessionAnalysisNote(sessionId string, analysisId string, tex
 		return resp, errors.New("Modifying session analysis on " + sessionId + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
 	}
 
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) DownloadFromAnalysis(sessionId, analysisId, filename string, destination *DownloadSource) (chan int64, chan error) {
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Token is a credential sent in the Authorization header.
+type Token struct {
+	// Scheme precedes the credential in the header, such as "scitran-user" or "Bearer".
+	Scheme string
+
+	Value string
+
+	// Expiry is when the token stops working. A zero value means it does not expire.
+	Expiry time.Time
+}
+
+// tokenExpiryLeeway treats tokens as expired slightly early, so they do not lapse in flight.
+const tokenExpiryLeeway = 10 * time.Second
+
+// Header returns the value of the Authorization header for this token.
+func (t *Token) Header() string {
+	if t.Scheme == "" {
+		return t.Value
+	}
+	return t.Scheme + " " + t.Value
+}
+
+// Valid reports whether the token is present and unexpired.
+func (t *Token) Valid() bool {
+	if t == nil || t.Value == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(tokenExpiryLeeway).Before(t.Expiry)
+}
+
+// TokenSource provides tokens for authenticating requests.
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// TokenInvalidator is implemented by token sources that cache tokens, such as ReuseTokenSource.
+// AuthTransport calls Invalidate with a token the server rejected, so that the next call to Token provides a new one.
+type TokenInvalidator interface {
+	Invalidate(token *Token)
+}
+
+// TokenSourceFunc adapts an ordinary function, such as one that exchanges device credentials or reads a job token, into a TokenSource.
+type TokenSourceFunc func() (*Token, error)
+
+// Token implements the TokenSource interface.
+func (f TokenSourceFunc) Token() (*Token, error) {
+	return f()
+}
+
+// StaticTokenSource always returns the same token.
+func StaticTokenSource(token *Token) TokenSource {
+	return TokenSourceFunc(func() (*Token, error) {
+		return token, nil
+	})
+}
+
+// BearerTokenSource authenticates with an OAuth access token, or any other bearer token.
+func BearerTokenSource(accessToken string) TokenSource {
+	return StaticTokenSource(&Token{Scheme: "Bearer", Value: accessToken})
+}
+
+// ApiKeyTokenSource authenticates with an API key, in the format accepted by ParseApiKey.
+func ApiKeyTokenSource(apiKey string) (TokenSource, error) {
+	_, _, key, err := ParseApiKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return StaticTokenSource(&Token{Scheme: "scitran-user", Value: key}), nil
+}
+
+// ReuseTokenSource caches tokens from another source, only asking it for a new one when the cached token expires or is rejected.
+// Use this to wrap sources that are expensive to call, such as those which refresh an OAuth token.
+func ReuseTokenSource(source TokenSource) TokenSource {
+	return &reuseTokenSource{source: source}
+}
+
+type reuseTokenSource struct {
+	source TokenSource
+
+	mutex sync.Mutex
+	token *Token
+}
+
+// Token implements the TokenSource interface.
+func (s *reuseTokenSource) Token() (*Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// Invalidate implements the TokenInvalidator interface, discarding the cached token unless it was already replaced.
+func (s *reuseTokenSource) Invalidate(token *Token) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+// AuthTransport sets the Authorization header of each request from a TokenSource.
+//
+// When the server answers 401, a source that is a TokenInvalidator, such as ReuseTokenSource, is told the token was rejected,
+// and the request is sent once more, if the source then provides a different token.
+// Requests that already have an Authorization header, even an empty one, are sent unchanged.
+type AuthTransport struct {
+	Source TokenSource
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *AuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if _, ok := req.Header["Authorization"]; ok || t.Source == nil {
+		return transport.RoundTrip(req)
+	}
+
+	token, err := t.Source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, errors.New("Token source returned no token")
+	}
+
+	resp, err := transport.RoundTrip(authorize(req, token, req.Body))
+	if err != nil || resp.StatusCode != 401 {
+		return resp, err
+	}
+
+	// A consumed body cannot be sent again
+	if req.Body != nil && req.GetBody == nil {
+		return resp, err
+	}
+
+	invalidator, ok := t.Source.(TokenInvalidator)
+	if !ok {
+		return resp, err
+	}
+	invalidator.Invalidate(token)
+
+	refreshed, refreshErr := t.Source.Token()
+	if refreshErr != nil || refreshed == nil || refreshed.Header() == token.Header() {
+		return resp, err
+	}
+
+	// Drain the response so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	var body io.ReadCloser
+	if req.Body != nil {
+		body, err = req.GetBody()
+		if err != nil {
+			// A RoundTripper may not return both a response and an error, and the 401 has been read, so only the error is left
+			return nil, err
+		}
+	}
+
+	return transport.RoundTrip(authorize(req, refreshed, body))
+}
+
+// authorize returns a copy of a request with an Authorization header and the given body.
+// RoundTrippers must not modify the original request.
+func authorize(req *http.Request, token *Token, body io.ReadCloser) *http.Request {
+	authed := new(http.Request)
+	*authed = *req
+	authed.Body = body
+
+	authed.Header = make(http.Header, len(req.Header)+1)
+	for key, values := range req.Header {
+		authed.Header[key] = values
+	}
+	authed.Header.Set("Authorization", token.Header())
+
+	return authed
+}
@@ -37,18 +37,18 @@ type BatchProposal struct {
 	Modified *time.Time `json:"modified,omitempty"`
 }
 
-func (c *Client) GetAllBatches() ([]*Batch, *http.Response, error) {
+func (c *Client) GetAllBatches(options ...*ListOptions) ([]*Batch, *http.Response, error) {
 	var aerr *Error
 	var batchs []*Batch
-	resp, err := c.New().Get("batch").Receive(&batchs, &aerr)
-	return batchs, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath("batch", options)).Receive(&batchs, &aerr)
+	return batchs, resp, c.unsupported(Batches, CoalesceResponse(resp, err, aerr))
 }
 
 func (c *Client) GetBatch(id string) (*Batch, *http.Response, error) {
 	var aerr *Error
 	var batch *Batch
 	resp, err := c.New().Get("batch/"+id).Receive(&batch, &aerr)
-	return batch, resp, Coalesce(err, aerr)
+	return batch, resp, c.unsupported(Batches, CoalesceResponse(resp, err, aerr))
 }
 
 func (c *Client) ProposeBatch(gearId string, config map[string]interface{}, tags []string, targets []*ContainerReference) (*BatchProposal, *http.Response, error) {
@@ -68,7 +68,7 @@ func (c *Client) ProposeBatch(gearId string, config map[string]interface{}, tags
 	}
 
 	resp, err := c.New().Post("batch").BodyJSON(batch).Receive(&proposal, &aerr)
-	return proposal, resp, Coalesce(err, aerr)
+	return proposal, resp, c.unsupported(Batches, CoalesceResponse(resp, err, aerr))
 }
 
 func (c *Client) StartBatch(id string) ([]*Job, *http.Response, error) {
@@ -76,7 +76,7 @@ func (c *Client) StartBatch(id string) ([]*Job, *http.Response, error) {
 	var jobs []*Job
 
 	resp, err := c.New().Post("batch/"+id+"/run").Receive(&jobs, &aerr)
-	return jobs, resp, Coalesce(err, aerr)
+	return jobs, resp, c.unsupported(Batches, CoalesceResponse(resp, err, aerr))
 }
 
 func (c *Client) CancelBatch(id string) (int, *http.Response, error) {
@@ -88,5 +88,5 @@ func (c *Client) CancelBatch(id string) (int, *http.Response, error) {
 	}
 
 	resp, err := c.New().Post("batch/"+id+"/cancel").Receive(&response, &aerr)
-	return response.Cancelled, resp, Coalesce(err, aerr)
+	return response.Cancelled, resp, c.unsupported(Batches, CoalesceResponse(resp, err, aerr))
 }
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SkipItem is returned by a BulkOperation to record an item as skipped rather than failed.
+var SkipItem = errors.New("skip this item")
+
+// BulkOperation is applied to each container of a bulk run.
+// It is called concurrently when BulkOptions.Workers is more than one.
+type BulkOperation func(container *Container) error
+
+// BulkAddTag returns an operation that tags each container. Containers that already have the tag are skipped.
+func BulkAddTag(tag string) BulkOperation {
+	return func(container *Container) error {
+		_, err := container.AddTag(tag)
+		if IsConflict(err) {
+			return SkipItem
+		}
+		return err
+	}
+}
+
+// BulkAddNote returns an operation that adds a note to each container.
+func BulkAddNote(text string) BulkOperation {
+	return func(container *Container) error {
+		_, err := container.AddNote(text)
+		return err
+	}
+}
+
+// BulkSetInfo returns an operation that sets info fields on each container.
+func BulkSetInfo(set map[string]interface{}) BulkOperation {
+	return func(container *Container) error {
+		_, err := container.SetInfo(set)
+		return err
+	}
+}
+
+// BulkDeleteInfoFields returns an operation that deletes info fields from each container.
+func BulkDeleteInfoFields(keys []string) BulkOperation {
+	return func(container *Container) error {
+		_, err := container.DeleteInfoFields(keys)
+		return err
+	}
+}
+
+// BulkOptions control how Bulk runs an operation.
+type BulkOptions struct {
+	// Workers is how many items run at once. Zero means one.
+	Workers int
+
+	// Retry, if set, runs items again that fail with a retryable error, such as throttling, waiting between attempts.
+	// Only MaxAttempts and the backoff settings are used.
+	Retry *RetryPolicy
+}
+
+// BulkStatus is the outcome of one item of a bulk run.
+type BulkStatus string
+
+const (
+	BulkSucceeded BulkStatus = "succeeded"
+	BulkSkipped   BulkStatus = "skipped"
+	BulkFailed    BulkStatus = "failed"
+)
+
+// BulkResult is the outcome of one item of a bulk run.
+type BulkResult struct {
+	Target   *ContainerReference `json:"target"`
+	Status   BulkStatus          `json:"status"`
+	Attempts int                 `json:"attempts"`
+
+	// Err is why the item failed. It is kept as Message and StatusCode when the result is serialized, and restored from them.
+	Err        error  `json:"-"`
+	Message    string `json:"error,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+}
+
+// UnmarshalJSON restores a result's error, as an *Error if it came from the server.
+func (r *BulkResult) UnmarshalJSON(raw []byte) error {
+	type plain BulkResult
+	err := json.Unmarshal(raw, (*plain)(r))
+	if err != nil {
+		return err
+	}
+
+	if r.StatusCode != 0 {
+		r.Err = &Error{StatusCode: r.StatusCode, Message: r.Message}
+	} else if r.Message != "" {
+		r.Err = errors.New(r.Message)
+	}
+	return nil
+}
+
+// BulkReport holds the outcome of every item of a bulk run, in the order the items were given.
+// Reports can be saved as JSON, and the failures run again:
+//
+//	report, err = client.Bulk(report.FailedTargets(), operation, options)
+type BulkReport struct {
+	Results []*BulkResult `json:"results"`
+}
+
+// Count returns how many items had a status.
+func (r *BulkReport) Count(status BulkStatus) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// FailedTargets returns the items that failed, to run again.
+func (r *BulkReport) FailedTargets() []*ContainerReference {
+	var targets []*ContainerReference
+	for _, result := range r.Results {
+		if result.Status == BulkFailed {
+			targets = append(targets, result.Target)
+		}
+	}
+	return targets
+}
+
+// Err returns an error summarizing the failures of a run, or nil if nothing failed.
+func (r *BulkReport) Err() error {
+	failed := r.Count(BulkFailed)
+	if failed == 0 {
+		return nil
+	}
+
+	var first error
+	for _, result := range r.Results {
+		if result.Status == BulkFailed {
+			first = result.Err
+			break
+		}
+	}
+	message := strconv.Itoa(failed) + " of " + strconv.Itoa(len(r.Results)) + " items failed"
+	if first != nil {
+		message += "; the first with: " + first.Error()
+	}
+	return errors.New(message)
+}
+
+// Bulk applies an operation to many containers, with bounded concurrency, and reports the outcome of each.
+// Every item is attempted, whether or not others fail; the error returned is the report's Err.
+// If the client's context ends, items that have not run are recorded as failed with its error.
+//
+//	report, err := client.Bulk(targets, api.BulkAddTag("reviewed"), &api.BulkOptions{Workers: 8, Retry: &api.DefaultRetryPolicy})
+func (c *Client) Bulk(targets []*ContainerReference, operation BulkOperation, options *BulkOptions) (*BulkReport, error) {
+	if options == nil {
+		options = &BulkOptions{}
+	}
+	workers := options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	report := &BulkReport{Results: make([]*BulkResult, len(targets))}
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for x := 0; x < workers; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				report.Results[index] = c.bulkItem(targets[index], operation, options.Retry)
+			}
+		}()
+	}
+
+	for index := range targets {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return report, report.Err()
+}
+
+// bulkItem runs an operation on one item, retrying as the policy allows.
+func (c *Client) bulkItem(target *ContainerReference, operation BulkOperation, policy *RetryPolicy) *BulkResult {
+	result := &BulkResult{Target: target}
+	ctx := c.Context()
+
+	for {
+		err := ctx.Err()
+		if err == nil {
+			result.Attempts++
+			err = operation(c.ContainerOf(target))
+		}
+
+		switch {
+		case err == nil:
+			result.Status = BulkSucceeded
+			return result
+		case err == SkipItem:
+			result.Status = BulkSkipped
+			return result
+		}
+
+		if ctx.Err() == nil && policy != nil && IsRetryable(err) && result.Attempts < policy.MaxAttempts {
+			wait, _ := policy.backoff(result.Attempts, nil)
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+				continue
+			case <-ctx.Done():
+				timer.Stop()
+				err = ctx.Err()
+			}
+		}
+
+		result.Status = BulkFailed
+		result.Err = err
+		result.Message = err.Error()
+		if aerr, ok := AsError(err); ok {
+			result.Message = aerr.Message
+			result.StatusCode = aerr.StatusCode
+		}
+		return result
+	}
+}
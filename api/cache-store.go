@@ -0,0 +1,282 @@
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response held by a CacheStore.
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+
+	// Stored is when the response was last fetched or revalidated.
+	Stored time.Time `json:"stored"`
+}
+
+// size estimates the memory used by a response.
+func (r *CachedResponse) size() int64 {
+	size := int64(len(r.Body))
+	for key, values := range r.Header {
+		size += int64(len(key))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+	return size
+}
+
+// CacheStore holds the responses cached by a CacheTransport. Implementations must be safe for concurrent use.
+//
+// Stores are best-effort: a response that cannot be stored is simply fetched again next time.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	Delete(key string)
+
+	// Keys lists every key held, so that responses can be invalidated.
+	Keys() []string
+}
+
+// Defaults for NewMemoryCache, used when a CachePolicy does not set a store.
+const (
+	DefaultCacheEntries = 1000
+	DefaultCacheBytes   = 64 * 1024 * 1024
+)
+
+// MemoryCache is a CacheStore that keeps responses in memory, evicting the least recently used when full.
+type MemoryCache struct {
+	maxEntries int
+	maxBytes   int64
+
+	mutex   sync.Mutex
+	bytes   int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxEntries responses and maxBytes of response data.
+// Zero values are unlimited.
+func NewMemoryCache(maxEntries int, maxBytes int64) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+// Get implements the CacheStore interface.
+func (c *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*memoryCacheEntry).response, true
+}
+
+// Set implements the CacheStore interface.
+func (c *MemoryCache) Set(key string, response *CachedResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.remove(key)
+
+	// A response larger than the whole cache would only evict everything else
+	size := response.size()
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, response: response})
+	c.bytes += size
+
+	for (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.order.Back().Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete implements the CacheStore interface.
+func (c *MemoryCache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.remove(key)
+}
+
+// Keys implements the CacheStore interface.
+func (c *MemoryCache) Keys() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Len returns the number of responses held.
+func (c *MemoryCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+func (c *MemoryCache) remove(key string) {
+	element, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.order.Remove(element)
+	delete(c.entries, key)
+	c.bytes -= element.Value.(*memoryCacheEntry).response.size()
+}
+
+// DiskCache is a CacheStore that keeps responses as files in a directory, so that they outlive the process.
+//
+// It does not limit its size; entries are replaced when revalidated, and removed when invalidated.
+// Several processes may share a directory, but invalidations made by one are not seen by the others.
+type DiskCache struct {
+	dir string
+
+	// Keys by file name, loaded from the directory on first use
+	once  sync.Once
+	mutex sync.Mutex
+	keys  map[string]string
+}
+
+type diskCacheEntry struct {
+	Key      string          `json:"key"`
+	Response *CachedResponse `json:"response"`
+}
+
+const diskCacheExtension = ".json"
+
+// NewDiskCache creates a DiskCache in a directory, creating the directory if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get implements the CacheStore interface.
+func (c *DiskCache) Get(key string) (*CachedResponse, bool) {
+	raw, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry diskCacheEntry
+	if json.Unmarshal(raw, &entry) != nil || entry.Key != key || entry.Response == nil {
+		return nil, false
+	}
+	return entry.Response, true
+}
+
+// Set implements the CacheStore interface.
+func (c *DiskCache) Set(key string, response *CachedResponse) {
+	c.load()
+
+	raw, err := json.Marshal(&diskCacheEntry{Key: key, Response: response})
+	if err != nil {
+		return
+	}
+
+	// Replace the file atomically, so that readers never see part of an entry
+	tmp, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(raw)
+	tmp.Close()
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	c.mutex.Lock()
+	c.keys[filepath.Base(c.path(key))] = key
+	c.mutex.Unlock()
+}
+
+// Delete implements the CacheStore interface.
+func (c *DiskCache) Delete(key string) {
+	c.load()
+
+	os.Remove(c.path(key))
+
+	c.mutex.Lock()
+	delete(c.keys, filepath.Base(c.path(key)))
+	c.mutex.Unlock()
+}
+
+// Keys implements the CacheStore interface.
+func (c *DiskCache) Keys() []string {
+	c.load()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, len(c.keys))
+	for _, key := range c.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// path returns the file that holds a key. Keys are hashed, as URLs do not make safe file names.
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+diskCacheExtension)
+}
+
+// load reads the keys of the entries already in the directory.
+func (c *DiskCache) load() {
+	c.once.Do(func() {
+		keys := map[string]string{}
+
+		infos, _ := ioutil.ReadDir(c.dir)
+		for _, info := range infos {
+			if !strings.HasSuffix(info.Name(), diskCacheExtension) {
+				continue
+			}
+
+			raw, err := ioutil.ReadFile(filepath.Join(c.dir, info.Name()))
+			if err != nil {
+				continue
+			}
+			var entry diskCacheEntry
+			if json.Unmarshal(raw, &entry) == nil {
+				keys[info.Name()] = entry.Key
+			}
+		}
+
+		c.mutex.Lock()
+		c.keys = keys
+		c.mutex.Unlock()
+	})
+}
@@ -0,0 +1,357 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachePolicy controls how CacheTransport caches responses.
+type CachePolicy struct {
+	// TTL is how long a cached response is used without contacting the server.
+	// Once it has passed, a response with an ETag or Last-Modified header is revalidated, and others are fetched again.
+	// Zero revalidates every time.
+	TTL time.Duration
+
+	// Store holds cached responses.
+	// It defaults to a MemoryCache of DefaultCacheEntries responses and DefaultCacheBytes.
+	Store CacheStore
+}
+
+// DefaultCachePolicy is used by the EnableCache option.
+var DefaultCachePolicy = CachePolicy{
+	TTL: time.Minute,
+}
+
+// CacheTransport caches the responses to GET requests, so that repeated reads of the same document are cheap.
+//
+// Only successful JSON responses are cached; file downloads, download tickets and job queue requests never are.
+// Concurrent requests for the same URL are collapsed into one.
+//
+// Any other request is taken to be a write, and invalidates the responses it may have changed:
+// those for the container it modifies, and every listing.
+// Deleting a container, or modifying one whose changes reach other containers, such as a project, invalidates everything.
+// Writes made by other clients are only seen once a response's TTL has passed.
+//
+// Responses are cached per credential, so that a store may be shared between clients without sharing data.
+type CacheTransport struct {
+	Policy CachePolicy
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+
+	mutex sync.Mutex
+	store CacheStore
+
+	// Incremented by each write, so that responses fetched before it are not stored after it
+	generation uint64
+	flights    map[string]*cacheFlight
+}
+
+// cacheFlight is a request in progress, which identical requests wait on.
+type cacheFlight struct {
+	done chan struct{}
+
+	// Set if the request completed
+	response *CachedResponse
+}
+
+// isolatedTypes are the container types whose writes do not change other containers.
+var isolatedTypes = map[string]bool{
+	"sessions":     true,
+	"acquisitions": true,
+	"analyses":     true,
+	"gears":        true,
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	switch req.Method {
+	case "GET":
+	case "HEAD", "OPTIONS":
+		return transport.RoundTrip(req)
+	default:
+		resp, err := transport.RoundTrip(req)
+		t.invalidate(req)
+		return resp, err
+	}
+
+	if !cacheableRequest(req) {
+		return transport.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	store := t.getStore()
+
+	cached, found := store.Get(key)
+	if found && time.Since(cached.Stored) < t.Policy.TTL {
+		return cached.response(req), nil
+	}
+
+	// Wait on an identical request if one is in progress
+	t.mutex.Lock()
+	generation := t.generation
+	flightKey := strconv.FormatUint(generation, 10) + " " + key
+	flight, inProgress := t.flights[flightKey]
+	if !inProgress {
+		flight = &cacheFlight{done: make(chan struct{})}
+		if t.flights == nil {
+			t.flights = map[string]*cacheFlight{}
+		}
+		t.flights[flightKey] = flight
+	}
+	t.mutex.Unlock()
+
+	if inProgress {
+		select {
+		case <-flight.done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		// The request failed, perhaps because its context was cancelled; try again independently
+		if flight.response == nil {
+			return transport.RoundTrip(req)
+		}
+		return flight.response.response(req), nil
+	}
+
+	response, resp, err := t.fetch(transport, req, cached)
+
+	t.mutex.Lock()
+	delete(t.flights, flightKey)
+	if response != nil && generation == t.generation {
+		if cacheableResponse(response) {
+			store.Set(key, response)
+		} else if found {
+			store.Delete(key)
+		}
+	}
+	t.mutex.Unlock()
+
+	flight.response = response
+	close(flight.done)
+
+	return resp, err
+}
+
+// fetch sends a request, revalidating a cached response if there is one.
+// Unless the request fails, it returns the response read into memory, along with a response for the caller.
+func (t *CacheTransport) fetch(transport http.RoundTripper, req *http.Request, cached *CachedResponse) (*CachedResponse, *http.Response, error) {
+	sent := req
+	if cached != nil {
+		etag := cached.Header.Get("ETag")
+		lastModified := cached.Header.Get("Last-Modified")
+
+		if etag != "" || lastModified != "" {
+			sent = new(http.Request)
+			*sent = *req
+			sent.Header = cloneHeader(req.Header)
+
+			if etag != "" {
+				sent.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				sent.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	resp, err := transport.RoundTrip(sent)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	// Unchanged; keep the cached body, with any updated validators
+	if resp.StatusCode == http.StatusNotModified && sent != req {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		refreshed := &CachedResponse{
+			StatusCode: cached.StatusCode,
+			Header:     cloneHeader(cached.Header),
+			Body:       cached.Body,
+			Stored:     time.Now(),
+		}
+		for _, key := range []string{"ETag", "Last-Modified", "Cache-Control", "Date"} {
+			if value := resp.Header.Get(key); value != "" {
+				refreshed.Header.Set(key, value)
+			}
+		}
+		return refreshed, refreshed.response(req), nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	response := &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     cloneHeader(resp.Header),
+		Body:       body,
+		Stored:     time.Now(),
+	}
+	return response, resp, nil
+}
+
+// invalidate removes the cached responses that a write may have changed.
+func (t *CacheTransport) invalidate(req *http.Request) {
+	path := apiPath(req.URL)
+
+	// Searches are sent as POST, but change nothing
+	if len(path) == 2 && path[0] == "dataexplorer" && path[1] == "search" {
+		return
+	}
+
+	everything := req.Method == "DELETE" || len(path) == 0 || !isolatedTypes[path[0]]
+	id := ""
+	if len(path) > 1 {
+		id = path[1]
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.generation++
+	store := t.getStoreLocked()
+
+	for _, key := range store.Keys() {
+		if everything {
+			store.Delete(key)
+			continue
+		}
+
+		// Keys are a credential hash and a URL
+		u, err := url.Parse(key[strings.Index(key, " ")+1:])
+		if err != nil {
+			store.Delete(key)
+			continue
+		}
+		cachedPath := apiPath(u)
+
+		// Anything but a single container document is a listing, which may include the container
+		if len(cachedPath) != 2 || (id != "" && cachedPath[1] == id) {
+			store.Delete(key)
+		}
+	}
+}
+
+func (t *CacheTransport) getStore() CacheStore {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.getStoreLocked()
+}
+
+func (t *CacheTransport) getStoreLocked() CacheStore {
+	if t.store == nil {
+		t.store = t.Policy.Store
+		if t.store == nil {
+			t.store = NewMemoryCache(DefaultCacheEntries, DefaultCacheBytes)
+		}
+	}
+	return t.store
+}
+
+// response returns a new http.Response for a cached response.
+func (r *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cloneHeader(r.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// cacheableRequest reports whether the response to a GET request may be cached.
+func cacheableRequest(req *http.Request) bool {
+	// The caller is making its own conditional or partial request
+	for _, key := range []string{"If-None-Match", "If-Modified-Since", "Range"} {
+		if req.Header.Get(key) != "" {
+			return false
+		}
+	}
+
+	// The caller needs the current state, such as for a conditional change
+	if strings.Contains(req.Header.Get("Cache-Control"), "no-cache") {
+		return false
+	}
+
+	// Tickets are single-use, and file contents can be large
+	if _, isSet := req.URL.Query()["ticket"]; isSet {
+		return false
+	}
+
+	path := apiPath(req.URL)
+	for x, segment := range path {
+		if segment == "files" && x > 0 {
+			return false
+		}
+	}
+
+	// Fetching the next job starts it
+	if len(path) == 2 && path[0] == "jobs" && path[1] == "next" {
+		return false
+	}
+
+	return true
+}
+
+// cacheableResponse reports whether a response may be stored.
+func cacheableResponse(r *CachedResponse) bool {
+	return r.StatusCode == http.StatusOK &&
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") &&
+		!strings.Contains(r.Header.Get("Cache-Control"), "no-store")
+}
+
+// cacheKey identifies a request by its credential and URL.
+// The credential is hashed, so that it is not kept in the store.
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return hex.EncodeToString(sum[:8]) + " " + req.URL.String()
+}
+
+// apiPath returns the segments of a URL's path after the API prefix, such as ["projects", "<id>"].
+func apiPath(u *url.URL) []string {
+	path := u.Path
+	if index := strings.Index(path, "/api/"); index >= 0 {
+		path = path[index+len("/api/"):]
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func cloneHeader(h http.Header) http.Header {
+	result := http.Header{}
+	for key, values := range h {
+		result[key] = append([]string{}, values...)
+	}
+	return result
+}
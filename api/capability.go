@@ -0,0 +1,191 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Capability is a feature of the API that only some servers support.
+//
+// A server supports a capability if its config lists the capability as a feature. Failing that, a capability that adds a route
+// is supported if the server has the route, which is asked for when the server info is fetched: a server without it answers 404,
+// and one with it anything else, such as 405 for a route that only takes POST. A capability without a route of its own,
+// such as AnalysisJobs, is taken to be supported unless the config says otherwise.
+type Capability string
+
+const (
+	// AnalysisJobs is creating an analysis together with the job that runs it. See AddSessionAnalysis.
+	AnalysisJobs Capability = "analysis_jobs"
+
+	// DataExplorerSearch is the search API. See Search and SearchRaw.
+	DataExplorerSearch Capability = "dataexplorer_search"
+
+	// Batches is proposing and running batch jobs. See ProposeBatch.
+	Batches Capability = "batches"
+)
+
+// capabilities describes each capability, with a route it adds that can be asked for with a GET, if any.
+var capabilities = map[Capability]struct {
+	route       string
+	description string
+}{
+	AnalysisJobs:       {"", "creating analyses with jobs"},
+	DataExplorerSearch: {"dataexplorer/search", "search"},
+	Batches:            {"batch?limit=1", "batch jobs"},
+}
+
+// ServerInfo describes a server's version and configuration.
+type ServerInfo struct {
+	Version *Version
+	Config  *Config
+
+	// Missing lists the capabilities whose routes the server answered 404 for.
+	Missing []Capability
+}
+
+// Supports reports whether the server supports a capability.
+// Feature flags in the server's config take precedence over whether it has the capability's route.
+func (i *ServerInfo) Supports(capability Capability) bool {
+	if i.Config != nil {
+		if enabled, isSet := i.Config.Features[string(capability)].(bool); isSet {
+			return enabled
+		}
+	}
+
+	if _, isKnown := capabilities[capability]; !isKnown {
+		return false
+	}
+	for _, missing := range i.Missing {
+		if missing == capability {
+			return false
+		}
+	}
+	return true
+}
+
+// UnsupportedError is returned when a request fails because the server does not support a capability it needs.
+type UnsupportedError struct {
+	Capability Capability
+
+	// Version is that of the server, and Err the failure it responded with.
+	Version *Version
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *UnsupportedError) Error() string {
+	description := capabilities[e.Capability].description
+	if description == "" {
+		description = string(e.Capability)
+	}
+
+	message := "Server does not support " + description
+	if e.Version != nil {
+		message += " (database schema level " + strconv.Itoa(e.Version.Database) + ")"
+	}
+	return message
+}
+
+// Unwrap returns the failure the server responded with.
+func (e *UnsupportedError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnsupported reports whether err is an UnsupportedError.
+func IsUnsupported(err error) bool {
+	_, ok := err.(*UnsupportedError)
+	return ok
+}
+
+// serverInfoCache holds the server info of a client and its copies, once fetched.
+type serverInfoCache struct {
+	mutex sync.Mutex
+	info  *ServerInfo
+}
+
+// GetServerInfo returns the server's version and configuration.
+// They are fetched on first use and cached for the life of the client; see RefreshServerInfo.
+func (c *Client) GetServerInfo() (*ServerInfo, error) {
+	if c.server == nil {
+		return c.fetchServerInfo()
+	}
+
+	c.server.mutex.Lock()
+	defer c.server.mutex.Unlock()
+
+	if c.server.info == nil {
+		info, err := c.fetchServerInfo()
+		if err != nil {
+			return nil, err
+		}
+		c.server.info = info
+	}
+	return c.server.info, nil
+}
+
+// RefreshServerInfo fetches the server's version and configuration again, such as after the server has been upgraded.
+func (c *Client) RefreshServerInfo() (*ServerInfo, error) {
+	if c.server != nil {
+		c.server.mutex.Lock()
+		c.server.info = nil
+		c.server.mutex.Unlock()
+	}
+	return c.GetServerInfo()
+}
+
+// Supports reports whether the server supports a capability. See ServerInfo.Supports.
+func (c *Client) Supports(capability Capability) (bool, error) {
+	info, err := c.GetServerInfo()
+	if err != nil {
+		return false, err
+	}
+	return info.Supports(capability), nil
+}
+
+func (c *Client) fetchServerInfo() (*ServerInfo, error) {
+	version, _, err := c.GetVersion()
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := c.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	info := &ServerInfo{Version: version, Config: config}
+
+	for capability, known := range capabilities {
+		if known.route == "" {
+			continue
+		}
+		// Any answer but 404, even a failure, means the route exists; only a request that could not be made fails the fetch
+		var aerr *Error
+		resp, err := c.New().Get(known.route).Set("Cache-Control", "no-cache").Receive(nil, &aerr)
+		err = CoalesceResponse(resp, err, aerr)
+		if IsNotFound(err) {
+			info.Missing = append(info.Missing, capability)
+		} else if err != nil && StatusCode(err) == 0 {
+			return nil, err
+		}
+	}
+	sort.Slice(info.Missing, func(x, y int) bool { return info.Missing[x] < info.Missing[y] })
+	return info, nil
+}
+
+// unsupported explains a failed request that needed a capability.
+// If the failure looks like a missing endpoint and the server does not support the capability, it returns an UnsupportedError.
+// Otherwise, including when the server info cannot be fetched, the original error is returned.
+func (c *Client) unsupported(capability Capability, err error) error {
+	switch StatusCode(err) {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+	default:
+		return err
+	}
+
+	info, infoErr := c.GetServerInfo()
+	if infoErr != nil || info.Supports(capability) {
+		return err
+	}
+	return &UnsupportedError{Capability: capability, Version: info.Version, Err: err}
+}
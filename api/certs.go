@@ -0,0 +1,29 @@
+package api
+
+import (
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+)
+
+// LoadCertPool reads PEM-encoded CA bundles into a pool for TrustRootCAs.
+// The pool starts with the system's certificate authorities, where the platform provides them.
+func LoadCertPool(paths ...string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, path := range paths {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("No certificates found in CA bundle " + path)
+		}
+	}
+
+	return pool, nil
+}
@@ -39,39 +39,39 @@ type collectionSubmission struct {
 	Contents *collectionOperation `json:"contents,omitempty"`
 }
 
-func (c *Client) GetAllCollections() ([]*Collection, *http.Response, error) {
-	var aerr *Error
-	var collections []*Collection
-	resp, err := c.New().Get("collections").Receive(&collections, &aerr)
-	return collections, resp, Coalesce(err, aerr)
+func (c *Client) GetAllCollections(options ...*ListOptions) ([]*Collection, *http.Response, error) {
+	collections := []*Collection{}
+	it := c.IterateCollections(options...)
+	err := it.collect(func(x interface{}) { collections = append(collections, x.(*Collection)) })
+	return collections, it.lastResponse, err
 }
 
 func (c *Client) GetCollection(id string) (*Collection, *http.Response, error) {
 	var aerr *Error
 	var collection *Collection
 	resp, err := c.New().Get("collections/"+id).Receive(&collection, &aerr)
-	return collection, resp, Coalesce(err, aerr)
+	return collection, resp, CoalesceResponse(resp, err, aerr)
 }
 
-func (c *Client) GetCollectionSessions(id string) ([]*Session, *http.Response, error) {
+func (c *Client) GetCollectionSessions(id string, options ...*ListOptions) ([]*Session, *http.Response, error) {
 	var aerr *Error
 	var sessions []*Session
-	resp, err := c.New().Get("collections/"+id+"/sessions").Receive(&sessions, &aerr)
-	return sessions, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath("collections/"+id+"/sessions", options)).Receive(&sessions, &aerr)
+	return sessions, resp, CoalesceResponse(resp, err, aerr)
 }
 
-func (c *Client) GetCollectionAcquisitions(id string) ([]*Session, *http.Response, error) {
+func (c *Client) GetCollectionAcquisitions(id string, options ...*ListOptions) ([]*Session, *http.Response, error) {
 	var aerr *Error
 	var sessions []*Session
-	resp, err := c.New().Get("collections/"+id+"/acquisitions").Receive(&sessions, &aerr)
-	return sessions, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath("collections/"+id+"/acquisitions", options)).Receive(&sessions, &aerr)
+	return sessions, resp, CoalesceResponse(resp, err, aerr)
 }
 
-func (c *Client) GetCollectionSessionAcquisitions(id string, sid string) ([]*Session, *http.Response, error) {
+func (c *Client) GetCollectionSessionAcquisitions(id string, sid string, options ...*ListOptions) ([]*Session, *http.Response, error) {
 	var aerr *Error
 	var sessions []*Session
-	resp, err := c.New().Get("collections/"+id+"/acquisitions?session="+sid).Receive(&sessions, &aerr)
-	return sessions, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath("collections/"+id+"/acquisitions?session="+sid, options)).Receive(&sessions, &aerr)
+	return sessions, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddCollection(collection *Collection) (string, *http.Response, error) {
@@ -85,7 +85,7 @@ func (c *Client) AddCollection(collection *Collection) (string, *http.Response,
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) addNodesToCollection(id, nodeType string, nodeIds []string) (*http.Response, error) {
@@ -116,7 +116,7 @@ func (c *Client) addNodesToCollection(id, nodeType string, nodeIds []string) (*h
 		return resp, errors.New("Modifying collection " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
 	}
 
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddAcquisitionsToCollection(id string, aqids []string) (*http.Response, error) {
@@ -128,121 +128,108 @@ func (c *Client) AddSessionsToCollection(id string, sessionids []string) (*http.
 }
 
 func (c *Client) AddCollectionNote(id, text string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
-
-	note := &Note{
-		Text: text,
-	}
-
-	resp, err := c.New().Post("collections/"+id+"/notes").BodyJSON(note).Receive(&response, &aerr)
+	return c.Container(CollectionContainer, id).AddNote(text)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying collection " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+func (c *Client) ModifyCollectionNote(id, noteId, text string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).ModifyNote(noteId, text)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteCollectionNote(id, noteId string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).DeleteNote(noteId)
 }
 
 func (c *Client) ModifyCollection(id string, collection *Collection) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
-
-	resp, err := c.New().Put("collections/"+id).BodyJSON(collection).Receive(&response, &aerr)
+	return c.Container(CollectionContainer, id).Modify(collection)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying collection " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+// ModifyCollectionIfUnchanged changes a collection, as ModifyCollection does, unless it was modified since the given time. See Container.ModifyIfUnchanged.
+func (c *Client) ModifyCollectionIfUnchanged(id string, modified *time.Time, collection *Collection) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).ModifyIfUnchanged(modified, collection)
+}
 
-	return resp, Coalesce(err, aerr)
+// UpdateCollection reads a collection, passes it to merge, and makes the changes merge returns, reading and merging again
+// if someone else modified the collection in between. See Container.Update.
+func (c *Client) UpdateCollection(id string, merge func(collection *Collection) (*Collection, error), options *UpdateOptions) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).Update(func() interface{} { return &Collection{} }, func(current interface{}) (interface{}, error) {
+		return merge(current.(*Collection))
+	}, options)
 }
 
 func (c *Client) SetCollectionInfo(id string, set map[string]interface{}) (*http.Response, error) {
-	url := "collections/" + id + "/info"
-	return c.setInfo(url, set, false)
+	return c.Container(CollectionContainer, id).SetInfo(set)
 }
 
 func (c *Client) ReplaceCollectionInfo(id string, replace map[string]interface{}) (*http.Response, error) {
-	url := "collections/" + id + "/info"
-	return c.replaceInfo(url, replace, false)
+	return c.Container(CollectionContainer, id).ReplaceInfo(replace)
 }
 
 func (c *Client) DeleteCollectionInfoFields(id string, keys []string) (*http.Response, error) {
-	url := "collections/" + id + "/info"
-	return c.deleteInfoFields(url, keys, false)
+	return c.Container(CollectionContainer, id).DeleteInfoFields(keys)
 }
 
-func (c *Client) DeleteCollection(id string) (*http.Response, error) {
-	var aerr *Error
-
-	// Unlike other delete endpoints, this doesn't return anything. Which is good, but inconsistent.
-	// https://github.com/scitran/core/issues/680
-	resp, err := c.New().Delete("collections/"+id).Receive(nil, &aerr)
+// SyncCollectionInfo changes a collection's info to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncCollectionInfo(id string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(CollectionContainer, id).SyncInfo(desired)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteCollection(id string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).Delete()
 }
 
 func (c *Client) UploadToCollection(id string, files ...*UploadSource) (chan int64, chan error) {
-	url := "collections/" + id + "/files"
-	return c.UploadSimple(url, nil, files...)
+	return c.Container(CollectionContainer, id).Upload(files...)
 }
 
 func (c *Client) ModifyCollectionFile(id string, filename string, attributes *FileFields) (*http.Response, *ModifiedAndJobsResponse, error) {
-	url := "collections/" + id + "/files/" + filename
-	return c.modifyFileAttrs(url, attributes)
+	return c.Container(CollectionContainer, id).ModifyFile(filename, attributes)
 }
 
 func (c *Client) DeleteCollectionFile(id string, filename string) (*http.Response, error) {
-	url := "collections/" + id + "/files/" + filename
-	return c.deleteFile(url)
+	return c.Container(CollectionContainer, id).DeleteFile(filename)
 }
 
 func (c *Client) SetCollectionFileInfo(id string, filename string, set map[string]interface{}) (*http.Response, error) {
-	url := "collections/" + id + "/files/" + filename + "/info"
-	return c.setInfo(url, set, true)
+	return c.Container(CollectionContainer, id).SetFileInfo(filename, set)
 }
 
 func (c *Client) ReplaceCollectionFileInfo(id string, filename string, replace map[string]interface{}) (*http.Response, error) {
-	url := "collections/" + id + "/files/" + filename + "/info"
-	return c.replaceInfo(url, replace, true)
+	return c.Container(CollectionContainer, id).ReplaceFileInfo(filename, replace)
 }
 
 func (c *Client) DeleteCollectionFileInfoFields(id string, filename string, keys []string) (*http.Response, error) {
-	url := "collections/" + id + "/files/" + filename + "/info"
-	return c.deleteInfoFields(url, keys, true)
+	return c.Container(CollectionContainer, id).DeleteFileInfoFields(filename, keys)
+}
+
+// SyncCollectionFileInfo changes the info of a collection's file to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncCollectionFileInfo(id string, filename string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(CollectionContainer, id).SyncFileInfo(filename, desired)
+}
+
+func (c *Client) AddCollectionFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).AddFileTag(filename, tag)
+}
+
+func (c *Client) DeleteCollectionFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).DeleteFileTag(filename, tag)
+}
+
+func (c *Client) RenameCollectionFileTag(id string, filename string, tag string, newTag string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).RenameFileTag(filename, tag, newTag)
 }
 
 func (c *Client) DownloadFromCollection(id string, filename string, destination *DownloadSource) (chan int64, chan error) {
-	url := "collections/" + id + "/files/" + filename
-	return c.DownloadSimple(url, destination)
+	return c.Container(CollectionContainer, id).Download(filename, destination)
 }
 
 func (c *Client) GetCollectionDownloadUrl(id string, filename string) (string, *http.Response, error) {
-	return c.GetTicketDownloadUrl("collections", id, filename)
+	return c.Container(CollectionContainer, id).DownloadUrl(filename)
 }
 
-// No progress reporting
 func (c *Client) UploadFileToCollection(id string, path string) error {
-	src := CreateUploadSourceFromFilenames(path)
-	progress, result := c.UploadToCollection(id, src...)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(CollectionContainer, id).UploadFile(path)
 }
 
-// No progress reporting
 func (c *Client) DownloadFileFromCollection(id, name string, path string) error {
-	src := CreateDownloadSourceFromFilename(path)
-	progress, result := c.DownloadFromCollection(id, name, src)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(CollectionContainer, id).DownloadFile(name, path)
 }
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dghubble/sling"
+)
+
+// ContainerType is a kind of container, named as in a ContainerReference.
+type ContainerType string
+
+const (
+	GroupContainer       ContainerType = "group"
+	ProjectContainer     ContainerType = "project"
+	SubjectContainer     ContainerType = "subject"
+	SessionContainer     ContainerType = "session"
+	AcquisitionContainer ContainerType = "acquisition"
+	CollectionContainer  ContainerType = "collection"
+	AnalysisContainer    ContainerType = "analysis"
+)
+
+// ContainerTypes lists every kind of container.
+var ContainerTypes = []ContainerType{
+	GroupContainer, ProjectContainer, SubjectContainer, SessionContainer, AcquisitionContainer, CollectionContainer, AnalysisContainer,
+}
+
+// Route returns the name of the container type in API routes, such as "sessions".
+func (t ContainerType) Route() string {
+	if t == AnalysisContainer {
+		return "analyses"
+	}
+	return string(t) + "s"
+}
+
+// article returns the indefinite article for the container type, capitalized to start a sentence: "A" or "An".
+func (t ContainerType) article() string {
+	if t != "" && strings.IndexByte("aeiou", t[0]) >= 0 {
+		return "An"
+	}
+	return "A"
+}
+
+// Container is a handle to one container, for operations that work the same way on every kind of container.
+// Creating a handle does not contact the server; the container need not exist until an operation is used.
+//
+//	_, err := client.Container(api.SessionContainer, id).AddTag("reviewed")
+type Container struct {
+	Type ContainerType
+	Id   string
+
+	client *Client
+}
+
+// Container returns a handle to a container.
+func (c *Client) Container(t ContainerType, id string) *Container {
+	return &Container{Type: t, Id: id, client: c}
+}
+
+// ContainerOf returns a handle to the container a reference points to.
+func (c *Client) ContainerOf(ref *ContainerReference) *Container {
+	return c.Container(ContainerType(ref.Type), ref.Id)
+}
+
+// Path returns the container's API route, such as "sessions/<id>".
+func (c *Container) Path() string {
+	return c.Type.Route() + "/" + c.Id
+}
+
+// Reference returns a reference to the container, as used by jobs and batches.
+func (c *Container) Reference() *ContainerReference {
+	return &ContainerReference{Type: string(c.Type), Id: c.Id}
+}
+
+// Get decodes the container into result, which should be a pointer to the struct for its type, such as *Session.
+func (c *Container) Get(result interface{}) (*http.Response, error) {
+	var aerr *Error
+	resp, err := c.client.New().Get(c.Path()).Receive(result, &aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
+}
+
+// getUncached gets the container, asking any cache in the client's transport to let the request through,
+// for changes that are worked out from the container's current state.
+func (c *Container) getUncached(result interface{}) (*http.Response, error) {
+	var aerr *Error
+	resp, err := c.client.New().Get(c.Path()).Set("Cache-Control", "no-cache").Receive(result, &aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
+}
+
+// Modify changes the container's fields. Changes should be the struct for its type, such as *Session, or a map.
+func (c *Container) Modify(changes interface{}) (*http.Response, error) {
+	return c.modify(c.client.New().Put(c.Path()).BodyJSON(changes))
+}
+
+// Delete removes the container.
+func (c *Container) Delete() (*http.Response, error) {
+	var aerr *Error
+
+	// Unlike other delete endpoints, deleting a collection doesn't return anything.
+	// https://github.com/scitran/core/issues/680
+	if c.Type == CollectionContainer {
+		resp, err := c.client.New().Delete(c.Path()).Receive(nil, &aerr)
+		return resp, CoalesceResponse(resp, err, aerr)
+	}
+
+	var response *DeletedResponse
+	resp, err := c.client.New().Delete(c.Path()).Receive(&response, &aerr)
+
+	// Should not have to check this count
+	// https://github.com/scitran/core/issues/680
+	if err == nil && aerr == nil && response.DeletedCount != 1 {
+		return resp, errors.New("Deleting " + string(c.Type) + " " + c.Id + " returned " + strconv.Itoa(response.DeletedCount) + " instead of 1")
+	}
+
+	return resp, CoalesceResponse(resp, err, aerr)
+}
+
+func (c *Container) AddNote(text string) (*http.Response, error) {
+	note := &Note{
+		Text: text,
+	}
+	return c.modify(c.client.New().Post(c.Path() + "/notes").BodyJSON(note))
+}
+
+func (c *Container) AddTag(tag string) (*http.Response, error) {
+	tagDoc := map[string]interface{}{
+		"value": tag,
+	}
+	return c.modify(c.client.New().Post(c.Path() + "/tags").BodyJSON(tagDoc))
+}
+
+// DeleteTag removes a tag from the container.
+func (c *Container) DeleteTag(tag string) (*http.Response, error) {
+	return c.modify(c.client.New().Delete(c.Path() + "/tags/" + url.PathEscape(tag)))
+}
+
+// RenameTag replaces a tag of the container with another.
+func (c *Container) RenameTag(tag, newTag string) (*http.Response, error) {
+	tagDoc := map[string]interface{}{
+		"value": newTag,
+	}
+	return c.modify(c.client.New().Put(c.Path() + "/tags/" + url.PathEscape(tag)).BodyJSON(tagDoc))
+}
+
+// ModifyNote replaces the text of one of the container's notes, identified by Note.Id.
+func (c *Container) ModifyNote(noteId, text string) (*http.Response, error) {
+	note := &Note{
+		Text: text,
+	}
+	return c.modify(c.client.New().Put(c.Path() + "/notes/" + noteId).BodyJSON(note))
+}
+
+// DeleteNote removes one of the container's notes, identified by Note.Id.
+func (c *Container) DeleteNote(noteId string) (*http.Response, error) {
+	return c.modify(c.client.New().Delete(c.Path() + "/notes/" + noteId))
+}
+
+func (c *Container) SetInfo(set map[string]interface{}) (*http.Response, error) {
+	return c.client.setInfo(c.Path()+"/info", set, false)
+}
+
+func (c *Container) ReplaceInfo(replace map[string]interface{}) (*http.Response, error) {
+	return c.client.replaceInfo(c.Path()+"/info", replace, false)
+}
+
+func (c *Container) DeleteInfoFields(keys []string) (*http.Response, error) {
+	return c.client.deleteInfoFields(c.Path()+"/info", keys, false)
+}
+
+func (c *Container) Upload(files ...*UploadSource) (chan int64, chan error) {
+	return c.client.UploadSimple(c.Path()+"/files", nil, files...)
+}
+
+func (c *Container) ModifyFile(filename string, attributes *FileFields) (*http.Response, *ModifiedAndJobsResponse, error) {
+	return c.client.modifyFileAttrs(c.filePath(filename), attributes)
+}
+
+func (c *Container) DeleteFile(filename string) (*http.Response, error) {
+	return c.client.deleteFile(c.filePath(filename))
+}
+
+func (c *Container) SetFileInfo(filename string, set map[string]interface{}) (*http.Response, error) {
+	return c.client.setInfo(c.filePath(filename)+"/info", set, true)
+}
+
+func (c *Container) ReplaceFileInfo(filename string, replace map[string]interface{}) (*http.Response, error) {
+	return c.client.replaceInfo(c.filePath(filename)+"/info", replace, true)
+}
+
+func (c *Container) DeleteFileInfoFields(filename string, keys []string) (*http.Response, error) {
+	return c.client.deleteInfoFields(c.filePath(filename)+"/info", keys, true)
+}
+
+// AddFileTag tags one of the container's files. Files that already have the tag are left alone.
+//
+// A file's tags are read, then written back as a whole. The write is sent with an If-Match header holding the container's ETag,
+// and if the server refuses it because someone else changed the container in between, the tags are read and edited again,
+// up to three times in all. Without an ETag, or with a server that ignores If-Match, changes made by others in between are lost.
+func (c *Container) AddFileTag(filename, tag string) (*http.Response, error) {
+	return c.editFileTags(filename, func(tags []string) []string {
+		for _, x := range tags {
+			if x == tag {
+				return tags
+			}
+		}
+		return append(tags, tag)
+	})
+}
+
+// DeleteFileTag removes a tag from one of the container's files. See AddFileTag.
+func (c *Container) DeleteFileTag(filename, tag string) (*http.Response, error) {
+	return c.editFileTags(filename, func(tags []string) []string {
+		return replaceTag(tags, tag, "")
+	})
+}
+
+// RenameFileTag replaces a tag of one of the container's files with another. See AddFileTag.
+func (c *Container) RenameFileTag(filename, tag, newTag string) (*http.Response, error) {
+	return c.editFileTags(filename, func(tags []string) []string {
+		return replaceTag(tags, tag, newTag)
+	})
+}
+
+func (c *Container) Download(filename string, destination *DownloadSource) (chan int64, chan error) {
+	return c.client.DownloadSimple(c.filePath(filename), destination)
+}
+
+func (c *Container) DownloadUrl(filename string) (string, *http.Response, error) {
+	return c.client.GetTicketDownloadUrl(c.Type.Route(), c.Id, filename)
+}
+
+// No progress reporting
+func (c *Container) UploadFile(path string) error {
+	src := CreateUploadSourceFromFilenames(path)
+	progress, result := c.Upload(src...)
+
+	// drain and report
+	for range progress {
+	}
+	return <-result
+}
+
+// No progress reporting
+func (c *Container) DownloadFile(filename, path string) error {
+	src := CreateDownloadSourceFromFilename(path)
+	progress, result := c.Download(filename, src)
+
+	// drain and report
+	for range progress {
+	}
+	return <-result
+}
+
+// Analyses lists the container's analyses.
+func (c *Container) Analyses(options ...*ListOptions) ([]*AnalysisListItem, *http.Response, error) {
+	return c.client.GetAnalyses(c.Type.Route(), c.Id, "", options...)
+}
+
+// editFileTags reads a file's tags, bypassing any cache, then writes back the result of edit.
+func (c *Container) editFileTags(filename string, edit func([]string) []string) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		var doc struct {
+			Files []*File `json:"files"`
+		}
+		resp, err := c.getUncached(&doc)
+		if err != nil {
+			return resp, err
+		}
+
+		var file *File
+		for _, x := range doc.Files {
+			if x.Name == filename {
+				file = x
+			}
+		}
+		if file == nil {
+			return resp, &Error{StatusCode: http.StatusNotFound, Message: "No file " + strconv.Quote(filename) + " in " + string(c.Type) + " " + c.Id}
+		}
+
+		// The whole list is written back, so it is sent only if the container is as it was read
+		tags := edit(append([]string{}, file.Tags...))
+		s := c.client.New().Put(c.filePath(filename)).BodyJSON(map[string]interface{}{"tags": tags})
+		etag := resp.Header.Get("ETag")
+		if etag != "" {
+			s = s.Set("If-Match", etag)
+		}
+		resp, err = c.modify(s)
+		if etag == "" || StatusCode(err) != http.StatusPreconditionFailed || attempt >= 3 {
+			return resp, err
+		}
+	}
+}
+
+// replaceTag replaces a tag in a list, keeping its place, or removes it if the replacement is empty or already listed.
+func replaceTag(tags []string, tag, newTag string) []string {
+	result := []string{}
+	for _, x := range tags {
+		if x == newTag && x != tag {
+			continue
+		}
+		if x == tag {
+			x = newTag
+		}
+		if x != "" {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+func (c *Container) filePath(filename string) string {
+	return c.Path() + "/files/" + filename
+}
+
+// modify sends a request that should modify the container once.
+func (c *Container) modify(s *sling.Sling) (*http.Response, error) {
+	var aerr *Error
+	var response *ModifiedResponse
+
+	resp, err := s.Receive(&response, &aerr)
+
+	// Should not have to check this count
+	// https://github.com/scitran/core/issues/680
+	if err == nil && aerr == nil && response.ModifiedCount != 1 {
+		return resp, errors.New("Modifying " + string(c.Type) + " " + c.Id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
+	}
+
+	return resp, CoalesceResponse(resp, err, aerr)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dghubble/sling"
+)
+
+// contextDoer binds requests to a context before handing them to another Doer.
+type contextDoer struct {
+	ctx  context.Context
+	doer sling.Doer
+}
+
+// Do implements the sling.Doer interface.
+// Requests that already carry a context, such as uploads, keep it.
+func (d *contextDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Context() == context.Background() {
+		req = req.WithContext(d.ctx)
+	}
+	return d.doer.Do(req)
+}
+
+// WithContext returns a copy of the client whose requests are bound to ctx.
+//
+// Cancelling ctx, or reaching its deadline, aborts any request in flight - including uploads, downloads, and waits between retries.
+// The original client is unaffected, so one client can be shared while each caller supplies its own context:
+//
+//	sessions, _, err := client.WithContext(ctx).GetAllSessions()
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	// Avoid stacking contexts when called on a client that already has one
+	doer := c.Doer
+	if cd, ok := doer.(*contextDoer); ok {
+		doer = cd.doer
+	}
+	cd := &contextDoer{ctx: ctx, doer: doer}
+
+	result := *c
+	result.Doer = cd
+	result.Sling = c.Sling.New().Doer(cd)
+	result.ctx = ctx
+	return &result
+}
+
+// Context returns the context the client's requests are bound to.
+// It is context.Background unless the client was created with WithContext.
+func (c *Client) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
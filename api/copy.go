@@ -0,0 +1,432 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// CopyOptions control what Copy copies, and how.
+type CopyOptions struct {
+	// Label, if set, labels the copy of the source container, such as when copying into the same parent.
+	Label string
+
+	// Filter holds list options for each type, so that only matching containers below the source are copied.
+	// Containers that are left out are not copied, nor are their children.
+	Filter map[ContainerType]*ListOptions
+
+	// Include, if set, is called for each container below the source, and those it rejects are not copied, nor their children.
+	// It is called concurrently when Workers is more than one.
+	Include func(node *WalkNode) bool
+
+	// Workers is how many containers are copied at once. Zero means one.
+	Workers int
+
+	// Log, if set, records the copy as it goes, and lets a copy that was interrupted resume where it stopped.
+	Log *CopyLog
+}
+
+// Copy recreates a container and everything below it under a new parent: a project in a group, a session in a project,
+// or an acquisition in a session. Labels, tags, notes, info, subjects and files are copied; analyses, permissions,
+// and the authors and times of notes are not. File bytes are streamed from one container to the other, without touching disk.
+//
+// Copy returns the ID of the copy of the source. The first error stops the copy; pass the same CopyOptions.Log to resume it.
+//
+//	log, err := api.OpenCopyLog("copy.log")
+//	...
+//	defer log.Close()
+//	id, err := client.CopyProject(templateId, groupId, &api.CopyOptions{Label: "Teaching", Log: log})
+func (c *Client) Copy(source *Container, parentId string, options *CopyOptions) (string, error) {
+	switch source.Type {
+	case ProjectContainer, SessionContainer, AcquisitionContainer:
+	default:
+		return "", errors.New(source.Type.article() + " " + string(source.Type) + " cannot be copied")
+	}
+	if options == nil {
+		options = &CopyOptions{}
+	}
+	log := options.Log
+	if log == nil {
+		log = NewCopyLog()
+	}
+	cp := &copier{client: c, options: options, log: log}
+
+	err := c.Walk(source, func(node *WalkNode) error {
+		if node.Depth > 0 && options.Include != nil && !options.Include(node) {
+			return SkipContainer
+		}
+
+		target := parentId
+		if node.Parent != nil {
+			target, _ = log.CopyOf(node.Parent.Id)
+		}
+		return cp.copy(node, target)
+	}, &WalkOptions{Filter: options.Filter, Workers: options.Workers, Full: true})
+
+	id, _ := log.CopyOf(source.Id)
+	return id, err
+}
+
+type copier struct {
+	client  *Client
+	options *CopyOptions
+	log     *CopyLog
+}
+
+// copy copies one container, unless the log shows it already was, then its tags and notes, and each of its files, not yet copied.
+// The copy is logged as soon as it is made, so that a copy stopped before its tags and notes are done is finished on resume, not made again.
+func (cp *copier) copy(node *WalkNode, parentId string) error {
+	tags, notes, files := containerContents(node)
+
+	id, resumed := cp.log.CopyOf(node.Id)
+	if !resumed {
+		var err error
+		id, err = cp.create(node, parentId)
+		if err != nil {
+			return err
+		}
+		err = cp.log.add(&copyLogEntry{Source: node.Id, Copy: id})
+		if err != nil {
+			return err
+		}
+	}
+
+	target := cp.client.Container(node.Type, id)
+	if (len(tags) > 0 || len(notes) > 0) && !cp.log.copiedMetadata(node.Id) {
+		err := cp.copyMetadata(target, tags, notes, resumed)
+		if err != nil {
+			return err
+		}
+		err = cp.log.add(&copyLogEntry{Source: node.Id, Metadata: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		if cp.log.copiedFile(node.Id, file.Name) {
+			continue
+		}
+		err := cp.copyFile(node.Container, target, file)
+		if err != nil {
+			return err
+		}
+		err = cp.log.add(&copyLogEntry{Source: node.Id, File: file.Name})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyMetadata adds tags and notes to a copy. When resuming, the copy may already have some of them, which are not added again.
+func (cp *copier) copyMetadata(target *Container, tags []string, notes []*Note, resumed bool) error {
+	tagged := map[string]bool{}
+	noted := map[string]int{}
+	if resumed {
+		var doc struct {
+			Tags  []string `json:"tags"`
+			Notes []*Note  `json:"notes"`
+		}
+		_, err := target.getUncached(&doc)
+		if err != nil {
+			return err
+		}
+		for _, tag := range doc.Tags {
+			tagged[tag] = true
+		}
+		for _, note := range doc.Notes {
+			noted[note.Text]++
+		}
+	}
+
+	for _, tag := range tags {
+		if tagged[tag] {
+			continue
+		}
+		_, err := target.AddTag(tag)
+		if err != nil {
+			return err
+		}
+	}
+	for _, note := range notes {
+		if noted[note.Text] > 0 {
+			noted[note.Text]--
+			continue
+		}
+		_, err := target.AddNote(note.Text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// create adds a container with the fields of a node, under a parent.
+func (cp *copier) create(node *WalkNode, parentId string) (string, error) {
+	var id string
+	var err error
+
+	switch node.Type {
+	case ProjectContainer:
+		project := node.Project()
+		id, _, err = cp.client.AddProject(&Project{
+			Name:        cp.label(node, project.Name),
+			GroupId:     parentId,
+			Description: project.Description,
+			Info:        project.Info,
+		})
+
+	case SessionContainer:
+		session := node.Session()
+		var subject *Subject
+		if session.Subject != nil {
+			// Linked by code to a subject of the new project
+			subject = &Subject{
+				Code:      session.Subject.Code,
+				Firstname: session.Subject.Firstname,
+				Lastname:  session.Subject.Lastname,
+				Sex:       session.Subject.Sex,
+				Age:       session.Subject.Age,
+				Info:      session.Subject.Info,
+			}
+		}
+		id, _, err = cp.client.AddSession(&Session{
+			Name:      cp.label(node, session.Name),
+			ProjectId: parentId,
+			Subject:   subject,
+			Timestamp: session.Timestamp,
+			Timezone:  session.Timezone,
+			Uid:       session.Uid,
+			Info:      session.Info,
+		})
+
+	case AcquisitionContainer:
+		acquisition := node.Acquisition()
+		id, _, err = cp.client.AddAcquisition(&Acquisition{
+			Name:      cp.label(node, acquisition.Name),
+			SessionId: parentId,
+			Timestamp: acquisition.Timestamp,
+			Timezone:  acquisition.Timezone,
+			Uid:       acquisition.Uid,
+			Info:      acquisition.Info,
+		})
+	}
+	return id, err
+}
+
+// label returns the label for the copy of a node: the source's, unless the node is the source and CopyOptions.Label is set.
+func (cp *copier) label(node *WalkNode, label string) string {
+	if node.Depth == 0 && cp.options.Label != "" {
+		return cp.options.Label
+	}
+	return label
+}
+
+// copyFile streams a file from one container to another, then copies its attributes, info and tags.
+func (cp *copier) copyFile(source, target *Container, file *File) error {
+	reader, writer := io.Pipe()
+
+	// Downloads close their writer, even when they fail, which must not look like the end of the file
+	downloadProgress, downloaded := source.Download(file.Name, &DownloadSource{Writer: nopWriteCloser{writer}})
+	go func() {
+		for range downloadProgress {
+		}
+	}()
+	downloadErr := make(chan error, 1)
+	go func() {
+		err := <-downloaded
+		writer.CloseWithError(err)
+		downloadErr <- err
+	}()
+
+	uploadProgress, uploaded := target.Upload(&UploadSource{Name: file.Name, Reader: reader})
+	for range uploadProgress {
+	}
+	err := <-uploaded
+
+	// Unblocks the download, if the upload stopped early
+	reader.Close()
+	if dlErr := <-downloadErr; dlErr != nil {
+		return dlErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if file.Modality != "" || len(file.Measurements) > 0 || file.Type != "" {
+		_, _, err = target.ModifyFile(file.Name, &FileFields{Modality: file.Modality, Measurements: file.Measurements, Type: file.Type})
+		if err != nil {
+			return err
+		}
+	}
+	if len(file.Info) > 0 {
+		_, err = target.SetFileInfo(file.Name, file.Info)
+		if err != nil {
+			return err
+		}
+	}
+	if len(file.Tags) > 0 {
+		_, err = target.editFileTags(file.Name, func([]string) []string { return file.Tags })
+	}
+	return err
+}
+
+// containerContents returns the tags, notes and files of a node that was fetched in full.
+func containerContents(node *WalkNode) ([]string, []*Note, []*File) {
+	switch x := node.value.(type) {
+	case *Project:
+		return x.Tags, x.Notes, x.Files
+	case *Session:
+		return x.Tags, x.Notes, x.Files
+	case *Acquisition:
+		return x.Tags, x.Notes, x.Files
+	}
+	return nil, nil, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// CopyLog records the progress of a copy: the copy made of each container, whether its tags and notes were copied, and each file copied to it.
+// Passing the same log to Copy again resumes the copy, skipping what was already copied.
+//
+// A log from OpenCopyLog is also kept in a file, so that a copy can be resumed by a later run.
+// The file holds one JSON entry per line, and is appended to as the copy goes.
+// CopyLogs are safe for concurrent use.
+type CopyLog struct {
+	mutex    sync.Mutex
+	copies   map[string]string
+	metadata map[string]bool
+	files    map[string]bool
+	file     *os.File
+}
+
+// copyLogEntry records one of: the copy made of a container, that its tags and notes were copied, or that one of its files was.
+type copyLogEntry struct {
+	Source   string `json:"source"`
+	Copy     string `json:"copy,omitempty"`
+	Metadata bool   `json:"metadata,omitempty"`
+	File     string `json:"file,omitempty"`
+}
+
+// NewCopyLog returns a log that is kept in memory.
+func NewCopyLog() *CopyLog {
+	return &CopyLog{
+		copies:   map[string]string{},
+		metadata: map[string]bool{},
+		files:    map[string]bool{},
+	}
+}
+
+// OpenCopyLog opens the log kept in a file, creating it if it does not exist.
+// A last line that was cut off, such as by a crash, is ignored.
+func OpenCopyLog(path string) (*CopyLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	log := NewCopyLog()
+
+	var bad error
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if bad != nil {
+			file.Close()
+			return nil, bad
+		}
+
+		var entry copyLogEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			bad = errors.New("Copy log " + path + " is invalid at line " + strconv.Itoa(line) + ": " + err.Error())
+			continue
+		}
+		log.apply(&entry)
+	}
+	if err = scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// Later entries start on a line of their own
+	if bad != nil {
+		_, err = file.Write([]byte("\n"))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	log.file = file
+	return log, nil
+}
+
+// CopyOf returns the ID of the copy made of a container, and whether one was made.
+func (l *CopyLog) CopyOf(sourceId string) (string, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	id, copied := l.copies[sourceId]
+	return id, copied
+}
+
+// Close closes the log's file, if it has one.
+func (l *CopyLog) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *CopyLog) copiedMetadata(sourceId string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.metadata[sourceId]
+}
+
+func (l *CopyLog) copiedFile(sourceId, name string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.files[sourceId+"/"+name]
+}
+
+// add records an entry, writing it to the log's file first.
+func (l *CopyLog) add(entry *copyLogEntry) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file != nil {
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		_, err = l.file.Write(append(raw, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+	l.apply(entry)
+	return nil
+}
+
+func (l *CopyLog) apply(entry *copyLogEntry) {
+	switch {
+	case entry.File != "":
+		l.files[entry.Source+"/"+entry.File] = true
+	case entry.Metadata:
+		l.metadata[entry.Source] = true
+	default:
+		l.copies[entry.Source] = entry.Copy
+	}
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"errors"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
 )
@@ -26,6 +25,10 @@ func CreateDownloadSourceFromFilename(filename string) *DownloadSource {
 	return &DownloadSource{Path: filename}
 }
 
+// Download will save the file at url to destination, reporting downloaded bytes to progress if set.
+// Download will not block sending to progress.
+//
+// If the client has a context, cancelling it stops the download and reports the context's error.
 func (c *Client) Download(url string, progress chan<- int64, destination *DownloadSource) chan error {
 
 	// Synchronous closure
@@ -53,16 +56,19 @@ func (c *Client) Download(url string, progress chan<- int64, destination *Downlo
 		if err != nil {
 			return closeAndErr(err)
 		}
+		req = markTransfer(req.WithContext(c.Context()))
 
 		resp, err := c.Doer.Do(req)
 		if err != nil {
+			if ctxErr := c.Context().Err(); ctxErr != nil {
+				err = ctxErr
+			}
 			return closeAndErr(err)
 		}
 
 		if resp.StatusCode != 200 {
-			// Needs robust handling for body & raw nils
-			raw, _ := ioutil.ReadAll(resp.Body)
-			return closeAndErr(errors.New(string(raw)))
+			defer resp.Body.Close()
+			return closeAndErr(errorFromResponse(resp))
 		}
 
 		if resp.Body == nil {
@@ -73,10 +79,17 @@ func (c *Client) Download(url string, progress chan<- int64, destination *Downlo
 		progressReader := NewProgressReader(resp.Body, progress)
 		defer progressReader.Close()
 
+		// Closing the body on cancellation stops the copy below
+		progressReader.closeWhenDone(c.Context())
+
 		// Copy response
 		var written int64
 		written, err = io.Copy(destination.Writer, progressReader)
 
+		if ctxErr := c.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+
 		// Verify that the written length was what was expected
 		if resp.ContentLength != written {
 			return errors.New("Response body was truncated")
@@ -110,7 +123,7 @@ func (c *Client) GetTicketDownloadUrl(container string, id string, filename stri
 	downloadUrl := container + "/" + id + "/files/" + filename + "?ticket="
 	resp, err := c.New().Get(downloadUrl).Receive(&ticket, &aerr)
 
-	cerr := Coalesce(err, aerr)
+	cerr := CoalesceResponse(resp, err, aerr)
 	if cerr != nil {
 		return "", resp, cerr
 	}
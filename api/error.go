@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Error is an API error. All failed server responses should be of this form.
+//
+// Error implements the error interface; every Client method that fails due to a server response returns an *Error.
+// Use the IsNotFound, IsForbidden, IsConflict and IsRetryable helpers to inspect one.
+type Error struct {
+	Message    string `json:"message"`
+	StatusCode int    `json:"status_code"`
+	RequestId  string `json:"request_id,omitempty"`
+
+	// Method and Url identify the request that failed.
+	// They are filled in from the response and are not part of the server's error document.
+	Method string `json:"-"`
+	Url    string `json:"-"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" {
+		message = "Unknown server error"
+	}
+	return "(" + strconv.Itoa(e.StatusCode) + ") " + message
+}
+
+// setRequest records the method and URL of the request that produced resp, if any.
+func (e *Error) setRequest(resp *http.Response) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+	e.Method = resp.Request.Method
+	if resp.Request.URL != nil {
+		e.Url = resp.Request.URL.String()
+	}
+}
+
+// errorFromResponse builds an *Error from a failed response, consuming its body.
+// Bodies that are not an API error document, such as those from a proxy, are used as the message.
+func errorFromResponse(resp *http.Response) *Error {
+	aerr := &Error{StatusCode: resp.StatusCode}
+
+	if resp.Body != nil {
+		raw, _ := ioutil.ReadAll(resp.Body)
+
+		if json.Unmarshal(raw, aerr) != nil || aerr.Message == "" {
+			aerr.Message = strings.TrimSpace(string(raw))
+		}
+
+		// Trust the response over the document
+		aerr.StatusCode = resp.StatusCode
+	}
+
+	if aerr.Message == "" {
+		aerr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	aerr.setRequest(resp)
+	return aerr
+}
+
+// AsError returns the *Error held by err, if any, however it is wrapped: by a *url.Error, or any error with an Unwrap method,
+// such as an UnsupportedError, a PathError for a missing label, or one made with fmt.Errorf and %w.
+func AsError(err error) (*Error, bool) {
+	for ; err != nil; err = unwrapError(err) {
+		if aerr, ok := err.(*Error); ok {
+			return aerr, aerr != nil
+		}
+	}
+	return nil, false
+}
+
+// StatusCode returns the HTTP status code of an API error, or zero if err is not an API error.
+func StatusCode(err error) int {
+	aerr, ok := AsError(err)
+	if !ok {
+		return 0
+	}
+	return aerr.StatusCode
+}
+
+// IsNotFound reports whether err is an API error for a missing resource.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsForbidden reports whether err is an API error for an operation the user may not perform.
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsUnauthorized reports whether err is an API error for missing or invalid credentials.
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsConflict reports whether err is an API error for a conflicting modification, such as a duplicate ID,
+// or a *ConflictError from a conditional change to a container that was modified since it was read.
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+// IsRetryable reports whether err is an API error that may succeed if the request is sent again.
+// This covers throttling and gateway failures; local errors are never considered retryable.
+func IsRetryable(err error) bool {
+	return retryableStatus(StatusCode(err))
+}
+
+// retryableStatus reports whether a response status indicates a transient failure.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
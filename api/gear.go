@@ -62,25 +62,25 @@ type GearDoc struct {
 	Modified *time.Time `json:"modified,omitempty"`
 }
 
-func (c *Client) GetAllGears() ([]*GearDoc, *http.Response, error) {
+func (c *Client) GetAllGears(options ...*ListOptions) ([]*GearDoc, *http.Response, error) {
 	var aerr *Error
 	var gears []*GearDoc
-	resp, err := c.New().Get("gears").Receive(&gears, &aerr)
-	return gears, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath("gears", options)).Receive(&gears, &aerr)
+	return gears, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) GetGear(id string) (*GearDoc, *http.Response, error) {
 	var aerr *Error
 	var gear *GearDoc
 	resp, err := c.New().Get("gears/"+id).Receive(&gear, &aerr)
-	return gear, resp, Coalesce(err, aerr)
+	return gear, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) GetGearInvocation(id string) (map[string]interface{}, *http.Response, error) {
 	var aerr *Error
 	var response map[string]interface{}
 	resp, err := c.New().Get("gears/"+id+"/invocation").Receive(&response, &aerr)
-	return response, resp, Coalesce(err, aerr)
+	return response, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddGear(gear *GearDoc) (string, *http.Response, error) {
@@ -113,12 +113,12 @@ func (c *Client) AddGear(gear *GearDoc) (string, *http.Response, error) {
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) DeleteGear(id string) (*http.Response, error) {
 	var aerr *Error
 
 	resp, err := c.New().Delete("gears/"+id).Receive(nil, &aerr)
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
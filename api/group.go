@@ -1,9 +1,7 @@
 package api
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
 	"time"
 )
 
@@ -21,18 +19,25 @@ type Group struct {
 	Permissions []*Permission `json:"permissions,omitempty"`
 }
 
-func (c *Client) GetAllGroups() ([]*Group, *http.Response, error) {
-	var aerr *Error
-	var groups []*Group
-	resp, err := c.New().Get("groups").Receive(&groups, &aerr)
-	return groups, resp, Coalesce(err, aerr)
+func (c *Client) GetAllGroups(options ...*ListOptions) ([]*Group, *http.Response, error) {
+	groups := []*Group{}
+	it := c.IterateGroups(options...)
+	err := it.collect(func(x interface{}) { groups = append(groups, x.(*Group)) })
+	return groups, it.lastResponse, err
 }
 
 func (c *Client) GetGroup(id string) (*Group, *http.Response, error) {
 	var aerr *Error
 	var group *Group
 	resp, err := c.New().Get("groups/"+id).Receive(&group, &aerr)
-	return group, resp, Coalesce(err, aerr)
+	return group, resp, CoalesceResponse(resp, err, aerr)
+}
+
+func (c *Client) GetGroupProjects(id string, options ...*ListOptions) ([]*Project, *http.Response, error) {
+	var aerr *Error
+	var projects []*Project
+	resp, err := c.New().Get(listPath("groups/"+id+"/projects", options)).Receive(&projects, &aerr)
+	return projects, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddGroup(group *Group) (string, *http.Response, error) {
@@ -46,55 +51,25 @@ func (c *Client) AddGroup(group *Group) (string, *http.Response, error) {
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddGroupTag(id, tag string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
-
-	var tagDoc interface{}
-	tagDoc = map[string]interface{}{
-		"value": tag,
-	}
-
-	resp, err := c.New().Post("groups/"+id+"/tags").BodyJSON(tagDoc).Receive(&response, &aerr)
+	return c.Container(GroupContainer, id).AddTag(tag)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying group " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+func (c *Client) DeleteGroupTag(id, tag string) (*http.Response, error) {
+	return c.Container(GroupContainer, id).DeleteTag(tag)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) RenameGroupTag(id, tag, newTag string) (*http.Response, error) {
+	return c.Container(GroupContainer, id).RenameTag(tag, newTag)
 }
 
 func (c *Client) ModifyGroup(id string, group *Group) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
-
-	resp, err := c.New().Put("groups/"+id).BodyJSON(group).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying group " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
-
-	return resp, Coalesce(err, aerr)
+	return c.Container(GroupContainer, id).Modify(group)
 }
 
 func (c *Client) DeleteGroup(id string) (*http.Response, error) {
-	var aerr *Error
-	var response *DeletedResponse
-
-	resp, err := c.New().Delete("groups/"+id).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.DeletedCount != 1 {
-		return resp, errors.New("Deleting group " + id + " returned " + strconv.Itoa(response.DeletedCount) + " instead of 1")
-	}
-
-	return resp, Coalesce(err, aerr)
+	return c.Container(GroupContainer, id).Delete()
 }
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hook observes each request a client sends, for logging, metrics, or tracing.
+// Hooks are called concurrently from every goroutine using the client, and must be safe for concurrent use.
+//
+// Each attempt of a retried request is observed separately.
+type Hook interface {
+	// Request is called before a request is sent, and returns the request to send.
+	// It may return a copy with added headers or context values, such as a trace span.
+	// As with RoundTrippers, the original request must not be modified.
+	Request(req *http.Request) *http.Request
+
+	// Response is called when response headers arrive, or the request fails.
+	// It may replace resp.Body, as long as the replacement reads the same content.
+	Response(req *http.Request, resp *http.Response, err error)
+
+	// Done is called once the response body has been read or closed, or the request fails.
+	Done(stats *RequestStats)
+}
+
+// RequestStats summarizes one request.
+type RequestStats struct {
+	// Request is the request as sent, after every hook has seen it.
+	Request *http.Request
+
+	// StatusCode is zero if the request failed.
+	StatusCode int
+
+	// Err is the error that failed the request, if any.
+	Err error
+
+	Start time.Time
+
+	// Latency is the time until response headers arrived; Duration, the time until the body was done.
+	Latency  time.Duration
+	Duration time.Duration
+
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// StatsHook adapts an ordinary function into a Hook that only needs RequestStats, such as a metrics collector.
+type StatsHook func(stats *RequestStats)
+
+func (h StatsHook) Request(req *http.Request) *http.Request                    { return req }
+func (h StatsHook) Response(req *http.Request, resp *http.Response, err error) {}
+func (h StatsHook) Done(stats *RequestStats)                                   { h(stats) }
+
+// HookTransport calls its hooks for each request. Hooks are called in order.
+type HookTransport struct {
+	Hooks []Hook
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *HookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	stats := &RequestStats{Start: time.Now()}
+
+	// Bodies are counted separately, as the transport may still be reading the request body when hooks are done
+	var sent, received int64
+	done := func() {
+		stats.Duration = time.Since(stats.Start)
+		stats.BytesSent = atomic.LoadInt64(&sent)
+		stats.BytesReceived = atomic.LoadInt64(&received)
+
+		for _, hook := range t.Hooks {
+			hook.Done(stats)
+		}
+	}
+
+	for _, hook := range t.Hooks {
+		if hooked := hook.Request(req); hooked != nil {
+			req = hooked
+		}
+	}
+
+	// Count the bytes of the request body as the transport reads them
+	if req.Body != nil {
+		counted := new(http.Request)
+		*counted = *req
+		counted.Body = &countingBody{ReadCloser: req.Body, count: &sent}
+		req = counted
+	}
+	stats.Request = req
+
+	resp, err := transport.RoundTrip(req)
+	stats.Latency = time.Since(stats.Start)
+
+	for _, hook := range t.Hooks {
+		hook.Response(req, resp, err)
+	}
+
+	if err != nil || resp.Body == nil {
+		stats.Err = err
+		if resp != nil {
+			stats.StatusCode = resp.StatusCode
+		}
+		done()
+		return resp, err
+	}
+
+	stats.StatusCode = resp.StatusCode
+	resp.Body = &hookBody{
+		countingBody: countingBody{ReadCloser: resp.Body, count: &received},
+		done:         done,
+	}
+	return resp, nil
+}
+
+// countingBody counts the bytes read through it.
+type countingBody struct {
+	io.ReadCloser
+	count *int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(b.count, int64(n))
+	return n, err
+}
+
+// hookBody finishes its request once it has been read or closed.
+type hookBody struct {
+	countingBody
+	once sync.Once
+	done func()
+}
+
+func (b *hookBody) Read(p []byte) (int, error) {
+	n, err := b.countingBody.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *hookBody) Close() error {
+	err := b.countingBody.Close()
+	b.once.Do(b.done)
+	return err
+}
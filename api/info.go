@@ -0,0 +1,223 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Info fields are addressed by dotted path, such as "qa.motion.fd_mean", to reach into nested objects.
+// The keys given to SetInfo and DeleteInfoFields, and to their per-type and file versions, are dotted paths as well:
+// the server sets or removes only the field the path names, leaving the rest of the info as it is.
+// A key that itself contains a dot cannot be addressed by path.
+
+// GetInfoPath returns the value at a dotted path in info, and whether there is one.
+func GetInfoPath(info map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		info, _ = info[key].(map[string]interface{})
+		if info == nil {
+			return nil, false
+		}
+	}
+	value, exists := info[keys[len(keys)-1]]
+	return value, exists
+}
+
+// SetInfoPath sets the value at a dotted path in info, creating objects along the way, and replacing any value in the way that is not one.
+func SetInfoPath(info map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, _ := info[key].(map[string]interface{})
+		if next == nil {
+			next = map[string]interface{}{}
+			info[key] = next
+		}
+		info = next
+	}
+	info[keys[len(keys)-1]] = value
+}
+
+// DeleteInfoPath removes the value at a dotted path in info, and reports whether there was one.
+func DeleteInfoPath(info map[string]interface{}, path string) bool {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		info, _ = info[key].(map[string]interface{})
+		if info == nil {
+			return false
+		}
+	}
+	_, exists := info[keys[len(keys)-1]]
+	delete(info, keys[len(keys)-1])
+	return exists
+}
+
+// InfoUpdate is a change to info, as sent to the server: fields to set and fields to delete, by dotted path, or else a replacement of the whole.
+type InfoUpdate struct {
+	Set     map[string]interface{} `json:"set,omitempty"`
+	Delete  []string               `json:"delete,omitempty"`
+	Replace map[string]interface{} `json:"replace,omitempty"`
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u *InfoUpdate) IsEmpty() bool {
+	return len(u.Set) == 0 && len(u.Delete) == 0 && u.Replace == nil
+}
+
+// body returns the update as a request body. Unlike the JSON encoding of the struct, it keeps a replacement that is empty.
+func (u *InfoUpdate) body() map[string]interface{} {
+	if u.Replace != nil {
+		return map[string]interface{}{"replace": u.Replace}
+	}
+	body := map[string]interface{}{}
+	if len(u.Set) > 0 {
+		body["set"] = u.Set
+	}
+	if len(u.Delete) > 0 {
+		body["delete"] = u.Delete
+	}
+	return body
+}
+
+// DiffInfo returns the smallest update that turns current info into desired: the fields that differ are set,
+// and those that are gone are deleted, each by the deepest path that reaches it. Desired is compared as it would be sent,
+// so that, for instance, the integer 2 matches the 2.0 read from the server.
+//
+// Where the keys of an object contain dots, and so cannot be addressed by path, the object is set as a whole,
+// or if it is the info itself, replaced.
+func DiffInfo(current, desired map[string]interface{}) (*InfoUpdate, error) {
+	var normalized map[string]interface{}
+	raw, err := json.Marshal(desired)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(raw, &normalized)
+	if err != nil {
+		return nil, err
+	}
+	if normalized == nil {
+		normalized = map[string]interface{}{}
+	}
+
+	update := &InfoUpdate{Set: map[string]interface{}{}}
+	if len(current) == 0 && len(normalized) == 0 || reflect.DeepEqual(current, normalized) {
+		return update, nil
+	}
+	if !diffInfo(update, "", current, normalized) {
+		return &InfoUpdate{Replace: normalized}, nil
+	}
+	sort.Strings(update.Delete)
+	return update, nil
+}
+
+// diffInfo adds to an update the changes from one object to another at a path prefix.
+// It returns false, having added nothing, if either object has keys that cannot be addressed by path.
+func diffInfo(update *InfoUpdate, prefix string, current, desired map[string]interface{}) bool {
+	if !addressable(current) || !addressable(desired) {
+		return false
+	}
+
+	for key, want := range desired {
+		path := prefix + key
+		have, exists := current[key]
+		if exists && reflect.DeepEqual(have, want) {
+			continue
+		}
+
+		haveObject, haveIsObject := have.(map[string]interface{})
+		wantObject, wantIsObject := want.(map[string]interface{})
+		if haveIsObject && wantIsObject && diffInfo(update, path+".", haveObject, wantObject) {
+			continue
+		}
+		update.Set[path] = want
+	}
+	for key := range current {
+		if _, kept := desired[key]; !kept {
+			update.Delete = append(update.Delete, prefix+key)
+		}
+	}
+	return true
+}
+
+// addressable reports whether each key of an object can be part of a dotted path.
+func addressable(object map[string]interface{}) bool {
+	for key := range object {
+		if key == "" || strings.Contains(key, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// GetInfoPath returns the value at a dotted path in the container's info, and whether there is one.
+func (c *Container) GetInfoPath(path string) (interface{}, bool, *http.Response, error) {
+	var doc struct {
+		Info map[string]interface{} `json:"info"`
+	}
+	resp, err := c.Get(&doc)
+	if err != nil {
+		return nil, false, resp, err
+	}
+	value, exists := GetInfoPath(doc.Info, path)
+	return value, exists, resp, nil
+}
+
+// SetInfoPath sets one field of the container's info, by dotted path.
+func (c *Container) SetInfoPath(path string, value interface{}) (*http.Response, error) {
+	return c.SetInfo(map[string]interface{}{path: value})
+}
+
+// DeleteInfoPath removes one field of the container's info, by dotted path.
+func (c *Container) DeleteInfoPath(path string) (*http.Response, error) {
+	return c.DeleteInfoFields([]string{path})
+}
+
+// SyncInfo changes the container's info to desired, sending only how it differs from the current info, as found by DiffInfo.
+// It returns the update that was sent; if there was no difference, nothing is sent.
+//
+// The info is read, bypassing any cache, then the difference written, so fields changed by others in between may be overwritten,
+// but others are not.
+func (c *Container) SyncInfo(desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	var doc struct {
+		Info map[string]interface{} `json:"info"`
+	}
+	resp, err := c.getUncached(&doc)
+	if err != nil {
+		return nil, resp, err
+	}
+	return c.client.syncInfo(resp, c.Path()+"/info", doc.Info, desired, false)
+}
+
+// SyncFileInfo changes the info of one of the container's files to desired. See SyncInfo.
+func (c *Container) SyncFileInfo(filename string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	var doc struct {
+		Files []*File `json:"files"`
+	}
+	resp, err := c.getUncached(&doc)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	for _, file := range doc.Files {
+		if file.Name == filename {
+			return c.client.syncInfo(resp, c.filePath(filename)+"/info", file.Info, desired, true)
+		}
+	}
+	return nil, resp, &Error{StatusCode: http.StatusNotFound, Message: "No file " + strconv.Quote(filename) + " in " + string(c.Type) + " " + c.Id}
+}
+
+// syncInfo sends the difference between current and desired info, if any, or else returns the response of reading the current info.
+func (c *Client) syncInfo(readResp *http.Response, url string, current, desired map[string]interface{}, expectResponse bool) (*InfoUpdate, *http.Response, error) {
+	update, err := DiffInfo(current, desired)
+	if err != nil {
+		return nil, readResp, err
+	}
+	if update.IsEmpty() {
+		return update, readResp, nil
+	}
+	resp, err := c.postWithOptionalModifiedResponse(url, update.body(), expectResponse)
+	return update, resp, err
+}
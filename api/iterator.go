@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// DefaultPageSize is how many items an iterator requests at a time, unless its PageSize is changed.
+var DefaultPageSize = 1000
+
+// ListIterator pages through a listing, such as every session on the site.
+// Only one page is held in memory. Each page is read in full, and its response closed, before its items are returned,
+// so that the client can be used for other requests while iterating, even with LimitRequests.
+//
+// Pages are requested with the limit and after_id parameters.
+// Servers without pagination return the whole listing at once, which is then held in memory, up to the Limit option.
+// A server that applies the limit but ignores after_id sends the first page again; the rest of the listing is then requested at once.
+// Paging follows ID order, so a listing with a Sort option is requested all at once.
+//
+// Iterators are not safe for concurrent use. Use one like a bufio.Scanner:
+//
+//	it := client.IterateSessions()
+//	defer it.Close()
+//	for it.Next() {
+//		session := it.Session()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ListIterator struct {
+	// PageSize is how many items to request at a time. It may be changed before the first call to Next.
+	PageSize int
+
+	client  *Client
+	path    string
+	options *ListOptions
+	newItem func() interface{}
+	idOf    func(interface{}) string
+
+	lastResponse *http.Response
+	page         []interface{}
+	inPage       int
+	lastPage     bool
+	count        int
+	afterId      string
+
+	// unpaged is set once the server is found to ignore after_id, so that the listing is requested at once
+	unpaged bool
+
+	item interface{}
+	done bool
+	err  error
+}
+
+func newListIterator(client *Client, path string, options []*ListOptions, newItem func() interface{}, idOf func(interface{}) string) *ListIterator {
+	return &ListIterator{
+		PageSize: DefaultPageSize,
+		client:   client,
+		path:     path,
+		options:  mergeListOptions(options),
+		newItem:  newItem,
+		idOf:     idOf,
+	}
+}
+
+// Next advances to the next item, returning false when there are no more or an error occurs.
+func (it *ListIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	for {
+		if it.reachedLimit() {
+			it.Close()
+			return false
+		}
+
+		if it.inPage < len(it.page) {
+			it.item = it.page[it.inPage]
+			it.page[it.inPage] = nil
+			it.inPage++
+			it.count++
+			it.afterId = it.idOf(it.item)
+			return true
+		}
+
+		if it.lastPage {
+			it.Close()
+			return false
+		}
+		err := it.requestPage()
+		if err != nil {
+			return it.fail(err)
+		}
+	}
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iterator, releasing its page. Stopping early is safe, and Close may be called more than once.
+func (it *ListIterator) Close() error {
+	it.done = true
+	it.page = nil
+	it.inPage = 0
+	return nil
+}
+
+// reachedLimit reports whether the iterator has returned as many items as its options allow.
+func (it *ListIterator) reachedLimit() bool {
+	return it.options != nil && it.options.Limit > 0 && it.count >= it.options.Limit
+}
+
+// requestPage requests the next page and reads all of its items, closing the response.
+func (it *ListIterator) requestPage() error {
+	query := it.options.values()
+
+	pageLimit := 0
+	if it.PageSize > 0 && !it.unpaged && (it.options == nil || len(it.options.Sort) == 0) {
+		pageLimit = it.PageSize
+		if it.options != nil && it.options.Limit > 0 && it.options.Limit-it.count < pageLimit {
+			pageLimit = it.options.Limit - it.count
+		}
+		query.Set("limit", strconv.Itoa(pageLimit))
+		if it.afterId != "" {
+			query.Set("after_id", it.afterId)
+		}
+	}
+
+	path := it.path
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := it.client.New().Get(path).Request()
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(it.client.Context())
+
+	resp, err := it.client.Doer.Do(req)
+	it.lastResponse = resp
+	if err != nil {
+		if ctxErr := it.client.Context().Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errorFromResponse(resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return errors.New("Listing " + it.path + " did not return an array")
+	}
+
+	// Requested at once after paging failed, the listing starts with the items already returned
+	skip := 0
+	if pageLimit <= 0 {
+		skip = it.count
+	}
+
+	pageStart := it.afterId
+	it.page = it.page[:0]
+	it.inPage = 0
+	it.lastPage = false
+	for decoder.More() {
+		// Items past the limit are not needed, such as from a server without pagination
+		if it.options != nil && it.options.Limit > 0 && it.count+len(it.page) >= it.options.Limit {
+			it.lastPage = true
+			return nil
+		}
+
+		item := it.newItem()
+		err = decoder.Decode(item)
+		if err != nil {
+			return err
+		}
+
+		if skip > 0 {
+			skip--
+			continue
+		}
+
+		// A server that ignores after_id sends the first page again, so the next request is for the whole listing
+		if pageLimit > 0 && pageStart != "" && it.idOf(item) <= pageStart {
+			it.unpaged = true
+			it.page = it.page[:0]
+			return nil
+		}
+		it.page = append(it.page, item)
+	}
+	_, err = decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	// A short page is the last; so is one from a server that ignored the limit, or one whose items have no IDs to page after
+	it.lastPage = pageLimit <= 0 || len(it.page) != pageLimit || it.idOf(it.page[len(it.page)-1]) == ""
+	return nil
+}
+
+func (it *ListIterator) fail(err error) bool {
+	if it.err == nil {
+		it.err = err
+	}
+	it.Close()
+	return false
+}
+
+// collect reads every remaining item, for the slice-returning methods.
+func (it *ListIterator) collect(add func(interface{})) error {
+	defer it.Close()
+	for it.Next() {
+		add(it.item)
+	}
+	return it.Err()
+}
+
+// UserIterator iterates over users. See ListIterator.
+type UserIterator struct{ *ListIterator }
+
+// User returns the current user.
+func (it *UserIterator) User() *User { return it.item.(*User) }
+
+// IterateUsers returns an iterator over every user, or those matching the options.
+func (c *Client) IterateUsers(options ...*ListOptions) *UserIterator {
+	return &UserIterator{newListIterator(c, "users", options,
+		func() interface{} { return &User{} },
+		func(x interface{}) string { return x.(*User).Id })}
+}
+
+// GroupIterator iterates over groups. See ListIterator.
+type GroupIterator struct{ *ListIterator }
+
+// Group returns the current group.
+func (it *GroupIterator) Group() *Group { return it.item.(*Group) }
+
+// IterateGroups returns an iterator over every group, or those matching the options.
+func (c *Client) IterateGroups(options ...*ListOptions) *GroupIterator {
+	return &GroupIterator{newListIterator(c, "groups", options,
+		func() interface{} { return &Group{} },
+		func(x interface{}) string { return x.(*Group).Id })}
+}
+
+// ProjectIterator iterates over projects. See ListIterator.
+type ProjectIterator struct{ *ListIterator }
+
+// Project returns the current project.
+func (it *ProjectIterator) Project() *Project { return it.item.(*Project) }
+
+// IterateProjects returns an iterator over every project, or those matching the options.
+func (c *Client) IterateProjects(options ...*ListOptions) *ProjectIterator {
+	return &ProjectIterator{newListIterator(c, "projects", options,
+		func() interface{} { return &Project{} },
+		func(x interface{}) string { return x.(*Project).Id })}
+}
+
+// SubjectIterator iterates over subjects. See ListIterator.
+type SubjectIterator struct{ *ListIterator }
+
+// Subject returns the current subject.
+func (it *SubjectIterator) Subject() *Subject { return it.item.(*Subject) }
+
+// IterateSubjects returns an iterator over every subject, or those matching the options.
+func (c *Client) IterateSubjects(options ...*ListOptions) *SubjectIterator {
+	return &SubjectIterator{newListIterator(c, "subjects", options,
+		func() interface{} { return &Subject{} },
+		func(x interface{}) string { return x.(*Subject).Id })}
+}
+
+// SessionIterator iterates over sessions. See ListIterator.
+type SessionIterator struct{ *ListIterator }
+
+// Session returns the current session.
+func (it *SessionIterator) Session() *Session { return it.item.(*Session) }
+
+// IterateSessions returns an iterator over every session, or those matching the options.
+func (c *Client) IterateSessions(options ...*ListOptions) *SessionIterator {
+	return &SessionIterator{newListIterator(c, "sessions", options,
+		func() interface{} { return &Session{} },
+		func(x interface{}) string { return x.(*Session).Id })}
+}
+
+// AcquisitionIterator iterates over acquisitions. See ListIterator.
+type AcquisitionIterator struct{ *ListIterator }
+
+// Acquisition returns the current acquisition.
+func (it *AcquisitionIterator) Acquisition() *Acquisition { return it.item.(*Acquisition) }
+
+// IterateAcquisitions returns an iterator over every acquisition, or those matching the options.
+func (c *Client) IterateAcquisitions(options ...*ListOptions) *AcquisitionIterator {
+	return &AcquisitionIterator{newListIterator(c, "acquisitions", options,
+		func() interface{} { return &Acquisition{} },
+		func(x interface{}) string { return x.(*Acquisition).Id })}
+}
+
+// CollectionIterator iterates over collections. See ListIterator.
+type CollectionIterator struct{ *ListIterator }
+
+// Collection returns the current collection.
+func (it *CollectionIterator) Collection() *Collection { return it.item.(*Collection) }
+
+// IterateCollections returns an iterator over every collection, or those matching the options.
+func (c *Client) IterateCollections(options ...*ListOptions) *CollectionIterator {
+	return &CollectionIterator{newListIterator(c, "collections", options,
+		func() interface{} { return &Collection{} },
+		func(x interface{}) string { return x.(*Collection).Id })}
+}
@@ -120,7 +120,7 @@ func (c *Client) GetJob(id string) (*Job, *http.Response, error) {
 	var job *Job
 
 	resp, err := c.New().Get("jobs/"+id).Receive(&job, &aerr)
-	return job, resp, Coalesce(err, aerr)
+	return job, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) GetJobLogs(id string) (*JobLog, *http.Response, error) {
@@ -128,7 +128,7 @@ func (c *Client) GetJobLogs(id string) (*JobLog, *http.Response, error) {
 	var logs *JobLog
 
 	resp, err := c.New().Get("jobs/"+id+"/logs").Receive(&logs, &aerr)
-	return logs, resp, Coalesce(err, aerr)
+	return logs, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddJob(job *Job) (string, *http.Response, error) {
@@ -142,21 +142,21 @@ func (c *Client) AddJob(job *Job) (string, *http.Response, error) {
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddJobLogs(id string, statements []*JobLogStatement) (*http.Response, error) {
 	var aerr *Error
 
 	resp, err := c.New().Post("jobs/"+id+"/logs").BodyJSON(statements).Receive(nil, &aerr)
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) ModifyJob(id string, job *Job) (*http.Response, error) {
 	var aerr *Error
 
 	resp, err := c.New().Put("jobs/"+id).BodyJSON(job).Receive(nil, &aerr)
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) StartNextPendingJob(tags ...string) (JobRetrieval, *Job, *http.Response, error) {
@@ -170,7 +170,7 @@ func (c *Client) StartNextPendingJob(tags ...string) (JobRetrieval, *Job, *http.
 	}
 
 	resp, err := c.New().Get("jobs/next").QueryStruct(params).Receive(&job, &aerr)
-	rerr := Coalesce(err, aerr)
+	rerr := CoalesceResponse(resp, err, aerr)
 
 	if rerr == nil && job != nil {
 		return JobAquired, job, resp, nil
@@ -188,7 +188,7 @@ func (c *Client) HeartbeatJob(id string) (*http.Response, error) {
 	empty := map[string]string{}
 
 	resp, err := c.New().Put("jobs/"+id).BodyJSON(empty).Receive(nil, &aerr)
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) ChangeJobState(id string, state JobState) (*http.Response, error) {
@@ -198,5 +198,5 @@ func (c *Client) ChangeJobState(id string, state JobState) (*http.Response, erro
 	}
 
 	resp, err := c.New().Put("jobs/"+id).BodyJSON(jobMod).Receive(nil, &aerr)
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
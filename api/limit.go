@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limits caps how fast requests are sent. Zero values are unlimited.
+type Limits struct {
+	// RequestsPerSecond is the sustained rate at which requests may start.
+	RequestsPerSecond float64
+
+	// Burst is how many requests may start at once after a quiet period. It defaults to 1.
+	Burst int
+
+	// MaxInFlight is how many requests may be outstanding at once.
+	// A request is outstanding until its response body has been read or closed.
+	MaxInFlight int
+}
+
+// Limiter enforces Limits. It is safe for concurrent use, and may be shared between clients.
+type Limiter struct {
+	limits Limits
+
+	// Token bucket: how many requests may start now, and when it was last refilled.
+	// A negative count represents requests already waiting for their turn.
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+
+	slots chan struct{}
+}
+
+// NewLimiter creates a Limiter.
+func NewLimiter(limits Limits) *Limiter {
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+
+	l := &Limiter{
+		limits: limits,
+		tokens: float64(limits.Burst),
+		last:   time.Now(),
+	}
+
+	if limits.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limits.MaxInFlight)
+	}
+
+	return l
+}
+
+// Wait blocks until a request may start, or ctx is done.
+// If it returns nil, the caller must call the returned function when the request is finished.
+func (l *Limiter) Wait(ctx context.Context) (func(), error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	err := l.waitRate(ctx)
+	if err != nil {
+		l.release()
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() { once.Do(l.release) }, nil
+}
+
+func (l *Limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// waitRate takes a token from the bucket, waiting for one to be refilled if necessary.
+func (l *Limiter) waitRate(ctx context.Context) error {
+	rate := l.limits.RequestsPerSecond
+	if rate <= 0 {
+		return nil
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * rate
+	if l.tokens > float64(l.limits.Burst) {
+		l.tokens = float64(l.limits.Burst)
+	}
+	l.last = now
+
+	// Reserve a token, even if it has not been refilled yet
+	l.tokens--
+	wait := time.Duration(-l.tokens / rate * float64(time.Second))
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reservation back
+		l.mutex.Lock()
+		l.tokens++
+		l.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// LimitTransport delays requests to stay within the limits of its Limiters.
+//
+// Uploads and downloads are limited by Transfers, and all other requests by Requests.
+// If Transfers is nil, file transfers share the Requests limits. A nil Limiter is unlimited.
+type LimitTransport struct {
+	Requests  *Limiter
+	Transfers *Limiter
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+type transferContextKey struct{}
+
+// markTransfer marks a request as a file transfer, for LimitTransport.
+func markTransfer(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), transferContextKey{}, true))
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *LimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	limiter := t.Requests
+	if transfer, _ := req.Context().Value(transferContextKey{}).(bool); transfer && t.Transfers != nil {
+		limiter = t.Transfers
+	}
+
+	if limiter == nil {
+		return transport.RoundTrip(req)
+	}
+
+	done, err := limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		done()
+		return resp, err
+	}
+
+	resp.Body = &limitedBody{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+// limitedBody frees its request's in-flight slot once it has been read or closed.
+type limitedBody struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	b.done()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListOptions asks the server to filter, sort, limit or trim a listing, rather than sending everything.
+// Every list method accepts them as an optional last argument:
+//
+//	sessions, _, err := client.GetProjectSessions(projectId, &api.ListOptions{
+//		Filter: []string{"tags=reviewed", "modified>" + since.Format(time.RFC3339)},
+//		Sort:   []string{"label"},
+//		Fields: []string{"label", "subject"},
+//	})
+//
+// Filters are a field, an operator and a value. The operators are =, !=, <, <=, >, >= and =~ for a regular expression.
+// Fields can be nested, such as "subject.code" or "info.quality"; a filter on a list field such as tags matches any item.
+type ListOptions struct {
+	// Filter holds conditions every item must meet, such as "tags=reviewed".
+	Filter []string
+
+	// Sort orders the listing by fields, each optionally followed by ":asc" or ":desc", such as "created:desc".
+	Sort []string
+
+	// Limit is the most items to return. Zero means no limit.
+	Limit int
+
+	// Fields, if set, are the only fields returned, such as "label" or "subject.code". IDs are always returned.
+	Fields []string
+}
+
+// mergeListOptions combines the options passed to a list method. Later limits take precedence.
+func mergeListOptions(options []*ListOptions) *ListOptions {
+	if len(options) == 1 {
+		return options[0]
+	}
+
+	var merged *ListOptions
+	for _, o := range options {
+		if o == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &ListOptions{}
+		}
+		merged.Filter = append(merged.Filter, o.Filter...)
+		merged.Sort = append(merged.Sort, o.Sort...)
+		merged.Fields = append(merged.Fields, o.Fields...)
+		if o.Limit != 0 {
+			merged.Limit = o.Limit
+		}
+	}
+	return merged
+}
+
+// values returns the query parameters for the options. A nil ListOptions has none.
+func (o *ListOptions) values() url.Values {
+	v := url.Values{}
+	if o == nil {
+		return v
+	}
+
+	if len(o.Filter) > 0 {
+		v.Set("filter", strings.Join(o.Filter, ","))
+	}
+	if len(o.Sort) > 0 {
+		v.Set("sort", strings.Join(o.Sort, ","))
+	}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if len(o.Fields) > 0 {
+		v.Set("fields", strings.Join(o.Fields, ","))
+	}
+	return v
+}
+
+// listPath adds the query parameters for list options to a route, which may already have some.
+func listPath(path string, options []*ListOptions) string {
+	query := mergeListOptions(options).values().Encode()
+	if query == "" {
+		return path
+	}
+	if strings.Contains(path, "?") {
+		return path + "&" + query
+	}
+	return path + "?" + query
+}
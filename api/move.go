@@ -0,0 +1,308 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// MoveOptions control how Move handles a label that is already taken.
+type MoveOptions struct {
+	// Merge, when the new parent already has a container with the same label, moves the container's children into that one
+	// instead, then deletes the emptied container. Children are merged the same way. Its tags and info fields are added to
+	// the one it is merged into, where missing, and its notes are added again, by the current user.
+	// A container with files or analyses, or with an info field that differs from the same field of the one it would be merged into,
+	// cannot be merged. Without Merge, a label that is taken is a conflict.
+	Merge bool
+
+	// DryRun checks and plans the move, and reports what it would do, without changing anything.
+	DryRun bool
+}
+
+// MoveStep is one change made by a move.
+type MoveStep struct {
+	Container *ContainerReference `json:"container"`
+
+	// Target is the container's new parent or, if Merged, the container it was merged into.
+	Target *ContainerReference `json:"target"`
+
+	// Merged is true if the container's children were moved into Target, and the container deleted.
+	Merged bool `json:"merged,omitempty"`
+
+	// When Merged, Tags, Notes and Info are what was carried over to Target: the tags and info fields it lacked,
+	// and the text of each note.
+	Tags  []string               `json:"tags,omitempty"`
+	Notes []string               `json:"notes,omitempty"`
+	Info  map[string]interface{} `json:"info,omitempty"`
+}
+
+// MoveReport lists the changes made by a move, in the order they were made.
+type MoveReport struct {
+	Steps []*MoveStep `json:"steps"`
+}
+
+// moveParents maps each type that can be moved to the type of its parent, and the field that holds the parent's ID.
+var moveParents = map[ContainerType]struct {
+	t     ContainerType
+	field string
+}{
+	ProjectContainer:     {GroupContainer, "group"},
+	SessionContainer:     {ProjectContainer, "project"},
+	AcquisitionContainer: {SessionContainer, "session"},
+}
+
+// moveChildren maps each type that can be merged to the type of its children.
+var moveChildren = map[ContainerType]ContainerType{
+	ProjectContainer: SessionContainer,
+	SessionContainer: AcquisitionContainer,
+}
+
+// Move gives a project a new group, a session a new project, or an acquisition a new session.
+//
+// Before changing anything, Move checks that the new parent exists, that the current user may write to it,
+// and that it does not already hold a container with the same label. Sessions share a label only if they also share a subject code.
+// Permission failures are errors for which IsForbidden is true, and labels that are taken, errors for which IsConflict is true.
+// If a change fails partway through, the report lists those made before it.
+//
+//	report, err := client.Move(client.Container(api.SessionContainer, id), projectId, &api.MoveOptions{Merge: true})
+func (c *Client) Move(container *Container, parentId string, options *MoveOptions) (*MoveReport, error) {
+	if options == nil {
+		options = &MoveOptions{}
+	}
+	report := &MoveReport{}
+
+	user, _, err := c.GetCurrentUser()
+	if err != nil {
+		return report, err
+	}
+	m := &mover{client: c, user: user, options: options}
+	err = m.plan(container, parentId)
+	if err != nil {
+		return report, err
+	}
+	if options.DryRun {
+		report.Steps = m.steps
+		return report, nil
+	}
+
+	for _, step := range m.steps {
+		moved := c.ContainerOf(step.Container)
+		if step.Merged {
+			err = c.carryOver(step)
+			if err == nil {
+				_, err = moved.Delete()
+			}
+		} else {
+			_, err = moved.Modify(map[string]interface{}{
+				moveParents[moved.Type].field: step.Target.Id,
+			})
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Steps = append(report.Steps, step)
+	}
+	return report, nil
+}
+
+// carryOver adds the tags, notes and info of a merged container to the one it is merged into.
+func (c *Client) carryOver(step *MoveStep) error {
+	into := c.ContainerOf(step.Target)
+	for _, tag := range step.Tags {
+		_, err := into.AddTag(tag)
+		if err != nil {
+			return err
+		}
+	}
+	for _, note := range step.Notes {
+		_, err := into.AddNote(note)
+		if err != nil {
+			return err
+		}
+	}
+	if len(step.Info) > 0 {
+		_, err := into.SetInfo(step.Info)
+		return err
+	}
+	return nil
+}
+
+// moveItem is the part of a container that a move needs.
+// Listings leave out tags, notes and info, so the container merged into is read in full.
+type moveItem struct {
+	Id      string                 `json:"_id"`
+	Label   string                 `json:"label"`
+	Subject *Subject               `json:"subject"`
+	Files   []*File                `json:"files"`
+	Tags    []string               `json:"tags"`
+	Notes   []*Note                `json:"notes"`
+	Info    map[string]interface{} `json:"info"`
+
+	Group   string `json:"group"`
+	Project string `json:"project"`
+	Session string `json:"session"`
+}
+
+func (i *moveItem) parentId(t ContainerType) string {
+	switch t {
+	case ProjectContainer:
+		return i.Group
+	case SessionContainer:
+		return i.Project
+	}
+	return i.Session
+}
+
+// describe names the item as its label, and for sessions, its subject code; items that match are a conflict.
+func (i *moveItem) describe(t ContainerType) string {
+	description := string(t) + " " + strconv.Quote(i.Label)
+	if i.Subject != nil && i.Subject.Code != "" {
+		description += " of subject " + strconv.Quote(i.Subject.Code)
+	}
+	return description
+}
+
+// mover plans a move, checking it as it goes, so that nothing changes unless every step can be made.
+type mover struct {
+	client  *Client
+	user    *User
+	options *MoveOptions
+	steps   []*MoveStep
+}
+
+func (m *mover) plan(container *Container, parentId string) error {
+	parent, movable := moveParents[container.Type]
+	if !movable {
+		return errors.New(container.Type.article() + " " + string(container.Type) + " cannot be moved")
+	}
+
+	var item moveItem
+	_, err := container.Get(&item)
+	if err != nil {
+		return err
+	}
+	if item.parentId(container.Type) == parentId {
+		return nil
+	}
+
+	target := m.client.Container(parent.t, parentId)
+	permissions, _, err := target.Permissions()
+	if err != nil {
+		return err
+	}
+	if !m.mayWrite(permissions) {
+		return &Error{StatusCode: http.StatusForbidden, Message: "User " + m.user.Id + " cannot write to " + string(parent.t) + " " + parentId}
+	}
+
+	var siblings []*moveItem
+	err = m.list(target.Path()+"/"+container.Type.Route(), &siblings)
+	if err != nil {
+		return err
+	}
+	var match *moveItem
+	for _, sibling := range siblings {
+		if sibling.Id != item.Id && sibling.describe(container.Type) == item.describe(container.Type) {
+			match = sibling
+			break
+		}
+	}
+
+	if match == nil {
+		m.steps = append(m.steps, &MoveStep{Container: container.Reference(), Target: target.Reference()})
+		return nil
+	}
+
+	into := m.client.Container(container.Type, match.Id)
+	if !m.options.Merge {
+		return &Error{StatusCode: http.StatusConflict, Message: container.Type.article() + " " + item.describe(container.Type) + " already exists in " + string(parent.t) + " " + parentId}
+	}
+	if len(item.Files) > 0 {
+		return &Error{StatusCode: http.StatusConflict, Message: "The " + item.describe(container.Type) + " has files, and cannot be merged into " + into.Path()}
+	}
+	analyses, _, err := container.Analyses()
+	if err != nil {
+		return err
+	}
+	if len(analyses) > 0 {
+		return &Error{StatusCode: http.StatusConflict, Message: "The " + item.describe(container.Type) + " has analyses, and cannot be merged into " + into.Path()}
+	}
+	var existing moveItem
+	_, err = into.Get(&existing)
+	if err != nil {
+		return err
+	}
+	step := &MoveStep{Container: container.Reference(), Target: into.Reference(), Merged: true}
+	err = carry(step, &item, &existing, container.Type, into)
+	if err != nil {
+		return err
+	}
+
+	if childType, hasChildren := moveChildren[container.Type]; hasChildren {
+		var children []*moveItem
+		err = m.list(container.Path()+"/"+childType.Route(), &children)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			err = m.plan(m.client.Container(childType, child.Id), match.Id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	m.steps = append(m.steps, step)
+	return nil
+}
+
+// carry plans which of the tags, notes and info of an item being merged are carried over to the one it is merged into.
+// Info fields that both have, with different values, and those that cannot be set by path, are a conflict.
+func carry(step *MoveStep, item, existing *moveItem, t ContainerType, into *Container) error {
+	for key, value := range item.Info {
+		current, exists := existing.Info[key]
+		if !addressable(map[string]interface{}{key: value}) || exists && !reflect.DeepEqual(current, value) {
+			return &Error{StatusCode: http.StatusConflict, Message: "The " + item.describe(t) + " has info field " + strconv.Quote(key) + " that cannot be merged into " + into.Path()}
+		}
+		if !exists {
+			if step.Info == nil {
+				step.Info = map[string]interface{}{}
+			}
+			step.Info[key] = value
+		}
+	}
+
+	tagged := map[string]bool{}
+	for _, tag := range existing.Tags {
+		tagged[tag] = true
+	}
+	for _, tag := range item.Tags {
+		if !tagged[tag] {
+			step.Tags = append(step.Tags, tag)
+		}
+	}
+	for _, note := range item.Notes {
+		step.Notes = append(step.Notes, note.Text)
+	}
+	return nil
+}
+
+// mayWrite reports whether the current user may write to a container with the given permissions.
+// Site admins are left to the server, which decides by whether the client is in root mode.
+func (m *mover) mayWrite(permissions []*Permission) bool {
+	if m.user.RootAccess != nil && *m.user.RootAccess {
+		return true
+	}
+	for _, permission := range permissions {
+		if permission.Id == m.user.Id {
+			return permission.Level == ReadWriteAccess || permission.Level == AdminAccess
+		}
+	}
+	return false
+}
+
+func (m *mover) list(path string, result interface{}) error {
+	var aerr *Error
+	resp, err := m.client.New().Get(path).Receive(result, &aerr)
+	return CoalesceResponse(resp, err, aerr)
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // Helper func
-func (c *Client) modifyFileAttrs(url string, attributes *FileFields) (*http.Response, *ModifiedAndJobsResponse, error) {
+func (c *Client) modifyFileAttrs(url string, attributes interface{}) (*http.Response, *ModifiedAndJobsResponse, error) {
 	var aerr *Error
 	var response *ModifiedAndJobsResponse
 
@@ -21,7 +21,7 @@ func (c *Client) modifyFileAttrs(url string, attributes *FileFields) (*http.Resp
 		return resp, nil, errors.New("Modifying file returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
 	}
 
-	return resp, response, Coalesce(err, aerr)
+	return resp, response, CoalesceResponse(resp, err, aerr)
 }
 
 // Helper func
@@ -61,7 +61,7 @@ func (c *Client) deleteFile(url string) (*http.Response, error) {
 		return resp, errors.New("Deleting " + url + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
 	}
 
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 // Helper func
@@ -83,7 +83,7 @@ func (c *Client) postWithOptionalModifiedResponse(url string, body interface{},
 		resp, err = c.New().Post(url).BodyJSON(body).Receive(nil, &aerr)
 	}
 
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 // ParseApiKey accepts an API key and returns the hostname, port, key, and any parsing error.
@@ -123,6 +123,9 @@ type Config struct {
 	// This feature is depreciated.
 	Site map[string]interface{} `json:"site"`
 
+	// Features holds flags for optional features, if the server reports any. See Capability.
+	Features map[string]interface{} `json:"features,omitempty"`
+
 	Created  time.Time `json:"created"`
 	Modified time.Time `json:"modified"`
 }
@@ -131,18 +134,21 @@ func (c *Client) GetConfig() (*Config, *http.Response, error) {
 	var aerr *Error
 	var config *Config
 	resp, err := c.New().Get("config").Receive(&config, &aerr)
-	return config, resp, Coalesce(err, aerr)
+	return config, resp, CoalesceResponse(resp, err, aerr)
 }
 
 // Version identifies the upgrade level of system components.
 type Version struct {
 	// Database represents the database schema level.
 	Database int `json:"database"`
+
+	// Release is the server's release, if it reports one.
+	Release string `json:"release,omitempty"`
 }
 
 func (c *Client) GetVersion() (*Version, *http.Response, error) {
 	var aerr *Error
 	var version *Version
 	resp, err := c.New().Get("version").Receive(&version, &aerr)
-	return version, resp, Coalesce(err, aerr)
+	return version, resp, CoalesceResponse(resp, err, aerr)
 }
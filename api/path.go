@@ -0,0 +1,298 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// pathLevels names what each label of a path refers to.
+var pathLevels = []string{"group", "project", "subject", "session", "acquisition", "file"}
+
+// SplitPath splits a path into its labels, undoing the escaping added by JoinPath.
+func SplitPath(path string) []string {
+	var labels []string
+	var label []byte
+
+	for x := 0; x < len(path); x++ {
+		switch {
+		case path[x] == '\\' && x+1 < len(path):
+			x++
+			label = append(label, path[x])
+		case path[x] == '/':
+			labels = append(labels, string(label))
+			label = nil
+		default:
+			label = append(label, path[x])
+		}
+	}
+	return append(labels, string(label))
+}
+
+// JoinPath joins labels into a path, escaping slashes and backslashes with a backslash.
+func JoinPath(labels ...string) string {
+	escaped := make([]string, len(labels))
+	for x, label := range labels {
+		label = strings.Replace(label, `\`, `\\`, -1)
+		escaped[x] = strings.Replace(label, "/", `\/`, -1)
+	}
+	return strings.Join(escaped, "/")
+}
+
+// ResolvedPath is the chain of containers a path names, outermost first.
+// Fields below the end of the path are nil.
+type ResolvedPath struct {
+	Group       *Group       `json:"group,omitempty"`
+	Project     *Project     `json:"project,omitempty"`
+	Subject     *Subject     `json:"subject,omitempty"`
+	Session     *Session     `json:"session,omitempty"`
+	Acquisition *Acquisition `json:"acquisition,omitempty"`
+	File        *File        `json:"file,omitempty"`
+
+	client *Client
+}
+
+// Container returns a handle to the innermost container of the path.
+func (r *ResolvedPath) Container() *Container {
+	switch {
+	case r.Acquisition != nil:
+		return r.client.Container(AcquisitionContainer, r.Acquisition.Id)
+	case r.Session != nil:
+		return r.client.Container(SessionContainer, r.Session.Id)
+	case r.Subject != nil:
+		return r.client.Container(SubjectContainer, r.Subject.Id)
+	case r.Project != nil:
+		return r.client.Container(ProjectContainer, r.Project.Id)
+	}
+	return r.client.Container(GroupContainer, r.Group.Id)
+}
+
+// PathError is returned when a path does not name exactly one container or file.
+type PathError struct {
+	// Path is the part of the path that was resolved, up to and including the label that failed.
+	Path string
+
+	// Level is what the label should name, such as "session" or "file".
+	Level string
+
+	// Matches holds the IDs of each container with the label, when there is more than one.
+	Matches []string
+
+	// Err is a not-found *Error, when nothing has the label.
+	Err error
+}
+
+func (e *PathError) Error() string {
+	labels := SplitPath(e.Path)
+	label := strconv.Quote(labels[len(labels)-1])
+
+	if len(e.Matches) > 1 {
+		return "Path " + strconv.Quote(e.Path) + " is ambiguous: " + strconv.Itoa(len(e.Matches)) + " " + e.Level + "s are labeled " + label
+	}
+	return "No " + e.Level + " " + label + " in path " + strconv.Quote(e.Path)
+}
+
+// Unwrap returns the not-found *Error, if any.
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// IsAmbiguous reports whether err is a PathError for a label that more than one container has.
+func IsAmbiguous(err error) bool {
+	pathErr, ok := err.(*PathError)
+	return ok && pathErr != nil && len(pathErr.Matches) > 1
+}
+
+// ResolvePath finds the containers, and file, named by a path of labels:
+//
+//	group/project/subject/session/acquisition/file
+//
+// The group may be given by ID or label, and the subject by code. A path may stop at any level.
+// Labels that contain a slash are escaped with a backslash, as by JoinPath.
+//
+// A label that nothing has fails with a PathError for which IsNotFound is true.
+// A label that several containers share fails with a PathError for which IsAmbiguous is true.
+func (c *Client) ResolvePath(path string) (*ResolvedPath, *http.Response, error) {
+	labels := SplitPath(path)
+	if len(labels) > len(pathLevels) {
+		return nil, nil, errors.New("Path " + strconv.Quote(path) + " has more than " + strconv.Itoa(len(pathLevels)) + " levels")
+	}
+	result := &ResolvedPath{client: c}
+
+	// pick chooses the one of n listed items with the label at the given level. Groups also match by ID.
+	pick := func(level, n int, labelOf, idOf func(int) string) (int, error) {
+		var matches []int
+		for x := 0; x < n; x++ {
+			if level == 0 && idOf(x) == labels[level] {
+				return x, nil
+			}
+			if labelOf(x) == labels[level] {
+				matches = append(matches, x)
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], nil
+		}
+
+		pathErr := &PathError{Path: JoinPath(labels[:level+1]...), Level: pathLevels[level]}
+		for _, x := range matches {
+			pathErr.Matches = append(pathErr.Matches, idOf(x))
+		}
+		if len(matches) == 0 {
+			pathErr.Err = &Error{StatusCode: http.StatusNotFound, Message: pathErr.Error()}
+		}
+		return 0, pathErr
+	}
+
+	groups, resp, err := c.GetAllGroups()
+	if err != nil {
+		return nil, resp, err
+	}
+	x, err := pick(0, len(groups), func(x int) string { return groups[x].Name }, func(x int) string { return groups[x].Id })
+	if err != nil {
+		return nil, resp, err
+	}
+	result.Group = groups[x]
+	if len(labels) == 1 {
+		return result, resp, nil
+	}
+
+	projects, resp, err := c.GetGroupProjects(result.Group.Id)
+	if err != nil {
+		return nil, resp, err
+	}
+	x, err = pick(1, len(projects), func(x int) string { return projects[x].Name }, func(x int) string { return projects[x].Id })
+	if err != nil {
+		return nil, resp, err
+	}
+	result.Project = projects[x]
+	if len(labels) == 2 {
+		return result, resp, nil
+	}
+
+	subjects, resp, err := c.GetProjectSubjects(result.Project.Id)
+	if err != nil {
+		return nil, resp, err
+	}
+	x, err = pick(2, len(subjects), func(x int) string { return subjects[x].Code }, func(x int) string { return subjects[x].Id })
+	if err != nil {
+		return nil, resp, err
+	}
+	result.Subject = subjects[x]
+	if len(labels) == 3 {
+		return result, resp, nil
+	}
+
+	sessions, resp, err := c.GetSubjectSessions(result.Subject.Id)
+	if err != nil {
+		return nil, resp, err
+	}
+	x, err = pick(3, len(sessions), func(x int) string { return sessions[x].Name }, func(x int) string { return sessions[x].Id })
+	if err != nil {
+		return nil, resp, err
+	}
+	result.Session = sessions[x]
+	if len(labels) == 4 {
+		return result, resp, nil
+	}
+
+	acquisitions, resp, err := c.GetSessionAcquisitions(result.Session.Id)
+	if err != nil {
+		return nil, resp, err
+	}
+	x, err = pick(4, len(acquisitions), func(x int) string { return acquisitions[x].Name }, func(x int) string { return acquisitions[x].Id })
+	if err != nil {
+		return nil, resp, err
+	}
+	result.Acquisition = acquisitions[x]
+	if len(labels) == 5 {
+		return result, resp, nil
+	}
+
+	// Listings leave out files
+	result.Acquisition, resp, err = c.GetAcquisition(result.Acquisition.Id)
+	if err != nil {
+		return nil, resp, err
+	}
+	files := result.Acquisition.Files
+	x, err = pick(5, len(files), func(x int) string { return files[x].Name }, func(x int) string { return files[x].Name })
+	if err != nil {
+		return nil, resp, err
+	}
+	result.File = files[x]
+	return result, resp, nil
+}
+
+// PathOf returns the path of labels that ResolvePath would resolve to a container.
+// Groups are named by ID. Only groups, projects, subjects, sessions and acquisitions have paths.
+func (c *Client) PathOf(container *Container) (string, *http.Response, error) {
+	var labels []string
+	var resp *http.Response
+	var err error
+	id := container.Id
+
+	switch container.Type {
+	case AcquisitionContainer:
+		var acquisition *Acquisition
+		acquisition, resp, err = c.GetAcquisition(id)
+		if err != nil {
+			return "", resp, err
+		}
+		labels = append(labels, acquisition.Name)
+		id = acquisition.SessionId
+		fallthrough
+
+	case SessionContainer:
+		var session *Session
+		session, resp, err = c.GetSession(id)
+		if err != nil {
+			return "", resp, err
+		}
+		labels = append(labels, session.Name, subjectCode(session))
+		id = session.ProjectId
+		fallthrough
+
+	case SubjectContainer:
+		// Sessions have already added their subject's code
+		if container.Type == SubjectContainer {
+			var subject *Subject
+			subject, resp, err = c.GetSubject(id)
+			if err != nil {
+				return "", resp, err
+			}
+			labels = append(labels, subject.Code)
+			id = subject.ProjectId
+		}
+		fallthrough
+
+	case ProjectContainer:
+		var project *Project
+		project, resp, err = c.GetProject(id)
+		if err != nil {
+			return "", resp, err
+		}
+		labels = append(labels, project.Name)
+		id = project.GroupId
+		fallthrough
+
+	case GroupContainer:
+		labels = append(labels, id)
+
+	default:
+		return "", nil, errors.New(container.Type.article() + " " + string(container.Type) + " does not have a path")
+	}
+
+	// Labels were gathered innermost first
+	for x, y := 0, len(labels)-1; x < y; x, y = x+1, y-1 {
+		labels[x], labels[y] = labels[y], labels[x]
+	}
+	return JoinPath(labels...), resp, nil
+}
+
+func subjectCode(session *Session) string {
+	if session.Subject == nil {
+		return ""
+	}
+	return session.Subject.Code
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Access levels of a Permission, from least to most. On groups, these are the roles a user may have.
+const (
+	ReadOnlyAccess  = "ro"
+	ReadWriteAccess = "rw"
+	AdminAccess     = "admin"
+)
+
+// Permissions lists the users who may access the container, and how.
+func (c *Container) Permissions() ([]*Permission, *http.Response, error) {
+	var doc struct {
+		Permissions []*Permission `json:"permissions"`
+	}
+	resp, err := c.Get(&doc)
+	return doc.Permissions, resp, err
+}
+
+// AddPermission gives a user access to the container. Users who already have access are a conflict.
+func (c *Container) AddPermission(permission *Permission) (*http.Response, error) {
+	return c.modify(c.client.New().Post(c.Path() + "/permissions").BodyJSON(permission))
+}
+
+// ModifyPermission changes a user's access to the container.
+func (c *Container) ModifyPermission(userId, level string) (*http.Response, error) {
+	access := map[string]interface{}{
+		"access": level,
+	}
+	return c.modify(c.client.New().Put(c.permissionPath(userId)).BodyJSON(access))
+}
+
+// DeletePermission removes a user's access to the container.
+func (c *Container) DeletePermission(userId string) (*http.Response, error) {
+	return c.modify(c.client.New().Delete(c.permissionPath(userId)))
+}
+
+// setPermissions makes the container's permissions the same as those given, changing only those that differ.
+// The current permissions are read past any cache, as the server may have changed them along with another container's.
+func (c *Container) setPermissions(permissions []*Permission) (*http.Response, error) {
+	var doc struct {
+		Permissions []*Permission `json:"permissions"`
+	}
+	resp, err := c.getUncached(&doc)
+	if err != nil {
+		return resp, err
+	}
+
+	current := map[string]string{}
+	for _, permission := range doc.Permissions {
+		current[permission.Id] = permission.Level
+	}
+	wanted := map[string]bool{}
+	for _, permission := range permissions {
+		wanted[permission.Id] = true
+		level, exists := current[permission.Id]
+		switch {
+		case !exists:
+			resp, err = c.AddPermission(permission)
+		case level != permission.Level:
+			resp, err = c.ModifyPermission(permission.Id, permission.Level)
+		}
+		if err != nil {
+			return resp, err
+		}
+	}
+	for _, permission := range doc.Permissions {
+		if !wanted[permission.Id] {
+			resp, err = c.DeletePermission(permission.Id)
+			if err != nil {
+				return resp, err
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (c *Container) permissionPath(userId string) string {
+	return c.Path() + "/permissions/" + url.PathEscape(userId)
+}
+
+func (c *Client) GetGroupPermissions(id string) ([]*Permission, *http.Response, error) {
+	return c.Container(GroupContainer, id).Permissions()
+}
+
+// AddGroupPermission gives a user a role in a group. A group has no roles of its own: the permission's level is the role,
+// one of ReadOnlyAccess, ReadWriteAccess or AdminAccess.
+func (c *Client) AddGroupPermission(id string, permission *Permission) (*http.Response, error) {
+	return c.Container(GroupContainer, id).AddPermission(permission)
+}
+
+// ModifyGroupPermission changes a user's role in a group to another access level. See AddGroupPermission.
+func (c *Client) ModifyGroupPermission(id, userId, level string) (*http.Response, error) {
+	return c.Container(GroupContainer, id).ModifyPermission(userId, level)
+}
+
+func (c *Client) DeleteGroupPermission(id, userId string) (*http.Response, error) {
+	return c.Container(GroupContainer, id).DeletePermission(userId)
+}
+
+func (c *Client) GetProjectPermissions(id string) ([]*Permission, *http.Response, error) {
+	return c.Container(ProjectContainer, id).Permissions()
+}
+
+func (c *Client) AddProjectPermission(id string, permission *Permission) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).AddPermission(permission)
+}
+
+func (c *Client) ModifyProjectPermission(id, userId, level string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).ModifyPermission(userId, level)
+}
+
+func (c *Client) DeleteProjectPermission(id, userId string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).DeletePermission(userId)
+}
+
+// PropagateProjectPermissions gives a project's subjects, sessions and acquisitions the same permissions as the project,
+// adding, changing and removing each user's access as needed. The server does this whenever the project's permissions change,
+// so it is needed only for children whose permissions were changed one by one since, or that were moved in from another project.
+// A project without permissions leaves its children without any.
+func (c *Client) PropagateProjectPermissions(id string) (*http.Response, error) {
+	var project struct {
+		Permissions []*Permission `json:"permissions"`
+	}
+	resp, err := c.Container(ProjectContainer, id).getUncached(&project)
+	if err != nil {
+		return resp, err
+	}
+
+	var children []*Container
+	subjects, resp, err := c.GetProjectSubjects(id)
+	if err != nil {
+		return resp, err
+	}
+	for _, subject := range subjects {
+		children = append(children, c.Container(SubjectContainer, subject.Id))
+	}
+	sessions, resp, err := c.GetProjectSessions(id)
+	if err != nil {
+		return resp, err
+	}
+	for _, session := range sessions {
+		children = append(children, c.Container(SessionContainer, session.Id))
+		acquisitions, resp, err := c.GetSessionAcquisitions(session.Id)
+		if err != nil {
+			return resp, err
+		}
+		for _, acquisition := range acquisitions {
+			children = append(children, c.Container(AcquisitionContainer, acquisition.Id))
+		}
+	}
+
+	for _, child := range children {
+		resp, err = child.setPermissions(project.Permissions)
+		if err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+func (c *Client) GetCollectionPermissions(id string) ([]*Permission, *http.Response, error) {
+	return c.Container(CollectionContainer, id).Permissions()
+}
+
+func (c *Client) AddCollectionPermission(id string, permission *Permission) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).AddPermission(permission)
+}
+
+func (c *Client) ModifyCollectionPermission(id, userId, level string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).ModifyPermission(userId, level)
+}
+
+func (c *Client) DeleteCollectionPermission(id, userId string) (*http.Response, error) {
+	return c.Container(CollectionContainer, id).DeletePermission(userId)
+}
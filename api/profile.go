@@ -0,0 +1,296 @@
+package api
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by ProfileFromEnv and NewClientFromEnv.
+// Each one overrides the corresponding setting of the selected profile.
+const (
+	// SdkApiKeyKey sets the API key: "hostname:port:key", or just the key if a host is configured.
+	SdkApiKeyKey = "SdkApiKey"
+
+	// SdkHostKey sets the host: "hostname" or "hostname:port".
+	SdkHostKey = "SdkHost"
+
+	// SdkProfileKey selects a named profile from the config file.
+	SdkProfileKey = "SdkProfile"
+
+	// SdkConfigKey sets the path of the config file. See DefaultConfigPath.
+	SdkConfigKey = "SdkConfig"
+
+	// SdkRootKey, if true, enables root (Manage Site) mode.
+	SdkRootKey = "SdkRoot"
+
+	// SdkInsecureKey, if true, disables SSL verification.
+	SdkInsecureKey = "SdkInsecure"
+
+	// SdkPlaintextKey, if true, uses a plaintext HTTP transport.
+	SdkPlaintextKey = "SdkPlaintext"
+
+	// SdkCaBundleKey sets the path of a PEM-encoded CA bundle to trust.
+	SdkCaBundleKey = "SdkCaBundle"
+)
+
+// DefaultProfileName is used when neither the environment nor the config file selects a profile.
+const DefaultProfileName = "default"
+
+// ProfileProxyFromEnvironment is the Profile.Proxy that selects proxies by environment variables, as ProxyFromEnvironment does.
+const ProfileProxyFromEnvironment = "environment"
+
+// Profile holds everything needed to connect to one Flywheel instance.
+type Profile struct {
+	// Host is "hostname" or "hostname:port". It may be omitted if Key is a full API key.
+	Host string `json:"host,omitempty"`
+
+	// Key is an API key: either "hostname:port:key", or just the key if Host is set.
+	Key string `json:"key,omitempty"`
+
+	// Enable root (Manage Site) mode
+	Root bool `json:"root,omitempty"`
+
+	// Skip SSL verification
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+
+	// Use plaintext (HTTP) transport
+	InsecureUsePlaintext bool `json:"insecure_use_plaintext,omitempty"`
+
+	// Path of a PEM-encoded CA bundle to trust, in addition to the system's
+	CaBundle string `json:"ca_bundle,omitempty"`
+
+	// Paths of a PEM-encoded certificate and key to present to the server, for mutual TLS
+	ClientCertificate string `json:"client_certificate,omitempty"`
+	ClientKey         string `json:"client_key,omitempty"`
+
+	// Proxy URL, such as "http://proxy.example.com:3128", or "environment" to select proxies by the HTTPS_PROXY and NO_PROXY
+	// environment variables. Defaults to connecting directly.
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// ProfileConfig is the format of the config file: a set of named profiles.
+//
+//	{
+//		"default": "work",
+//		"profiles": {
+//			"work":  { "key": "flywheel.example.com:my-key" },
+//			"local": { "host": "localhost:8443", "key": "change-me", "ca_bundle": "/etc/flywheel/local-ca.pem" }
+//		}
+//	}
+type ProfileConfig struct {
+	// Default names the profile to use when none is selected.
+	Default string `json:"default,omitempty"`
+
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// DefaultConfigPath returns the config file location used when SdkConfig is not set: .flywheel/profiles.json in the user's home folder.
+func DefaultConfigPath() string {
+	home := ""
+	if u, err := user.Current(); err == nil {
+		home = u.HomeDir
+	}
+	if home == "" {
+		home = os.Getenv("HOME")
+	}
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+
+	return filepath.Join(home, ".flywheel", "profiles.json")
+}
+
+// LoadProfileConfig reads a config file.
+func LoadProfileConfig(path string) (*ProfileConfig, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ProfileConfig{}
+	err = json.Unmarshal(raw, config)
+	if err != nil {
+		return nil, errors.New("Could not parse config file " + path + ": " + err.Error())
+	}
+	return config, nil
+}
+
+// Profile returns a copy of the named profile.
+// If name is empty, the config's default profile is used.
+func (pc *ProfileConfig) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = pc.Default
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+
+	profile, ok := pc.Profiles[name]
+	if !ok || profile == nil {
+		return nil, errors.New("Profile " + name + " not found")
+	}
+
+	result := *profile
+	return &result, nil
+}
+
+// ApiKey returns the profile's host and key as a single API key, as accepted by NewApiKeyClient.
+func (p *Profile) ApiKey() (string, error) {
+	apiKey := p.Key
+
+	if p.Host != "" && p.Key != "" {
+		apiKey = p.Host + ":" + p.Key
+	}
+
+	_, _, _, err := ParseApiKey(apiKey)
+	if err != nil {
+		return "", errors.New("Invalid API key in profile")
+	}
+	return apiKey, nil
+}
+
+// Options returns the client options the profile enables, loading any certificates it names.
+func (p *Profile) Options() ([]ApiKeyClientOption, error) {
+	options := []ApiKeyClientOption{}
+
+	if p.CaBundle != "" {
+		pool, err := LoadCertPool(p.CaBundle)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, TrustRootCAs(pool))
+	}
+	if p.ClientCertificate != "" || p.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(p.ClientCertificate, p.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, ClientCertificate(cert))
+	}
+	if p.Proxy == ProfileProxyFromEnvironment {
+		options = append(options, ProxyFromEnvironment)
+	} else if p.Proxy != "" {
+		proxy, err := url.Parse(p.Proxy)
+		if err != nil {
+			return nil, errors.New("Invalid proxy in profile: " + err.Error())
+		}
+		options = append(options, UseProxy(http.ProxyURL(proxy)))
+	}
+
+	if p.InsecureSkipVerify {
+		options = append(options, InsecureNoSSLVerification)
+	}
+	if p.InsecureUsePlaintext {
+		options = append(options, InsecureUsePlaintext)
+	}
+	if p.Root {
+		options = append(options, EnableRoot)
+	}
+
+	return options, nil
+}
+
+// NewClientFromProfile creates a Client from a profile.
+// Any options given are applied after those of the profile.
+func NewClientFromProfile(profile *Profile, options ...ApiKeyClientOption) (*Client, error) {
+	apiKey, err := profile.ApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	profileOptions, err := profile.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(profileOptions, options...)
+	return NewApiKeyClient(apiKey, options...), nil
+}
+
+// ProfileFromEnv selects a profile from the config file, then applies any environment overrides.
+//
+// The config file is optional unless SdkConfig or SdkProfile is set, so a key from SdkApiKey alone is enough.
+func ProfileFromEnv() (*Profile, error) {
+	profile := &Profile{}
+
+	path, pathSet := os.LookupEnv(SdkConfigKey)
+	name, nameSet := os.LookupEnv(SdkProfileKey)
+	if !pathSet {
+		path = DefaultConfigPath()
+	}
+
+	config, err := LoadProfileConfig(path)
+	if err == nil {
+		profile, err = config.Profile(name)
+
+		// Without a name or default, an empty profile can be filled in from the environment
+		if err != nil && (nameSet || config.Default != "") {
+			return nil, err
+		} else if err != nil {
+			profile = &Profile{}
+		}
+	} else if pathSet || nameSet || !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if value, ok := os.LookupEnv(SdkApiKeyKey); ok {
+		profile.Key = value
+
+		// A full key brings its own host
+		if strings.Count(value, ":") > 0 {
+			profile.Host = ""
+		}
+	}
+	if value, ok := os.LookupEnv(SdkHostKey); ok {
+		profile.Host = value
+	}
+	if value, ok := os.LookupEnv(SdkCaBundleKey); ok {
+		profile.CaBundle = value
+	}
+
+	flags := []struct {
+		key   string
+		value *bool
+	}{
+		{SdkRootKey, &profile.Root},
+		{SdkInsecureKey, &profile.InsecureSkipVerify},
+		{SdkPlaintextKey, &profile.InsecureUsePlaintext},
+	}
+	for _, flag := range flags {
+		if value, ok := os.LookupEnv(flag.key); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("Invalid value for " + flag.key + ": " + value)
+			}
+			*flag.value = parsed
+		}
+	}
+
+	if profile.Key == "" {
+		return nil, errors.New("No API key found; set " + SdkApiKeyKey + " or configure a profile in " + path)
+	}
+
+	return profile, nil
+}
+
+// NewClientFromEnv creates a Client from the config file and environment. See ProfileFromEnv.
+// The client records or replays as SdkReplay says; see RecordReplayFromEnv.
+// Any options given are applied after those of the profile.
+func NewClientFromEnv(options ...ApiKeyClientOption) (*Client, error) {
+	profile, err := ProfileFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	options = append([]ApiKeyClientOption{RecordReplayFromEnv()}, options...)
+	return NewClientFromProfile(profile, options...)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"io"
 	"sync"
 	"sync/atomic"
@@ -66,6 +67,22 @@ func (r *ProgressReader) start() {
 	close(r.progress)
 }
 
+// closeWhenDone closes the ProgressReader, and the reader it wraps, once ctx is done.
+// This unblocks a Read waiting on a slow source, such as a response body.
+func (r *ProgressReader) closeWhenDone(ctx context.Context) {
+	if ctx.Done() == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			r.Close()
+		case <-r.closed:
+		}
+	}()
+}
+
 // update triggers a non-blocking progress update
 func (r *ProgressReader) update(x int64) {
 	if r.progress != nil {
@@ -86,6 +103,12 @@ func (r *ProgressReader) Update() {
 	r.update(atomic.LoadInt64(&r.count))
 }
 
+// reset starts counting from zero, such as when a failed upload is sent again.
+// Progress is only reported once the new count passes the previous one.
+func (r *ProgressReader) reset() {
+	atomic.StoreInt64(&r.count, 0)
+}
+
 // Read implements io.Reader.
 func (r *ProgressReader) SetReader(newReader io.Reader) {
 	r.Reader = newReader
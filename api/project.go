@@ -1,9 +1,7 @@
 package api
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
 	"time"
 )
 
@@ -26,25 +24,32 @@ type Project struct {
 	Permissions []*Permission `json:"permissions,omitempty"`
 }
 
-func (c *Client) GetAllProjects() ([]*Project, *http.Response, error) {
-	var aerr *Error
-	var projects []*Project
-	resp, err := c.New().Get("projects").Receive(&projects, &aerr)
-	return projects, resp, Coalesce(err, aerr)
+func (c *Client) GetAllProjects(options ...*ListOptions) ([]*Project, *http.Response, error) {
+	projects := []*Project{}
+	it := c.IterateProjects(options...)
+	err := it.collect(func(x interface{}) { projects = append(projects, x.(*Project)) })
+	return projects, it.lastResponse, err
 }
 
 func (c *Client) GetProject(id string) (*Project, *http.Response, error) {
 	var aerr *Error
 	var project *Project
 	resp, err := c.New().Get("projects/"+id).Receive(&project, &aerr)
-	return project, resp, Coalesce(err, aerr)
+	return project, resp, CoalesceResponse(resp, err, aerr)
 }
 
-func (c *Client) GetProjectSessions(id string) ([]*Session, *http.Response, error) {
+func (c *Client) GetProjectSessions(id string, options ...*ListOptions) ([]*Session, *http.Response, error) {
 	var aerr *Error
 	var sessions []*Session
-	resp, err := c.New().Get("projects/"+id+"/sessions").Receive(&sessions, &aerr)
-	return sessions, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath("projects/"+id+"/sessions", options)).Receive(&sessions, &aerr)
+	return sessions, resp, CoalesceResponse(resp, err, aerr)
+}
+
+func (c *Client) GetProjectSubjects(id string, options ...*ListOptions) ([]*Subject, *http.Response, error) {
+	var aerr *Error
+	var subjects []*Subject
+	resp, err := c.New().Get(listPath("projects/"+id+"/subjects", options)).Receive(&subjects, &aerr)
+	return subjects, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddProject(project *Project) (string, *http.Response, error) {
@@ -58,150 +63,134 @@ func (c *Client) AddProject(project *Project) (string, *http.Response, error) {
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddProjectNote(id, text string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
-
-	note := &Note{
-		Text: text,
-	}
-
-	resp, err := c.New().Post("projects/"+id+"/notes").BodyJSON(note).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying project " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
-
-	return resp, Coalesce(err, aerr)
+	return c.Container(ProjectContainer, id).AddNote(text)
 }
 
 func (c *Client) AddProjectTag(id, tag string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
+	return c.Container(ProjectContainer, id).AddTag(tag)
+}
 
-	var tagDoc interface{}
-	tagDoc = map[string]interface{}{
-		"value": tag,
-	}
+func (c *Client) DeleteProjectTag(id, tag string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).DeleteTag(tag)
+}
 
-	resp, err := c.New().Post("projects/"+id+"/tags").BodyJSON(tagDoc).Receive(&response, &aerr)
+func (c *Client) RenameProjectTag(id, tag, newTag string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).RenameTag(tag, newTag)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying project " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+func (c *Client) ModifyProjectNote(id, noteId, text string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).ModifyNote(noteId, text)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteProjectNote(id, noteId string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).DeleteNote(noteId)
 }
 
 func (c *Client) ModifyProject(id string, project *Project) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
+	return c.Container(ProjectContainer, id).Modify(project)
+}
 
-	resp, err := c.New().Put("projects/"+id).BodyJSON(project).Receive(&response, &aerr)
+// ModifyProjectIfUnchanged changes a project, as ModifyProject does, unless it was modified since the given time. See Container.ModifyIfUnchanged.
+func (c *Client) ModifyProjectIfUnchanged(id string, modified *time.Time, project *Project) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).ModifyIfUnchanged(modified, project)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying project " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+// UpdateProject reads a project, passes it to merge, and makes the changes merge returns, reading and merging again
+// if someone else modified the project in between. See Container.Update.
+func (c *Client) UpdateProject(id string, merge func(project *Project) (*Project, error), options *UpdateOptions) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).Update(func() interface{} { return &Project{} }, func(current interface{}) (interface{}, error) {
+		return merge(current.(*Project))
+	}, options)
+}
+
+// MoveProject gives a project a new group. See Move.
+func (c *Client) MoveProject(id, groupId string, options *MoveOptions) (*MoveReport, error) {
+	return c.Move(c.Container(ProjectContainer, id), groupId, options)
+}
 
-	return resp, Coalesce(err, aerr)
+// CopyProject copies a project, with its sessions and acquisitions, to a group. See Copy.
+func (c *Client) CopyProject(id, groupId string, options *CopyOptions) (string, error) {
+	return c.Copy(c.Container(ProjectContainer, id), groupId, options)
 }
 
 func (c *Client) SetProjectInfo(id string, set map[string]interface{}) (*http.Response, error) {
-	url := "projects/" + id + "/info"
-	return c.setInfo(url, set, false)
+	return c.Container(ProjectContainer, id).SetInfo(set)
 }
 
 func (c *Client) ReplaceProjectInfo(id string, replace map[string]interface{}) (*http.Response, error) {
-	url := "projects/" + id + "/info"
-	return c.replaceInfo(url, replace, false)
+	return c.Container(ProjectContainer, id).ReplaceInfo(replace)
 }
 
 func (c *Client) DeleteProjectInfoFields(id string, keys []string) (*http.Response, error) {
-	url := "projects/" + id + "/info"
-	return c.deleteInfoFields(url, keys, false)
+	return c.Container(ProjectContainer, id).DeleteInfoFields(keys)
 }
 
-func (c *Client) DeleteProject(id string) (*http.Response, error) {
-	var aerr *Error
-	var response *DeletedResponse
-
-	resp, err := c.New().Delete("projects/"+id).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.DeletedCount != 1 {
-		return resp, errors.New("Deleting project " + id + " returned " + strconv.Itoa(response.DeletedCount) + " instead of 1")
-	}
+// SyncProjectInfo changes a project's info to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncProjectInfo(id string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(ProjectContainer, id).SyncInfo(desired)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteProject(id string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).Delete()
 }
 
 func (c *Client) UploadToProject(id string, files ...*UploadSource) (chan int64, chan error) {
-	url := "projects/" + id + "/files"
-	return c.UploadSimple(url, nil, files...)
+	return c.Container(ProjectContainer, id).Upload(files...)
 }
 
 func (c *Client) ModifyProjectFile(id string, filename string, attributes *FileFields) (*http.Response, *ModifiedAndJobsResponse, error) {
-	url := "projects/" + id + "/files/" + filename
-	return c.modifyFileAttrs(url, attributes)
+	return c.Container(ProjectContainer, id).ModifyFile(filename, attributes)
 }
 
 func (c *Client) DeleteProjectFile(id string, filename string) (*http.Response, error) {
-	url := "projects/" + id + "/files/" + filename
-	return c.deleteFile(url)
+	return c.Container(ProjectContainer, id).DeleteFile(filename)
 }
 
 func (c *Client) SetProjectFileInfo(id string, filename string, set map[string]interface{}) (*http.Response, error) {
-	url := "projects/" + id + "/files/" + filename + "/info"
-	return c.setInfo(url, set, true)
+	return c.Container(ProjectContainer, id).SetFileInfo(filename, set)
 }
 
 func (c *Client) ReplaceProjectFileInfo(id string, filename string, replace map[string]interface{}) (*http.Response, error) {
-	url := "projects/" + id + "/files/" + filename + "/info"
-	return c.replaceInfo(url, replace, true)
+	return c.Container(ProjectContainer, id).ReplaceFileInfo(filename, replace)
 }
 
 func (c *Client) DeleteProjectFileInfoFields(id string, filename string, keys []string) (*http.Response, error) {
-	url := "projects/" + id + "/files/" + filename + "/info"
-	return c.deleteInfoFields(url, keys, true)
+	return c.Container(ProjectContainer, id).DeleteFileInfoFields(filename, keys)
+}
+
+// SyncProjectFileInfo changes the info of a project's file to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncProjectFileInfo(id string, filename string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(ProjectContainer, id).SyncFileInfo(filename, desired)
+}
+
+func (c *Client) AddProjectFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).AddFileTag(filename, tag)
+}
+
+func (c *Client) DeleteProjectFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).DeleteFileTag(filename, tag)
+}
+
+func (c *Client) RenameProjectFileTag(id string, filename string, tag string, newTag string) (*http.Response, error) {
+	return c.Container(ProjectContainer, id).RenameFileTag(filename, tag, newTag)
 }
 
 func (c *Client) DownloadFromProject(id string, filename string, destination *DownloadSource) (chan int64, chan error) {
-	url := "projects/" + id + "/files/" + filename
-	return c.DownloadSimple(url, destination)
+	return c.Container(ProjectContainer, id).Download(filename, destination)
 }
 
 func (c *Client) GetProjectDownloadUrl(id string, filename string) (string, *http.Response, error) {
-	return c.GetTicketDownloadUrl("projects", id, filename)
+	return c.Container(ProjectContainer, id).DownloadUrl(filename)
 }
 
-// No progress reporting
 func (c *Client) UploadFileToProject(id string, path string) error {
-	src := CreateUploadSourceFromFilenames(path)
-	progress, result := c.UploadToProject(id, src...)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(ProjectContainer, id).UploadFile(path)
 }
 
-// No progress reporting
 func (c *Client) DownloadFileFromProject(id, name string, path string) error {
-	src := CreateDownloadSourceFromFilename(path)
-	progress, result := c.DownloadFromProject(id, name, src)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(ProjectContainer, id).DownloadFile(name, path)
 }
@@ -0,0 +1,405 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// SdkReplayKey is the environment variable that records or replays the requests of clients made by NewClientFromEnv,
+// or given the RecordReplayFromEnv option.
+// Valid values are the RecordingModes: "record", "replay", and "auto". See ReplayTransport.
+const SdkReplayKey = "SdkReplay"
+
+// SdkReplayFileKey is the environment variable that sets the recording file used with SdkReplay.
+// Defaults to DefaultRecordingPath.
+const SdkReplayFileKey = "SdkReplayFile"
+
+// DefaultRecordingPath is the recording file used with SdkReplay if SdkReplayFile is not set.
+const DefaultRecordingPath = "sdk-recording.json"
+
+// RecordingMode selects whether ReplayTransport records or replays.
+type RecordingMode string
+
+const (
+	// Record sends requests to the server, saving each interaction.
+	Record RecordingMode = "record"
+
+	// Replay answers requests from saved interactions, never contacting the server.
+	Replay RecordingMode = "replay"
+
+	// ReplayOrRecord replays if the recording file exists, and records otherwise.
+	ReplayOrRecord RecordingMode = "auto"
+)
+
+// Recording is the format of a recording file: every interaction, in the order they occurred.
+type Recording struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is one request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request without its host, so that recordings can be replayed against any server.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+
+	// BodyEncoding is "base64" for binary bodies.
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// ReplayMatcher reports whether a recorded request matches a live one.
+// The live request is scrubbed in the same way as recordings; body is its content.
+type ReplayMatcher func(req *http.Request, body []byte, recorded *RecordedRequest) bool
+
+// MatchRequests matches requests by method and URL. It is the default matcher.
+//
+// Bodies are ignored, so that tests which send random names can be replayed.
+func MatchRequests(req *http.Request, body []byte, recorded *RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL.RequestURI() == recorded.Url
+}
+
+// MatchRequestsAndBodies matches requests by method, URL, and body. JSON bodies are compared by value.
+func MatchRequestsAndBodies(req *http.Request, body []byte, recorded *RecordedRequest) bool {
+	if !MatchRequests(req, body, recorded) {
+		return false
+	}
+
+	recordedBody, err := decodeRecordedBody(recorded.Body, recorded.BodyEncoding)
+	if err != nil {
+		return false
+	}
+
+	var x, y interface{}
+	if json.Unmarshal(body, &x) == nil && json.Unmarshal(recordedBody, &y) == nil {
+		return reflect.DeepEqual(x, y)
+	}
+	return bytes.Equal(body, recordedBody)
+}
+
+// ReplayTransport records interactions with the server to a file, and replays them, so that tests can run offline.
+//
+// Credentials are scrubbed from recordings: Authorization and cookie headers are removed, download tickets are redacted,
+// and any credential sent in an Authorization header is replaced wherever it appears, such as a user's API key.
+//
+// When replaying, each request is answered by the first unused interaction that matches it.
+// Requests without a match fail.
+//
+// All transports with the same Path and Mode share one recording, so that many clients can record to one file.
+type ReplayTransport struct {
+	// Path of the recording file.
+	Path string
+
+	Mode RecordingMode
+
+	// Matcher defaults to MatchRequests if nil.
+	Matcher ReplayMatcher
+
+	// Scrub lists further secrets to replace in recordings.
+	Scrub []string
+
+	// Transport is the underlying HTTP transport to use when recording.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// recordingFile is the state of one recording file, shared by every ReplayTransport that uses it.
+type recordingFile struct {
+	path string
+	mode RecordingMode
+
+	once      sync.Once
+	loadErr   error
+	replaying bool
+
+	mutex     sync.Mutex
+	recording Recording
+	used      []bool
+	secrets   []string
+}
+
+// Recording files by mode and absolute path
+var recordingFiles = map[string]*recordingFile{}
+var recordingFilesMutex sync.Mutex
+
+// openRecording returns the shared state of a recording file.
+func openRecording(path string, mode RecordingMode) *recordingFile {
+	abs, err := filepath.Abs(path)
+	if err == nil {
+		path = abs
+	}
+
+	recordingFilesMutex.Lock()
+	defer recordingFilesMutex.Unlock()
+
+	key := string(mode) + ":" + path
+	file, ok := recordingFiles[key]
+	if !ok {
+		file = &recordingFile{path: path, mode: mode}
+		recordingFiles[key] = file
+	}
+
+	file.once.Do(file.load)
+	return file
+}
+
+const scrubbed = "[scrubbed]"
+
+// RoundTrip implements the RoundTripper interface.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	file := openRecording(t.Path, t.Mode)
+	if file.loadErr != nil {
+		return nil, file.loadErr
+	}
+
+	// Bodies are read in full, both to record them and so that upload writers finish
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if file.replaying {
+		return t.replay(file, req, body)
+	}
+	return t.record(file, req, body)
+}
+
+// load reads the recording file, if replaying.
+func (f *recordingFile) load() {
+	switch f.mode {
+	case Record:
+		return
+	case Replay, ReplayOrRecord:
+	default:
+		f.loadErr = errors.New("Invalid recording mode " + strconv.Quote(string(f.mode)))
+		return
+	}
+
+	raw, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) && f.mode == ReplayOrRecord {
+		return
+	} else if err != nil {
+		f.loadErr = err
+		return
+	}
+
+	err = json.Unmarshal(raw, &f.recording)
+	if err != nil {
+		f.loadErr = errors.New("Could not parse recording " + f.path + ": " + err.Error())
+		return
+	}
+
+	f.replaying = true
+	f.used = make([]bool, len(f.recording.Interactions))
+}
+
+func (t *ReplayTransport) replay(file *recordingFile, req *http.Request, body []byte) (*http.Response, error) {
+	matcher := t.Matcher
+	if matcher == nil {
+		matcher = MatchRequests
+	}
+
+	scrubbedReq := new(http.Request)
+	*scrubbedReq = *req
+	scrubbedReq.URL = redactRequest(req).URL
+
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+
+	for x, interaction := range file.recording.Interactions {
+		if file.used[x] || !matcher(scrubbedReq, body, &interaction.Request) {
+			continue
+		}
+		file.used[x] = true
+
+		recorded := interaction.Response
+		respBody, err := decodeRecordedBody(recorded.Body, recorded.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+
+		header := http.Header{}
+		for key, values := range recorded.Header {
+			header[key] = append([]string{}, values...)
+		}
+
+		return &http.Response{
+			Status:        strconv.Itoa(recorded.StatusCode) + " " + http.StatusText(recorded.StatusCode),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, errors.New("No recorded interaction for " + req.Method + " " + scrubbedReq.URL.RequestURI())
+}
+
+func (t *ReplayTransport) record(file *recordingFile, req *http.Request, body []byte) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	sent := new(http.Request)
+	*sent = *req
+	if body != nil {
+		sent.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := transport.RoundTrip(sent)
+	if err != nil {
+		return resp, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+
+	// Learn the credential in use, so that it can be scrubbed wherever it is echoed
+	auth := req.Header.Get("Authorization")
+	if auth != "" {
+		splits := strings.SplitN(auth, " ", 2)
+		file.secrets = appendSecret(file.secrets, splits[len(splits)-1])
+	}
+
+	secrets := append(file.secrets[:len(file.secrets):len(file.secrets)], t.Scrub...)
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Url:    scrub(redactRequest(req).URL.RequestURI(), secrets),
+			Header: scrubHeader(req.Header, secrets),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header, secrets),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(body, secrets)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(respBody, secrets)
+
+	file.recording.Interactions = append(file.recording.Interactions, interaction)
+	return resp, file.save()
+}
+
+// save writes the recording file, replacing it atomically so that an interrupted test leaves a usable file.
+func (f *recordingFile) save() error {
+	raw, err := json.MarshalIndent(f.recording, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.path)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(f.path))
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(raw)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// scrub replaces each secret in a string.
+func scrub(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, scrubbed, -1)
+		}
+	}
+	return s
+}
+
+func scrubHeader(h http.Header, secrets []string) http.Header {
+	result := http.Header{}
+	for key, values := range h {
+		for _, value := range values {
+			result.Add(key, scrub(value, secrets))
+		}
+	}
+	for _, key := range debugRedactedHeaders {
+		result.Del(key)
+	}
+	return result
+}
+
+// encodeBody scrubs a body, encoding it as base64 if it is binary.
+// Binary bodies are not scrubbed, as they are file contents rather than API responses.
+func encodeBody(body []byte, secrets []string) (string, string) {
+	if !utf8.Valid(body) || bytes.IndexByte(body, 0) >= 0 {
+		return base64.StdEncoding.EncodeToString(body), "base64"
+	}
+	return scrub(string(body), secrets), ""
+}
+
+func decodeRecordedBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, errors.New("Unknown body encoding " + strconv.Quote(encoding))
+	}
+}
+
+// appendSecret adds a secret to a list, unless already present.
+func appendSecret(secrets []string, secret string) []string {
+	if secret == "" {
+		return secrets
+	}
+	for _, existing := range secrets {
+		if existing == secret {
+			return secrets
+		}
+	}
+	return append(secrets, secret)
+}
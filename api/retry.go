@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how RetryTransport resends failed requests.
+//
+// Connection failures and throttling or gateway responses (429, 502, 503, 504) are retried.
+// By default, only idempotent requests are retried; see RetryPost.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request may be sent, including the first.
+	// Values below two disable retries.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. Each further retry doubles the wait, up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Jitter randomizes each wait by up to this fraction of itself, so that many clients do not retry in lockstep.
+	// For example, 0.2 waits between 80% and 120% of the backoff.
+	Jitter float64
+
+	// RetryPost allows POST requests to be retried.
+	// Only enable this if every POST made by the client is safe to repeat.
+	// Uploads are retried regardless of this setting, as long as their sources can be read again.
+	RetryPost bool
+}
+
+// DefaultRetryPolicy is used by the EnableRetry option.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+	Jitter:      0.2,
+}
+
+// RetryTransport resends requests that failed due to transient errors, waiting between attempts.
+//
+// A response with a Retry-After header is waited out as the server requests, unless that is longer than the policy's MaxBackoff.
+// Then the response is returned without retrying, so that the caller is not held up; IsRetryable is true of its error.
+// Requests with a body can only be retried if the body can be recreated with http.Request.GetBody.
+type RetryTransport struct {
+	Policy RetryPolicy
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+type retryContextKey struct{}
+
+// allowRetry marks a request as safe to retry, regardless of its method.
+func allowRetry(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), retryContextKey{}, true))
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if !t.canRetry(req) {
+		return transport.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+
+		// Each attempt after the first needs a fresh body
+		if attempt > 1 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			attemptReq = new(http.Request)
+			*attemptReq = *req
+			attemptReq.Body = body
+		}
+
+		resp, err := transport.RoundTrip(attemptReq)
+
+		if attempt >= t.Policy.MaxAttempts || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait, ok := t.Policy.backoff(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+
+		// Drain the response so the connection can be reused
+		if resp != nil && resp.Body != nil {
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// canRetry reports whether a request may be sent more than once.
+func (t *RetryTransport) canRetry(req *http.Request) bool {
+	if t.Policy.MaxAttempts < 2 {
+		return false
+	}
+
+	// A consumed body cannot be sent again
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	if marked, _ := req.Context().Value(retryContextKey{}).(bool); marked {
+		return true
+	}
+
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	case "POST":
+		return t.Policy.RetryPost
+	default:
+		return false
+	}
+}
+
+// shouldRetry reports whether a request's outcome is a transient failure.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// Cancellation and certificate problems will not resolve themselves
+		if req.Context().Err() != nil {
+			return false
+		}
+		return !isCertificateError(err)
+	}
+
+	return retryableStatus(resp.StatusCode)
+}
+
+// isCertificateError reports whether err, however it is wrapped, is a failure to verify the server's certificate.
+func isCertificateError(err error) bool {
+	for ; err != nil; err = unwrapError(err) {
+		switch err.(type) {
+		case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError:
+			return true
+		}
+	}
+	return false
+}
+
+// unwrapError returns the error that err wraps, or nil. Go 1.9 has neither errors.Unwrap nor an Unwrap method on *url.Error,
+// so that is unwrapped by hand, along with any error that has an Unwrap method, such as the one Go 1.20 puts around certificate errors.
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case *url.Error:
+		return e.Err
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	}
+	return nil
+}
+
+// backoff returns how long to wait after a failed attempt, or false if the response asks for a longer wait than MaxBackoff.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, p.MaxBackoff <= 0 || wait <= p.MaxBackoff
+		}
+	}
+
+	wait := p.MinBackoff
+	for x := 1; x < attempt && wait < p.MaxBackoff; x++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		wait += time.Duration(float64(wait) * p.Jitter * (2*rand.Float64() - 1))
+	}
+
+	return wait, true
+}
+
+// parseRetryAfter interprets a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := date.Sub(time.Now())
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
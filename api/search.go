@@ -121,7 +121,7 @@ func (c *Client) Search(search_query *SearchQuery) ([]*SearchResponse, *http.Res
 
 	resp, err := c.New().Post(url).BodyJSON(search_query).Receive(&response, &aerr)
 
-	return response, resp, Coalesce(err, aerr)
+	return response, resp, c.unsupported(DataExplorerSearch, CoalesceResponse(resp, err, aerr))
 }
 
 // SearchResponse is used for endpoints of data_explorer
@@ -142,5 +142,5 @@ func (c *Client) SearchRaw(search_query *SearchQuery) (*RawSearchResponseList, *
 
 	resp, err := c.New().Post("dataexplorer/search").BodyJSON(search_query).Receive(&response, &aerr)
 
-	return response, resp, Coalesce(err, aerr)
+	return response, resp, c.unsupported(DataExplorerSearch, CoalesceResponse(resp, err, aerr))
 }
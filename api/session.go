@@ -1,24 +1,10 @@
 package api
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
 	"time"
 )
 
-type Subject struct {
-	Id   string `json:"_id,omitempty" bson:"_id"`
-	Code string `json:"code,omitempty"`
-
-	Firstname string `json:"firstname,omitempty"`
-	Lastname  string `json:"lastname,omitempty"`
-
-	Sex  string                 `json:"sex,omitempty"`
-	Age  int                    `json:"age,omitempty"`
-	Info map[string]interface{} `json:"info,omitempty"`
-}
-
 type Session struct {
 	Id        string `json:"_id,omitempty" bson:"_id"`
 	Name      string `json:"label,omitempty" bson:"label"`
@@ -45,24 +31,24 @@ type Session struct {
 	Analyses []*Analysis `json:"analyses,omitempty"`
 }
 
-func (c *Client) GetAllSessions() ([]*Session, *http.Response, error) {
-	var aerr *Error
-	var sessions []*Session
-	resp, err := c.New().Get("sessions").Receive(&sessions, &aerr)
-	return sessions, resp, Coalesce(err, aerr)
+func (c *Client) GetAllSessions(options ...*ListOptions) ([]*Session, *http.Response, error) {
+	sessions := []*Session{}
+	it := c.IterateSessions(options...)
+	err := it.collect(func(x interface{}) { sessions = append(sessions, x.(*Session)) })
+	return sessions, it.lastResponse, err
 }
 
 func (c *Client) GetSession(id string) (*Session, *http.Response, error) {
 	var aerr *Error
 	var session *Session
 	resp, err := c.New().Get("sessions/"+id).Receive(&session, &aerr)
-	return session, resp, Coalesce(err, aerr)
+	return session, resp, CoalesceResponse(resp, err, aerr)
 }
-func (c *Client) GetSessionAcquisitions(id string) ([]*Acquisition, *http.Response, error) {
+func (c *Client) GetSessionAcquisitions(id string, options ...*ListOptions) ([]*Acquisition, *http.Response, error) {
 	var aerr *Error
 	var acquisitions []*Acquisition
-	resp, err := c.New().Get("sessions/"+id+"/acquisitions").Receive(&acquisitions, &aerr)
-	return acquisitions, resp, Coalesce(err, aerr)
+	resp, err := c.New().Get(listPath("sessions/"+id+"/acquisitions", options)).Receive(&acquisitions, &aerr)
+	return acquisitions, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddSession(session *Session) (string, *http.Response, error) {
@@ -76,150 +62,134 @@ func (c *Client) AddSession(session *Session) (string, *http.Response, error) {
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 func (c *Client) AddSessionNote(id, text string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
-
-	note := &Note{
-		Text: text,
-	}
-
-	resp, err := c.New().Post("sessions/"+id+"/notes").BodyJSON(note).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying session " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
-
-	return resp, Coalesce(err, aerr)
+	return c.Container(SessionContainer, id).AddNote(text)
 }
 
 func (c *Client) AddSessionTag(id, tag string) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
+	return c.Container(SessionContainer, id).AddTag(tag)
+}
 
-	var tagDoc interface{}
-	tagDoc = map[string]interface{}{
-		"value": tag,
-	}
+func (c *Client) DeleteSessionTag(id, tag string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).DeleteTag(tag)
+}
 
-	resp, err := c.New().Post("sessions/"+id+"/tags").BodyJSON(tagDoc).Receive(&response, &aerr)
+func (c *Client) RenameSessionTag(id, tag, newTag string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).RenameTag(tag, newTag)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying session " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+func (c *Client) ModifySessionNote(id, noteId, text string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).ModifyNote(noteId, text)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteSessionNote(id, noteId string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).DeleteNote(noteId)
 }
 
 func (c *Client) ModifySession(id string, session *Session) (*http.Response, error) {
-	var aerr *Error
-	var response *ModifiedResponse
+	return c.Container(SessionContainer, id).Modify(session)
+}
 
-	resp, err := c.New().Put("sessions/"+id).BodyJSON(session).Receive(&response, &aerr)
+// ModifySessionIfUnchanged changes a session, as ModifySession does, unless it was modified since the given time. See Container.ModifyIfUnchanged.
+func (c *Client) ModifySessionIfUnchanged(id string, modified *time.Time, session *Session) (*http.Response, error) {
+	return c.Container(SessionContainer, id).ModifyIfUnchanged(modified, session)
+}
 
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.ModifiedCount != 1 {
-		return resp, errors.New("Modifying session " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
-	}
+// UpdateSession reads a session, passes it to merge, and makes the changes merge returns, reading and merging again
+// if someone else modified the session in between. See Container.Update.
+func (c *Client) UpdateSession(id string, merge func(session *Session) (*Session, error), options *UpdateOptions) (*http.Response, error) {
+	return c.Container(SessionContainer, id).Update(func() interface{} { return &Session{} }, func(current interface{}) (interface{}, error) {
+		return merge(current.(*Session))
+	}, options)
+}
+
+// MoveSession gives a session a new project. See Move.
+func (c *Client) MoveSession(id, projectId string, options *MoveOptions) (*MoveReport, error) {
+	return c.Move(c.Container(SessionContainer, id), projectId, options)
+}
 
-	return resp, Coalesce(err, aerr)
+// CopySession copies a session, with its acquisitions, to a project. See Copy.
+func (c *Client) CopySession(id, projectId string, options *CopyOptions) (string, error) {
+	return c.Copy(c.Container(SessionContainer, id), projectId, options)
 }
 
 func (c *Client) SetSessionInfo(id string, set map[string]interface{}) (*http.Response, error) {
-	url := "sessions/" + id + "/info"
-	return c.setInfo(url, set, false)
+	return c.Container(SessionContainer, id).SetInfo(set)
 }
 
 func (c *Client) ReplaceSessionInfo(id string, replace map[string]interface{}) (*http.Response, error) {
-	url := "sessions/" + id + "/info"
-	return c.replaceInfo(url, replace, false)
+	return c.Container(SessionContainer, id).ReplaceInfo(replace)
 }
 
 func (c *Client) DeleteSessionInfoFields(id string, keys []string) (*http.Response, error) {
-	url := "sessions/" + id + "/info"
-	return c.deleteInfoFields(url, keys, false)
+	return c.Container(SessionContainer, id).DeleteInfoFields(keys)
 }
 
-func (c *Client) DeleteSession(id string) (*http.Response, error) {
-	var aerr *Error
-	var response *DeletedResponse
-
-	resp, err := c.New().Delete("sessions/"+id).Receive(&response, &aerr)
-
-	// Should not have to check this count
-	// https://github.com/scitran/core/issues/680
-	if err == nil && aerr == nil && response.DeletedCount != 1 {
-		return resp, errors.New("Deleting session " + id + " returned " + strconv.Itoa(response.DeletedCount) + " instead of 1")
-	}
+// SyncSessionInfo changes a session's info to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncSessionInfo(id string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(SessionContainer, id).SyncInfo(desired)
+}
 
-	return resp, Coalesce(err, aerr)
+func (c *Client) DeleteSession(id string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).Delete()
 }
 
 func (c *Client) UploadToSession(id string, files ...*UploadSource) (chan int64, chan error) {
-	url := "sessions/" + id + "/files"
-	return c.UploadSimple(url, nil, files...)
+	return c.Container(SessionContainer, id).Upload(files...)
 }
 
 func (c *Client) ModifySessionFile(id string, filename string, attributes *FileFields) (*http.Response, *ModifiedAndJobsResponse, error) {
-	url := "sessions/" + id + "/files/" + filename
-	return c.modifyFileAttrs(url, attributes)
+	return c.Container(SessionContainer, id).ModifyFile(filename, attributes)
 }
 
 func (c *Client) DeleteSessionFile(id string, filename string) (*http.Response, error) {
-	url := "sessions/" + id + "/files/" + filename
-	return c.deleteFile(url)
+	return c.Container(SessionContainer, id).DeleteFile(filename)
 }
 
 func (c *Client) SetSessionFileInfo(id string, filename string, set map[string]interface{}) (*http.Response, error) {
-	url := "sessions/" + id + "/files/" + filename + "/info"
-	return c.setInfo(url, set, true)
+	return c.Container(SessionContainer, id).SetFileInfo(filename, set)
 }
 
 func (c *Client) ReplaceSessionFileInfo(id string, filename string, replace map[string]interface{}) (*http.Response, error) {
-	url := "sessions/" + id + "/files/" + filename + "/info"
-	return c.replaceInfo(url, replace, true)
+	return c.Container(SessionContainer, id).ReplaceFileInfo(filename, replace)
 }
 
 func (c *Client) DeleteSessionFileInfoFields(id string, filename string, keys []string) (*http.Response, error) {
-	url := "sessions/" + id + "/files/" + filename + "/info"
-	return c.deleteInfoFields(url, keys, true)
+	return c.Container(SessionContainer, id).DeleteFileInfoFields(filename, keys)
+}
+
+// SyncSessionFileInfo changes the info of a session's file to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncSessionFileInfo(id string, filename string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(SessionContainer, id).SyncFileInfo(filename, desired)
+}
+
+func (c *Client) AddSessionFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).AddFileTag(filename, tag)
+}
+
+func (c *Client) DeleteSessionFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).DeleteFileTag(filename, tag)
+}
+
+func (c *Client) RenameSessionFileTag(id string, filename string, tag string, newTag string) (*http.Response, error) {
+	return c.Container(SessionContainer, id).RenameFileTag(filename, tag, newTag)
 }
 
 func (c *Client) DownloadFromSession(id string, filename string, destination *DownloadSource) (chan int64, chan error) {
-	url := "sessions/" + id + "/files/" + filename
-	return c.DownloadSimple(url, destination)
+	return c.Container(SessionContainer, id).Download(filename, destination)
 }
 
 func (c *Client) GetSessionDownloadUrl(id string, filename string) (string, *http.Response, error) {
-	return c.GetTicketDownloadUrl("sessions", id, filename)
+	return c.Container(SessionContainer, id).DownloadUrl(filename)
 }
 
-// No progress reporting
 func (c *Client) UploadFileToSession(id string, path string) error {
-	src := CreateUploadSourceFromFilenames(path)
-	progress, result := c.UploadToSession(id, src...)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(SessionContainer, id).UploadFile(path)
 }
 
-// No progress reporting
 func (c *Client) DownloadFileFromSession(id, name string, path string) error {
-	src := CreateDownloadSourceFromFilename(path)
-	progress, result := c.DownloadFromSession(id, name, src)
-
-	// drain and report
-	for range progress {
-	}
-	return <-result
+	return c.Container(SessionContainer, id).DownloadFile(name, path)
 }
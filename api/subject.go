@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// Subject is a person or animal that sessions are recorded from. Subjects belong to a project, and are identified within it by code.
+// Sessions hold a copy of their subject's fields; changes to the subject are copied to each of its sessions.
+type Subject struct {
+	Id        string `json:"_id,omitempty" bson:"_id"`
+	ProjectId string `json:"project,omitempty"`
+	Code      string `json:"code,omitempty"`
+
+	Firstname string `json:"firstname,omitempty"`
+	Lastname  string `json:"lastname,omitempty"`
+
+	Sex  string                 `json:"sex,omitempty"`
+	Age  int                    `json:"age,omitempty"`
+	Info map[string]interface{} `json:"info,omitempty"`
+
+	Notes []*Note  `json:"notes,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+
+	Created  *time.Time `json:"created,omitempty"`
+	Modified *time.Time `json:"modified,omitempty"`
+	Files    []*File    `json:"files,omitempty"`
+
+	Permissions []*Permission `json:"permissions,omitempty"`
+}
+
+func (c *Client) GetAllSubjects(options ...*ListOptions) ([]*Subject, *http.Response, error) {
+	subjects := []*Subject{}
+	it := c.IterateSubjects(options...)
+	err := it.collect(func(x interface{}) { subjects = append(subjects, x.(*Subject)) })
+	return subjects, it.lastResponse, err
+}
+
+func (c *Client) GetSubject(id string) (*Subject, *http.Response, error) {
+	var aerr *Error
+	var subject *Subject
+	resp, err := c.New().Get("subjects/"+id).Receive(&subject, &aerr)
+	return subject, resp, CoalesceResponse(resp, err, aerr)
+}
+
+func (c *Client) GetSubjectSessions(id string, options ...*ListOptions) ([]*Session, *http.Response, error) {
+	var aerr *Error
+	var sessions []*Session
+	resp, err := c.New().Get(listPath("subjects/"+id+"/sessions", options)).Receive(&sessions, &aerr)
+	return sessions, resp, CoalesceResponse(resp, err, aerr)
+}
+
+// AddSubject creates a subject in a project. Codes are unique within a project; a code already in use is a conflict.
+// Sessions added with a Subject whose code or ID matches an existing subject are linked to it, rather than creating another.
+func (c *Client) AddSubject(subject *Subject) (string, *http.Response, error) {
+	var aerr *Error
+	var response *IdResponse
+	var result string
+
+	resp, err := c.New().Post("subjects").BodyJSON(subject).Receive(&response, &aerr)
+
+	if response != nil {
+		result = response.Id
+	}
+
+	return result, resp, CoalesceResponse(resp, err, aerr)
+}
+
+func (c *Client) AddSubjectNote(id, text string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).AddNote(text)
+}
+
+func (c *Client) AddSubjectTag(id, tag string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).AddTag(tag)
+}
+
+func (c *Client) DeleteSubjectTag(id, tag string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).DeleteTag(tag)
+}
+
+func (c *Client) RenameSubjectTag(id, tag, newTag string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).RenameTag(tag, newTag)
+}
+
+func (c *Client) ModifySubjectNote(id, noteId, text string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).ModifyNote(noteId, text)
+}
+
+func (c *Client) DeleteSubjectNote(id, noteId string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).DeleteNote(noteId)
+}
+
+// ModifySubject changes a subject, and the copy held by each of its sessions. Moving a subject to another project moves its sessions.
+func (c *Client) ModifySubject(id string, subject *Subject) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).Modify(subject)
+}
+
+// ModifySubjectIfUnchanged changes a subject, as ModifySubject does, unless it was modified since the given time. See Container.ModifyIfUnchanged.
+func (c *Client) ModifySubjectIfUnchanged(id string, modified *time.Time, subject *Subject) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).ModifyIfUnchanged(modified, subject)
+}
+
+// UpdateSubject reads a subject, passes it to merge, and makes the changes merge returns, reading and merging again
+// if someone else modified the subject in between. See Container.Update.
+func (c *Client) UpdateSubject(id string, merge func(subject *Subject) (*Subject, error), options *UpdateOptions) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).Update(func() interface{} { return &Subject{} }, func(current interface{}) (interface{}, error) {
+		return merge(current.(*Subject))
+	}, options)
+}
+
+func (c *Client) SetSubjectInfo(id string, set map[string]interface{}) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).SetInfo(set)
+}
+
+func (c *Client) ReplaceSubjectInfo(id string, replace map[string]interface{}) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).ReplaceInfo(replace)
+}
+
+func (c *Client) DeleteSubjectInfoFields(id string, keys []string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).DeleteInfoFields(keys)
+}
+
+// SyncSubjectInfo changes a subject's info to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncSubjectInfo(id string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(SubjectContainer, id).SyncInfo(desired)
+}
+
+// DeleteSubject removes a subject along with its sessions.
+func (c *Client) DeleteSubject(id string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).Delete()
+}
+
+func (c *Client) UploadToSubject(id string, files ...*UploadSource) (chan int64, chan error) {
+	return c.Container(SubjectContainer, id).Upload(files...)
+}
+
+func (c *Client) ModifySubjectFile(id string, filename string, attributes *FileFields) (*http.Response, *ModifiedAndJobsResponse, error) {
+	return c.Container(SubjectContainer, id).ModifyFile(filename, attributes)
+}
+
+func (c *Client) DeleteSubjectFile(id string, filename string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).DeleteFile(filename)
+}
+
+func (c *Client) SetSubjectFileInfo(id string, filename string, set map[string]interface{}) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).SetFileInfo(filename, set)
+}
+
+func (c *Client) ReplaceSubjectFileInfo(id string, filename string, replace map[string]interface{}) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).ReplaceFileInfo(filename, replace)
+}
+
+func (c *Client) DeleteSubjectFileInfoFields(id string, filename string, keys []string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).DeleteFileInfoFields(filename, keys)
+}
+
+// SyncSubjectFileInfo changes the info of a subject's file to desired, sending only the difference. See Container.SyncInfo.
+func (c *Client) SyncSubjectFileInfo(id string, filename string, desired map[string]interface{}) (*InfoUpdate, *http.Response, error) {
+	return c.Container(SubjectContainer, id).SyncFileInfo(filename, desired)
+}
+
+func (c *Client) AddSubjectFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).AddFileTag(filename, tag)
+}
+
+func (c *Client) DeleteSubjectFileTag(id string, filename string, tag string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).DeleteFileTag(filename, tag)
+}
+
+func (c *Client) RenameSubjectFileTag(id string, filename string, tag string, newTag string) (*http.Response, error) {
+	return c.Container(SubjectContainer, id).RenameFileTag(filename, tag, newTag)
+}
+
+func (c *Client) DownloadFromSubject(id string, filename string, destination *DownloadSource) (chan int64, chan error) {
+	return c.Container(SubjectContainer, id).Download(filename, destination)
+}
+
+func (c *Client) GetSubjectDownloadUrl(id string, filename string) (string, *http.Response, error) {
+	return c.Container(SubjectContainer, id).DownloadUrl(filename)
+}
+
+func (c *Client) UploadFileToSubject(id string, path string) error {
+	return c.Container(SubjectContainer, id).UploadFile(path)
+}
+
+func (c *Client) DownloadFileFromSubject(id, name string, path string) error {
+	return c.Container(SubjectContainer, id).DownloadFile(name, path)
+}
@@ -1,7 +1,14 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
 
 	"github.com/dghubble/sling"
 )
@@ -14,6 +21,12 @@ const SdkDebugKey = "SdkDebug"
 type Client struct {
 	sling.Doer
 	*sling.Sling
+
+	// Context for all requests, if any. See WithContext.
+	ctx context.Context
+
+	// Server info, once fetched, shared with copies of the client. See GetServerInfo.
+	server *serverInfoCache
 }
 
 type ApiKeyClientOption func(*ApiKeyClientOptions)
@@ -21,6 +34,22 @@ type ApiKeyClientOptions struct {
 	// Skip SSL verification
 	InsecureSkipVerify bool
 
+	// Certificate authorities to verify the server against, instead of the system's, if any
+	RootCAs *x509.CertPool
+
+	// Certificates to present to the server, for mutual TLS
+	Certificates []tls.Certificate
+
+	// Selects a proxy for each request; nil connects directly
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// Connection timeouts; zero values do not time out
+	Timeouts Timeouts
+
+	// Idle connections to keep open, in total and for each host
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+
 	// Use plaintext (HTTP) transport
 	InsecureUsePlaintext bool
 
@@ -29,12 +58,56 @@ type ApiKeyClientOptions struct {
 
 	// A writer to send debug request bodies to, if any
 	DebugWriter io.Writer
+
+	// Hooks to call for each request, if any
+	Hooks []Hook
+
+	// File to record requests to, or replay them from, if any
+	RecordingPath string
+	RecordingMode RecordingMode
+
+	// Policy for retrying failed requests, if any
+	Retry *RetryPolicy
+
+	// Limits on requests, and on file transfers if set separately
+	RequestLimits  *Limits
+	TransferLimits *Limits
+
+	// Source of request credentials, replacing those of the API key, if any
+	TokenSource TokenSource
+
+	// Policy for caching responses, if any
+	Cache *CachePolicy
+}
+
+// Timeouts limit how long a client waits on its connections.
+type Timeouts struct {
+	// Dial limits connecting to the server, and TLSHandshake securing the connection.
+	Dial         time.Duration
+	TLSHandshake time.Duration
+
+	// ResponseHeader limits the wait for a response once a request has been sent.
+	// Large uploads may need a generous value, as the server responds once a file has been stored.
+	ResponseHeader time.Duration
+
+	// Idle limits how long an unused connection is kept open.
+	Idle time.Duration
+}
+
+// DefaultTimeouts match those of http.DefaultTransport.
+var DefaultTimeouts = Timeouts{
+	Dial:         30 * time.Second,
+	TLSHandshake: 10 * time.Second,
+	Idle:         90 * time.Second,
 }
 
 var DefaultApiKeyClientOptions = ApiKeyClientOptions{
 	InsecureSkipVerify:   false,
 	InsecureUsePlaintext: false,
 	EnableRoot:           false,
+	Timeouts:             DefaultTimeouts,
+	MaxIdleConns:         100,
+	MaxIdleConnsPerHost:  http.DefaultMaxIdleConnsPerHost,
 }
 
 // Specify that the ApiKeyClient should not verify SSL connections.
@@ -48,8 +121,130 @@ var InsecureUsePlaintext ApiKeyClientOption
 // Specify that the ApiKeyClient should operate in Root (Manage Site) mode.
 var EnableRoot ApiKeyClientOption
 
-// Specify that the ApiKeyClient should log all request bodies to the specified Writer.
-// See DebugTransport for details.
+// Specify that the ApiKeyClient should retry transient failures using DefaultRetryPolicy.
+var EnableRetry ApiKeyClientOption
+
+// Specify that the ApiKeyClient should cache responses in memory using DefaultCachePolicy.
+var EnableCache ApiKeyClientOption
+
+// Specify that the ApiKeyClient should select proxies by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+var ProxyFromEnvironment ApiKeyClientOption
+
+// Specify that the ApiKeyClient should verify the server against the given certificate authorities.
+// Prefer this to InsecureNoSSLVerification for sites with self-signed certificates. See LoadCertPool.
+func TrustRootCAs(pool *x509.CertPool) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.RootCAs = pool
+	}
+}
+
+// Specify that the ApiKeyClient should present a certificate to the server, for mutual TLS.
+// See tls.LoadX509KeyPair.
+func ClientCertificate(cert tls.Certificate) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.Certificates = append(o.Certificates[:len(o.Certificates):len(o.Certificates)], cert)
+	}
+}
+
+// Specify how the ApiKeyClient should select a proxy for each request, such as with http.ProxyURL.
+// By default, and when passed nil, the client connects directly; see ProxyFromEnvironment.
+func UseProxy(proxy func(*http.Request) (*url.URL, error)) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.Proxy = proxy
+	}
+}
+
+// Specify how long the ApiKeyClient should wait on its connections.
+// Defaults to DefaultTimeouts.
+func SetTimeouts(timeouts Timeouts) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.Timeouts = timeouts
+	}
+}
+
+// Specify how many idle connections the ApiKeyClient should keep open, in total and for each host.
+// Raise these when making many concurrent requests, so that connections are reused rather than reopened.
+func ConnectionPool(maxIdle, maxIdlePerHost int) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.MaxIdleConns = maxIdle
+		o.MaxIdleConnsPerHost = maxIdlePerHost
+	}
+}
+
+// Specify that the ApiKeyClient should retry transient failures using the given policy.
+// See RetryTransport for details.
+func RetryRequests(policy RetryPolicy) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.Retry = &policy
+	}
+}
+
+// Specify that the ApiKeyClient should cache responses using the given policy.
+// See CacheTransport for details.
+func CacheResponses(policy CachePolicy) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.Cache = &policy
+	}
+}
+
+// Specify that the ApiKeyClient should limit the rate and concurrency of its requests.
+// Unless LimitTransfers is also used, uploads and downloads count towards these limits.
+// See LimitTransport for details.
+func LimitRequests(limits Limits) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.RequestLimits = &limits
+	}
+}
+
+// Specify that the ApiKeyClient should limit uploads and downloads separately from other requests.
+// See LimitTransport for details.
+func LimitTransfers(limits Limits) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.TransferLimits = &limits
+	}
+}
+
+// Specify that the ApiKeyClient should authenticate with tokens from the given source, instead of its API key.
+// See AuthTransport for details.
+func Authenticate(source TokenSource) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.TokenSource = source
+	}
+}
+
+// Specify that the ApiKeyClient should call the given hooks for each request, in order.
+// May be used more than once. See Hook for details.
+func AddHooks(hooks ...Hook) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.Hooks = append(o.Hooks[:len(o.Hooks):len(o.Hooks)], hooks...)
+	}
+}
+
+// Specify that the ApiKeyClient should record its requests to a file, or replay them from it.
+// See ReplayTransport for details.
+func RecordReplay(path string, mode RecordingMode) ApiKeyClientOption {
+	return func(o *ApiKeyClientOptions) {
+		o.RecordingPath = path
+		o.RecordingMode = mode
+	}
+}
+
+// Specify that the ApiKeyClient should record or replay as the SdkReplay and SdkReplayFile environment variables say.
+// If SdkReplay is not set, the option does nothing. NewClientFromEnv applies it; clients made otherwise only record or replay if given it.
+func RecordReplayFromEnv() ApiKeyClientOption {
+	mode, replay := os.LookupEnv(SdkReplayKey)
+	if !replay {
+		return func(o *ApiKeyClientOptions) {}
+	}
+	path, pathSet := os.LookupEnv(SdkReplayFileKey)
+	if !pathSet {
+		path = DefaultRecordingPath
+	}
+	return RecordReplay(path, RecordingMode(mode))
+}
+
+// Specify that the ApiKeyClient should log all requests and responses to the specified Writer.
+// See DebugHook for details.
 func DebugLogRequests(w io.Writer) ApiKeyClientOption {
 	return func(o *ApiKeyClientOptions) {
 		o.DebugWriter = w
@@ -68,4 +263,18 @@ func init() {
 	EnableRoot = func(o *ApiKeyClientOptions) {
 		o.EnableRoot = true
 	}
+
+	EnableRetry = func(o *ApiKeyClientOptions) {
+		policy := DefaultRetryPolicy
+		o.Retry = &policy
+	}
+
+	ProxyFromEnvironment = func(o *ApiKeyClientOptions) {
+		o.Proxy = http.ProxyFromEnvironment
+	}
+
+	EnableCache = func(o *ApiKeyClientOptions) {
+		policy := DefaultCachePolicy
+		o.Cache = &policy
+	}
 }
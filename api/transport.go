@@ -1,12 +1,18 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/dghubble/sling"
 )
@@ -18,6 +24,37 @@ type RootModeQueryStruct struct {
 // NewApiKeyClient creates a Client with the given API key and options.
 // Passing a key with an invalid format will panic.
 func NewApiKeyClient(apiKey string, options ...ApiKeyClientOption) *Client {
+	host, port, _, err := ParseApiKey(apiKey)
+	if err != nil {
+		panic(err)
+	}
+
+	// Cannot fail once the key has parsed
+	source, _ := ApiKeyTokenSource(apiKey)
+
+	return newClient(host, port, source, options)
+}
+
+// NewTokenClient creates a Client for the given host, authenticating with tokens from source.
+// The host is "hostname" or "hostname:port"; the port defaults to 443.
+// Passing a host with an invalid port will panic.
+func NewTokenClient(host string, source TokenSource, options ...ApiKeyClientOption) *Client {
+	port := 443
+
+	splits := strings.Split(host, ":")
+	if len(splits) > 1 {
+		var err error
+		port, err = strconv.Atoi(splits[len(splits)-1])
+		if err != nil {
+			panic(err)
+		}
+		host = strings.Join(splits[:len(splits)-1], ":")
+	}
+
+	return newClient(host, port, source, options)
+}
+
+func newClient(host string, port int, source TokenSource, options []ApiKeyClientOption) *Client {
 
 	// If the debug environment variable is set, add the debug transport.
 	// This is added to the beginning of the options array, in case it is overridden later.
@@ -32,31 +69,91 @@ func NewApiKeyClient(apiKey string, options ...ApiKeyClientOption) *Client {
 		x(&config)
 	}
 
-	host, port, key, err := ParseApiKey(apiKey)
-	if err != nil {
-		panic(err)
+	if config.TokenSource != nil {
+		source = config.TokenSource
 	}
 
 	// Load TLS configuration into a transport
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: config.InsecureSkipVerify,
+			RootCAs:            config.RootCAs,
+			Certificates:       config.Certificates,
 		},
+		Proxy: config.Proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   config.Timeouts.Dial,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   config.Timeouts.TLSHandshake,
+		ResponseHeaderTimeout: config.Timeouts.ResponseHeader,
+		IdleConnTimeout:       config.Timeouts.Idle,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		ExpectContinueTimeout: 1 * time.Second,
 	}
 
-	var hc *http.Client
+	var rt http.RoundTripper = tr
 
-	// Create an HTTP client, adding the debug transport if specified
-	if config.DebugWriter == nil {
-		hc = &http.Client{
-			Transport: tr,
+	// Add the replay transport if specified, closest to the network so that everything above it runs as usual
+	if config.RecordingPath != "" {
+		rt = &ReplayTransport{
+			Transport: rt,
+			Path:      config.RecordingPath,
+			Mode:      config.RecordingMode,
 		}
-	} else {
-		debugT := &DebugTransport{
-			Transport: tr,
-			Writer:    config.DebugWriter,
+	}
+
+	// Add the hook transport if any hooks are specified.
+	// The debug hook goes last, so that it prints requests as sent.
+	hooks := config.Hooks
+	if config.DebugWriter != nil {
+		hooks = append(hooks[:len(hooks):len(hooks)], &DebugHook{Writer: config.DebugWriter})
+	}
+	if len(hooks) > 0 {
+		rt = &HookTransport{
+			Transport: rt,
+			Hooks:     hooks,
 		}
-		hc = debugT.Client()
+	}
+
+	// Add the limit transport if specified. Each attempt counts towards the limits.
+	if config.RequestLimits != nil || config.TransferLimits != nil {
+		limit := &LimitTransport{Transport: rt}
+		if config.RequestLimits != nil {
+			limit.Requests = NewLimiter(*config.RequestLimits)
+		}
+		if config.TransferLimits != nil {
+			limit.Transfers = NewLimiter(*config.TransferLimits)
+		}
+		rt = limit
+	}
+
+	// Add the retry transport if specified. Each attempt is hooked separately.
+	if config.Retry != nil && config.Retry.MaxAttempts > 1 {
+		rt = &RetryTransport{
+			Transport: rt,
+			Policy:    *config.Retry,
+		}
+	}
+
+	// Add the cache transport if specified. Cached responses skip retries, limits and hooks entirely.
+	if config.Cache != nil {
+		rt = &CacheTransport{
+			Transport: rt,
+			Policy:    *config.Cache,
+		}
+	}
+
+	// Authenticate outermost, as the cache keys responses by credential. The token is fetched once per request,
+	// so retries reuse it; a token the server rejects with 401 is refreshed by the auth transport itself.
+	rt = &AuthTransport{
+		Transport: rt,
+		Source:    source,
+	}
+
+	hc := &http.Client{
+		Transport: rt,
 	}
 
 	protocol := "https"
@@ -67,7 +164,6 @@ func NewApiKeyClient(apiKey string, options ...ApiKeyClientOption) *Client {
 	// Create a sling client, which is used for most server interactions
 	sc := sling.New().
 		Base(protocol+"://"+host+":"+strconv.Itoa(port)+"/").
-		Set("Authorization", "scitran-user "+key).
 		Set("User-Agent", "Flywheel SDK").
 		Path("api/").
 		Client(hc)
@@ -79,12 +175,14 @@ func NewApiKeyClient(apiKey string, options ...ApiKeyClientOption) *Client {
 	}
 
 	return &Client{
-		Doer:  hc,
-		Sling: sc,
+		Doer:   hc,
+		Sling:  sc,
+		server: &serverInfoCache{},
 	}
 }
 
 // DebugTransport prints its raw requests and responses to a writer, including bodies.
+// See DebugHook for details.
 type DebugTransport struct {
 
 	// All requests made with this transport will be written to Writer.
@@ -96,76 +194,199 @@ type DebugTransport struct {
 	Transport http.RoundTripper
 }
 
+// RoundTrip implements the RoundTripper interface.
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hooks := &HookTransport{
+		Hooks:     []Hook{&DebugHook{Writer: t.Writer}},
+		Transport: t.Transport,
+	}
+	return hooks.RoundTrip(req)
+}
+
+// Client returns an *http.Client which uses the DebugTransport.
+func (t *DebugTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
 var header = []byte("\n--------------------\n---- BEGIN HTTP ----\n--------------------\n")
 var middle = []byte("\n--------------------\n-- BEGIN RESPONSE --\n--------------------\n")
 var footer = []byte("\n--------------------\n----- END HTTP -----\n--------------------\n")
 
-// RoundTrip implements the RoundTripper interface.
-func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+// DebugBodyLimit is the most of each body that DebugHook prints.
+const DebugBodyLimit = 4096
+
+// Credentials that DebugHook does not print.
+var debugRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+var debugRedactedParams = []string{"ticket"}
 
-	// Effort in this made in this function, to:
+// DebugHook prints an HTTP/1.1 representation of each request and response to a writer.
+//
+// Credentials are redacted. Bodies are truncated to DebugBodyLimit, and binary bodies are omitted.
+type DebugHook struct {
+
+	// All requests will be written to Writer.
+	// It will default to os.Stderr if nil.
+	Writer io.Writer
+}
+
+type debugContextKey struct{}
+
+// Request implements the Hook interface.
+func (h *DebugHook) Request(req *http.Request) *http.Request {
+	// Effort in this made in this hook, to:
 	//
 	// 1) Write debug output only once, to be concurrency-friendly
 	// 2) Write as much debug output as possible when errors occur
-	// 3) Expose the most relevant error: return an HTTP error if there was both an HTTP and a debug error.
+	// 3) Never consume more of a body than is printed, so that uploads and downloads still stream
 	//
-	// For that reason, there's a bit of "if err is nil okay now we can use err" logic.
+	// For that reason, the request is dumped here, but written with its response.
 
-	msg, err := httputil.DumpRequestOut(req, true)
+	msg, err := httputil.DumpRequestOut(redactRequest(req), false)
 	if err != nil {
-		Println("ergh")
-		return nil, err
+		msg = []byte("Request dump failed: " + err.Error())
 	}
+	msg = append(append([]byte{}, header...), msg...)
 
-	msg = append(header, msg...)
-	msg = append(msg, middle...)
+	if req.Body != nil {
+		var preview []byte
+		preview, req = peekRequestBody(req)
+		msg = append(msg, preview...)
+	}
 
-	var resp *http.Response
+	msg = append(msg, middle...)
+	return req.WithContext(context.WithValue(req.Context(), debugContextKey{}, msg))
+}
 
-	if t.Transport != nil {
-		resp, err = t.Transport.RoundTrip(req)
-	} else {
-		resp, err = http.DefaultTransport.RoundTrip(req)
-	}
+// Response implements the Hook interface.
+func (h *DebugHook) Response(req *http.Request, resp *http.Response, err error) {
+	msg, _ := req.Context().Value(debugContextKey{}).([]byte)
 
-	// Stop if request failed
 	if err != nil {
-		str := []byte("Request failed: " + err.Error())
-		msg = append(msg, str...)
+		msg = append(msg, []byte("Request failed: "+err.Error())...)
+
+	} else if resp != nil {
+		saved := resp.Header
+		resp.Header = redactHeader(resp.Header)
+		dump, dumpErr := httputil.DumpResponse(resp, false)
+		resp.Header = saved
+
+		if dumpErr != nil {
+			msg = append(msg, []byte("Response dump failed: "+dumpErr.Error())...)
+		} else {
+			msg = append(msg, dump...)
+		}
 
-	} else {
-		// If there is a valid response, dump that too
-		if resp != nil {
-			var msg2 []byte
-			msg2, err = httputil.DumpResponse(resp, true)
-
-			if err != nil {
-				str := []byte("Response dump failed: " + err.Error())
-				msg = append(msg, str...)
-			} else {
-				msg = append(msg, msg2...)
-			}
+		if resp.Body != nil {
+			var preview []byte
+			preview, resp.Body = peekBody(resp.Body)
+			msg = append(msg, preview...)
 		}
 	}
 
 	msg = append(msg, footer...)
 
-	var err2 error
-	if t.Writer == nil {
-		_, err2 = os.Stderr.Write(msg)
+	if h.Writer == nil {
+		os.Stderr.Write(msg)
 	} else {
-		_, err2 = t.Writer.Write(msg)
+		h.Writer.Write(msg)
 	}
+}
 
-	// Don't allow debug write error to overwrite original error
-	if err == nil {
-		err = err2
+// Done implements the Hook interface.
+func (h *DebugHook) Done(stats *RequestStats) {}
+
+// redactRequest returns a copy of a request without credentials, for printing.
+func redactRequest(req *http.Request) *http.Request {
+	redacted := new(http.Request)
+	*redacted = *req
+	redacted.Header = redactHeader(req.Header)
+
+	query := req.URL.Query()
+	changed := false
+	for _, param := range debugRedactedParams {
+		if _, ok := query[param]; ok {
+			query.Set(param, "[redacted]")
+			changed = true
+		}
+	}
+	if changed {
+		u := *req.URL
+		u.RawQuery = query.Encode()
+		redacted.URL = &u
 	}
 
-	return resp, err
+	return redacted
 }
 
-// Client returns an *http.Client which uses the DebugTransport.
-func (t *DebugTransport) Client() *http.Client {
-	return &http.Client{Transport: t}
+func redactHeader(h http.Header) http.Header {
+	redacted := make(http.Header, len(h))
+	for key, values := range h {
+		redacted[key] = values
+	}
+	for _, key := range debugRedactedHeaders {
+		if _, ok := redacted[key]; ok {
+			redacted.Set(key, "[redacted]")
+		}
+	}
+	return redacted
+}
+
+// peekRequestBody returns a preview of a request body, and a copy of the request that will still send the whole body.
+func peekRequestBody(req *http.Request) ([]byte, *http.Request) {
+	peeked := new(http.Request)
+	*peeked = *req
+
+	var preview []byte
+	preview, peeked.Body = peekBody(req.Body)
+	return preview, peeked
+}
+
+// peekBody returns a printable preview of a body, and a replacement body that reads the whole content.
+func peekBody(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	peek := make([]byte, DebugBodyLimit+1)
+	n, err := io.ReadFull(body, peek)
+	peek = peek[:n]
+
+	replacement := &struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), body), body}
+
+	// Pass read errors on to whoever reads the body
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		replacement.Reader = io.MultiReader(bytes.NewReader(peek), &errReader{err})
+	}
+
+	if n == 0 {
+		return nil, replacement
+	}
+
+	text := peek
+	if n > DebugBodyLimit {
+		text = peek[:DebugBodyLimit]
+
+		// Truncation may split a character
+		for x := 1; x < utf8.UTFMax && !utf8.Valid(text); x++ {
+			text = text[:len(text)-1]
+		}
+	}
+
+	if !utf8.Valid(text) || bytes.IndexByte(text, 0) >= 0 {
+		return []byte("[binary body omitted]"), replacement
+	}
+
+	preview := append([]byte{}, text...)
+	if n > DebugBodyLimit {
+		preview = append(preview, []byte("\n[body truncated]")...)
+	}
+	return preview, replacement
+}
+
+// errReader fails every read with an error.
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	return 0, r.err
 }
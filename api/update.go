@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// ConflictError is the error of a conditional change to a container that someone else modified since it was read.
+// IsConflict is true for it.
+type ConflictError struct {
+	Container *ContainerReference
+
+	// Expected is the modified time the change was based on, and Actual the container's modified time when the change was tried, if known.
+	Expected *time.Time
+	Actual   *time.Time
+}
+
+// Error implements the error interface.
+func (e *ConflictError) Error() string {
+	message := "The " + e.Container.Type + " " + e.Container.Id + " was modified"
+	if e.Actual != nil {
+		message += " at " + e.Actual.Format(time.RFC3339Nano)
+	}
+	return message + ", not at " + e.Expected.Format(time.RFC3339Nano) + " as expected"
+}
+
+// Unwrap returns a 409 *Error, as a server reports a conflicting modification, so that a ConflictError is classified as one.
+func (e *ConflictError) Unwrap() error {
+	return &Error{StatusCode: http.StatusConflict, Message: e.Error()}
+}
+
+// UpdateOptions control how Update retries.
+type UpdateOptions struct {
+	// MaxAttempts is how many times the container may be read and merged, including the first. Zero means three.
+	MaxAttempts int
+}
+
+// ModifyIfUnchanged changes the container's fields, as Modify does, but only if the container was last modified at the given time,
+// normally the Modified of the copy that the changes are based on. Otherwise it changes nothing, and fails with a *ConflictError.
+//
+// The container is read, bypassing any cache, to check its modified time, and the change is sent with an If-Match header
+// holding the ETag of what was read, so that the server refuses it if someone else changed the container in between.
+// That guarantee depends on the server honouring If-Match. If the read has no ETag, the change is sent unconditionally,
+// and one made by someone else between the check and the change is lost, so the check is only best-effort.
+func (c *Container) ModifyIfUnchanged(modified *time.Time, changes interface{}) (*http.Response, error) {
+	if modified == nil {
+		return nil, errors.New("A conditional modification needs the modified time it is based on")
+	}
+
+	var doc struct {
+		Modified *time.Time `json:"modified"`
+	}
+	resp, err := c.getUncached(&doc)
+	if err != nil {
+		return resp, err
+	}
+	return c.modifyIfMatch(resp, modified, doc.Modified, changes)
+}
+
+// modifyIfMatch makes the change of ModifyIfUnchanged, given the response of the read that found the container
+// last modified at actual.
+func (c *Container) modifyIfMatch(read *http.Response, expected, actual *time.Time, changes interface{}) (*http.Response, error) {
+	if actual == nil || !actual.Equal(*expected) {
+		return read, &ConflictError{Container: c.Reference(), Expected: expected, Actual: actual}
+	}
+
+	s := c.client.New().Put(c.Path()).BodyJSON(changes)
+	if etag := read.Header.Get("ETag"); etag != "" {
+		s = s.Set("If-Match", etag)
+	}
+	resp, err := c.modify(s)
+	if StatusCode(err) == http.StatusPreconditionFailed {
+		// Read again only to report when it was changed. If that fails, Actual is left nil, as the conflict is what matters.
+		var current struct {
+			Modified *time.Time `json:"modified"`
+		}
+		c.getUncached(&current)
+		return resp, &ConflictError{Container: c.Reference(), Expected: expected, Actual: current.Modified}
+	}
+	return resp, err
+}
+
+// Update makes a read-modify-write change to the container. It reads the container into a new value from create,
+// passes that to merge, and makes the changes merge returns with ModifyIfUnchanged. If merge returns nil, nothing is changed.
+// Changes should hold only the fields to change, as for Modify, rather than the whole of what was read.
+//
+// If someone else modified the container in between, Update reads it and calls merge again, up to UpdateOptions.MaxAttempts times,
+// after which the *ConflictError is returned. An error from merge stops the update, and is returned as it is.
+//
+// The per-type versions, such as UpdateSession, create and pass values of their own type.
+func (c *Container) Update(create func() interface{}, merge func(current interface{}) (interface{}, error), options *UpdateOptions) (*http.Response, error) {
+	attempts := 3
+	if options != nil && options.MaxAttempts > 0 {
+		attempts = options.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		var raw json.RawMessage
+		resp, err := c.getUncached(&raw)
+		if err != nil {
+			return resp, err
+		}
+		var doc struct {
+			Modified *time.Time `json:"modified"`
+		}
+		err = json.Unmarshal(raw, &doc)
+		if err != nil {
+			return resp, err
+		}
+		current := create()
+		err = json.Unmarshal(raw, current)
+		if err != nil {
+			return resp, err
+		}
+
+		changes, err := merge(current)
+		if err != nil || isNilValue(changes) {
+			return resp, err
+		}
+
+		// With an ETag, what was read is what the change is checked against. Without one, ModifyIfUnchanged reads
+		// the container again, so that at least a change made while merge ran is caught.
+		if doc.Modified != nil && resp.Header.Get("ETag") != "" {
+			resp, err = c.modifyIfMatch(resp, doc.Modified, doc.Modified, changes)
+		} else {
+			resp, err = c.ModifyIfUnchanged(doc.Modified, changes)
+		}
+		if _, isConflict := err.(*ConflictError); !isConflict || attempt >= attempts {
+			return resp, err
+		}
+	}
+}
+
+// isNilValue reports whether v is nil, or a nil pointer, map or slice.
+func isNilValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"errors"
 	"io"
-	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -44,10 +43,7 @@ func CreateUploadSourceFromFilenames(filenames ...string) []*UploadSource {
 
 // Write a set of UploadSources to a multipart writer, reporting progress to a ProgressReader.
 func writeUploadSources(writer *multipart.Writer, reader *ProgressReader, metadata []byte, files []*UploadSource) error {
-	defer func() {
-		writer.Close()
-		reader.Close()
-	}()
+	defer writer.Close()
 
 	// Add metadata, if any
 	if len(metadata) > 0 {
@@ -70,20 +66,21 @@ func writeUploadSources(writer *multipart.Writer, reader *ProgressReader, metada
 			file.Name = filepath.Base(file.Path)
 		}
 
-		// Open a file descriptor if this UploadSource was not already an open reader
+		// Open a file descriptor if this UploadSource was not already an open reader.
+		// The file is opened again for each attempt, so that the upload can be retried.
+		fileReader := io.Reader(file.Reader)
 		if file.Reader == nil {
-			fileReader, err := os.Open(file.Path)
-			file.Reader = fileReader
-
+			osFile, err := os.Open(file.Path)
 			if err != nil {
 				return err
 			}
+			defer osFile.Close()
+			fileReader = osFile
 		}
-		defer file.Reader.Close()
 
 		// Report progress of the uploads, not of the encoded stream.
 		// Upload progress of metadata and preamble will not be reported.
-		reader.SetReader(file.Reader)
+		reader.SetReader(fileReader)
 
 		// Create a form name for this file.
 		// If there's only one file, don't add an index.
@@ -104,23 +101,181 @@ func writeUploadSources(writer *multipart.Writer, reader *ProgressReader, metada
 		if err != nil && err != io.EOF {
 			return err
 		}
-
-		file.Reader.Close()
 	}
 
 	return nil
 }
 
-// Fire an upload with a given url, reader, and content type.
-func (c *Client) sendUploadRequest(url string, reader io.ReadCloser, contentType string) (*http.Response, error) {
+// uploadBody encodes a set of UploadSources as a multipart form, streamed through a pipe.
+//
+// If every source can be read again - either it has a Path, or its Reader is an io.Seeker - the body is replayable,
+// and open can be called again to retry the upload.
+type uploadBody struct {
+	metadata []byte
+	files    []*UploadSource
+	progress *ProgressReader
+	boundary string
+
+	// Starting position of each seekable reader, or -1 if the source cannot be replayed.
+	offsets []int64
+
+	// Protects the fields below
+	mutex sync.Mutex
+
+	// The reading end of the most recent attempt, and the local error it encountered, if any.
+	reader   *io.PipeReader
+	err      error
+	attempts int
+
+	wg sync.WaitGroup
+}
+
+func newUploadBody(metadata []byte, files []*UploadSource, progress *ProgressReader) *uploadBody {
+	b := &uploadBody{
+		metadata: metadata,
+		files:    files,
+		progress: progress,
+		boundary: multipart.NewWriter(nil).Boundary(),
+		offsets:  make([]int64, len(files)),
+	}
+
+	for i, file := range files {
+		b.offsets[i] = -1
+
+		if file.Reader == nil {
+			b.offsets[i] = 0
+		} else if seeker, ok := file.Reader.(io.Seeker); ok {
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err == nil {
+				b.offsets[i] = offset
+			}
+		}
+	}
+
+	return b
+}
+
+// replayable reports whether every source can be read again from the start.
+func (b *uploadBody) replayable() bool {
+	for _, offset := range b.offsets {
+		if offset < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// contentType returns the multipart content type, which is the same for every attempt.
+func (b *uploadBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// stop closes the most recent attempt and waits for its encoding to finish.
+func (b *uploadBody) stop() {
+	b.mutex.Lock()
+	reader := b.reader
+	b.mutex.Unlock()
+
+	if reader != nil {
+		reader.Close()
+	}
+	b.wg.Wait()
+}
+
+// open begins encoding the body from the start, and returns a reader for the result.
+// It is suitable for use as http.Request.GetBody.
+func (b *uploadBody) open() (io.ReadCloser, error) {
+	b.stop()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// A local failure will not be fixed by sending the request again
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if b.attempts > 0 {
+		if !b.replayable() {
+			return nil, errors.New("Upload sources cannot be read again")
+		}
+
+		for i, file := range b.files {
+			if file.Reader == nil {
+				continue
+			}
+			_, err := file.Reader.(io.Seeker).Seek(b.offsets[i], io.SeekStart)
+			if err != nil {
+				return nil, err
+			}
+		}
+		b.progress.reset()
+	}
+	b.attempts++
+
+	reader, writer := io.Pipe()
+	multipartWriter := multipart.NewWriter(writer)
+	multipartWriter.SetBoundary(b.boundary)
+	b.reader = reader
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		err := writeUploadSources(multipartWriter, b.progress, b.metadata, b.files)
+
+		// If the request stopped reading, the response will explain why
+		if err == io.ErrClosedPipe {
+			err = nil
+		}
+
+		b.mutex.Lock()
+		b.err = err
+		b.mutex.Unlock()
+
+		writer.CloseWithError(err)
+	}()
+
+	return reader, nil
+}
+
+// close finishes the final attempt, releases the sources, and returns any local error encountered.
+func (b *uploadBody) close() error {
+	b.stop()
+
+	for _, file := range b.files {
+		if file.Reader != nil {
+			file.Reader.Close()
+		}
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.err
+}
+
+// Fire an upload with a given url and body.
+func (c *Client) sendUploadRequest(url string, body *uploadBody) (*http.Response, error) {
+	reader, err := body.open()
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := c.New().Post(url).
 		Body(reader).
-		Set("Content-Type", contentType).
+		Set("Content-Type", body.contentType()).
 		Request()
 
 	if err != nil {
 		return nil, err
 	}
+	req = markTransfer(req.WithContext(c.Context()))
+
+	// Uploading the same files again is safe, so allow retries if the body can be recreated.
+	if body.replayable() {
+		req.GetBody = body.open
+		req = allowRetry(req)
+	}
 
 	resp, err := c.Doer.Do(req)
 	if err != nil {
@@ -128,10 +283,7 @@ func (c *Client) sendUploadRequest(url string, reader io.ReadCloser, contentType
 	}
 
 	if resp.StatusCode != 200 {
-		// Needs robust handling for body & raw nils
-		raw, _ := ioutil.ReadAll(resp.Body)
-
-		return resp, errors.New(string(raw))
+		return resp, errorFromResponse(resp)
 	}
 
 	return resp, err
@@ -142,49 +294,38 @@ func (c *Client) sendUploadRequest(url string, reader io.ReadCloser, contentType
 //
 // Depending on the URL, metadata may be required, or only one file may be allowed at a time.
 // It is generally a good idea to use a purpose-specific upload method.
+//
+// If the client retries requests, the upload is retried when every UploadSource has a Path or a seekable Reader.
+// Readers are closed once the upload is complete.
+//
+// If the client has a context, cancelling it stops the upload and reports the context's error.
 func (c *Client) Upload(url string, metadata []byte, progress chan<- int64, files []*UploadSource) chan error {
 
-	// Form data is written from one goroutine to another
-	reader, writer := io.Pipe()
-	multipartWriter := multipart.NewWriter(writer)
-	contentType := multipartWriter.FormDataContentType()
-
-	// Wrap the pipe in a ProgressReader.
+	// Form data is written from one goroutine to another, reporting progress as files are read.
 	progressReader := NewProgressReader(nil, progress)
-
-	// Shared memory for results, protected by a waitgroup. Simpler (but more dangerous) than channels.
-	var writeError error
-	var uploadError error
-	var response *http.Response
-	var wg sync.WaitGroup
-	wg.Add(2)
+	body := newUploadBody(metadata, files, progressReader)
 
 	// Report result back to caller
 	resultChan := make(chan error, 1)
 
-	// Stream multipart encoding
-	go func() {
-		writeError = writeUploadSources(multipartWriter, progressReader, metadata, files)
-		writer.Close()
-		wg.Done()
-	}()
-
 	// Send encoded body to server, await completion, report
 	go func() {
-		response, uploadError = c.sendUploadRequest(url, reader, contentType)
-		wg.Done()
-	}()
-
-	// Wait for both to complete, and report back
-	go func() {
-		wg.Wait()
+		response, uploadError := c.sendUploadRequest(url, body)
 		if response != nil && response.Body != nil {
 			response.Body.Close()
 		}
 
-		// Encoding & local-IO errors take precedence over network errors.
+		// Encoding is finished once the body is closed; the progress reader has no more work.
+		writeError := body.close()
+		progressReader.SetReader(nil)
+		progressReader.Close()
+
+		// Cancellation explains any other error.
+		// Otherwise, encoding & local-IO errors take precedence over network errors.
 		// Could combine the two if both are set. Eh.
-		if writeError != nil {
+		if ctxError := c.Context().Err(); ctxError != nil {
+			resultChan <- ctxError
+		} else if writeError != nil {
 			resultChan <- writeError
 		} else {
 			resultChan <- uploadError
@@ -35,14 +35,14 @@ func (c *Client) GetCurrentUser() (*User, *http.Response, error) {
 	var aerr *Error
 	var user *User
 	resp, err := c.New().Get("users/self").Receive(&user, &aerr)
-	return user, resp, Coalesce(err, aerr)
+	return user, resp, CoalesceResponse(resp, err, aerr)
 }
 
-func (c *Client) GetAllUsers() ([]*User, *http.Response, error) {
-	var aerr *Error
-	var users []*User
-	resp, err := c.New().Get("users").Receive(&users, &aerr)
-	return users, resp, Coalesce(err, aerr)
+func (c *Client) GetAllUsers(options ...*ListOptions) ([]*User, *http.Response, error) {
+	users := []*User{}
+	it := c.IterateUsers(options...)
+	err := it.collect(func(x interface{}) { users = append(users, x.(*User)) })
+	return users, it.lastResponse, err
 }
 
 func (c *Client) GetUser(id string) (*User, *http.Response, error) {
@@ -50,7 +50,7 @@ func (c *Client) GetUser(id string) (*User, *http.Response, error) {
 	var user *User
 
 	resp, err := c.New().Get("users/"+id).Receive(&user, &aerr)
-	return user, resp, Coalesce(err, aerr)
+	return user, resp, CoalesceResponse(resp, err, aerr)
 }
 
 // AddUser creates a user, and returns the created Id.
@@ -65,7 +65,7 @@ func (c *Client) AddUser(user *User) (string, *http.Response, error) {
 		result = response.Id
 	}
 
-	return result, resp, Coalesce(err, aerr)
+	return result, resp, CoalesceResponse(resp, err, aerr)
 }
 
 // ModifyUser will update an existing user.
@@ -82,7 +82,7 @@ func (c *Client) ModifyUser(id string, user *User) (*http.Response, error) {
 		return resp, errors.New("Modifying user " + id + " returned " + strconv.Itoa(response.ModifiedCount) + " instead of 1")
 	}
 
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
 
 // DeleteUser will delete a user. Returns an error if user was not found or the delete did not succeed.
@@ -98,5 +98,5 @@ func (c *Client) DeleteUser(id string) (*http.Response, error) {
 		return resp, errors.New("Deleting user " + id + " returned " + strconv.Itoa(response.DeletedCount) + " instead of 1")
 	}
 
-	return resp, Coalesce(err, aerr)
+	return resp, CoalesceResponse(resp, err, aerr)
 }
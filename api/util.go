@@ -2,26 +2,38 @@ package api
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strconv"
+	"net/http"
 )
 
 // Coalesce will extract an API error message into a golang error, if applicable.
+// The returned error is an *Error when the server reported a failure.
+//
+// Prefer CoalesceResponse, which can also describe the request that failed.
 func Coalesce(err error, aerr *Error) error {
+	return CoalesceResponse(nil, err, aerr)
+}
 
-	// This should eventually be replaced with something that takes the http response as well and looks at that. Expect200? Etc.
+// CoalesceResponse will extract an API error into a golang error, if applicable.
+// If resp is a failed response, the returned *Error carries its status code, method and URL.
+func CoalesceResponse(resp *http.Response, err error, aerr *Error) error {
+	failed := resp != nil && (resp.StatusCode < 200 || resp.StatusCode > 299)
 
-	if err != nil {
+	if err != nil && !failed {
 		return err
 	} else if aerr != nil {
-		if aerr.Message == "" {
-			aerr.Message = "Unknown server error"
+		if failed {
+			aerr.StatusCode = resp.StatusCode
 		}
-		aerr.Message = "(" + strconv.Itoa(aerr.StatusCode) + ") " + aerr.Message
-		return errors.New(aerr.Message)
+		aerr.setRequest(resp)
+		return aerr
+	} else if failed {
+		// The failure could not be decoded as an API error, such as an HTML page from a proxy.
+		aerr = &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		aerr.setRequest(resp)
+		return aerr
 	} else {
-		return nil
+		return err
 	}
 }
 
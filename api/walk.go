@@ -0,0 +1,326 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// SkipContainer is returned by a WalkFunc to skip the children of the current container.
+var SkipContainer = errors.New("skip this container")
+
+// StopWalk is returned by a WalkFunc to end a walk early. Walk then returns nil.
+var StopWalk = errors.New("stop walking")
+
+// WalkFunc is called by Walk for each container visited.
+// Returning SkipContainer skips the container's children, StopWalk ends the walk, and any other error ends it with that error.
+type WalkFunc func(node *WalkNode) error
+
+// WalkNode is a container reached by Walk.
+type WalkNode struct {
+	*Container
+
+	// Parent is the node Walk reached this one from, or nil for where it started.
+	Parent *WalkNode
+
+	// Depth is how far below where Walk started the container is. Walk starts at depth 0.
+	Depth int
+
+	// The container as listed, such as a *Session.
+	// Listings leave out files, notes, tags and info; set WalkOptions.Full to fetch each container in full.
+	value interface{}
+
+	// The collection a walk from a collection is within, so that only the collection's acquisitions are listed
+	collectionId string
+}
+
+// Group returns the node's group, or nil if the node is another type.
+func (n *WalkNode) Group() *Group { x, _ := n.value.(*Group); return x }
+
+// Project returns the node's project, or nil if the node is another type.
+func (n *WalkNode) Project() *Project { x, _ := n.value.(*Project); return x }
+
+// Subject returns the node's subject, or nil if the node is another type.
+func (n *WalkNode) Subject() *Subject { x, _ := n.value.(*Subject); return x }
+
+// Session returns the node's session, or nil if the node is another type.
+func (n *WalkNode) Session() *Session { x, _ := n.value.(*Session); return x }
+
+// Acquisition returns the node's acquisition, or nil if the node is another type.
+func (n *WalkNode) Acquisition() *Acquisition { x, _ := n.value.(*Acquisition); return x }
+
+// Collection returns the node's collection, or nil if the node is another type.
+func (n *WalkNode) Collection() *Collection { x, _ := n.value.(*Collection); return x }
+
+// WalkOptions control which containers Walk visits, and how.
+type WalkOptions struct {
+	// MaxDepth is how far below the start to go; 1 visits only its children. Zero means no limit.
+	MaxDepth int
+
+	// Include, if set, lists the only types passed to the WalkFunc. Other types are still walked through.
+	Include []ContainerType
+
+	// Exclude lists types that are neither visited nor walked through.
+	Exclude []ContainerType
+
+	// Filter holds list options for each type, so that the server leaves out containers that are not wanted.
+	// Containers that are left out are not walked through.
+	Filter map[ContainerType]*ListOptions
+
+	// Workers is how many containers are visited and listed at once. Zero means one.
+	// With more than one, the WalkFunc is called concurrently, and the order of visits is not fixed.
+	Workers int
+
+	// Full fetches each container in full, with its files, notes, tags and info, at the cost of a request for each.
+	Full bool
+}
+
+// Walk visits a container and everything below it: group, project, session, then acquisition.
+// Collections are walked through their sessions to the acquisitions they hold, and subjects through their sessions.
+// A nil start walks every group.
+//
+// With one worker, containers are visited depth-first, each before its children, in the order they are listed.
+// The first error from the WalkFunc or the server ends the walk and is returned.
+//
+//	err := client.Walk(client.Container(api.ProjectContainer, id), func(node *api.WalkNode) error {
+//		if node.Type == api.SessionContainer && len(node.Session().Tags) == 0 {
+//			return api.SkipContainer
+//		}
+//		...
+//		return nil
+//	}, &api.WalkOptions{Workers: 4, Full: true})
+func (c *Client) Walk(start *Container, fn WalkFunc, options *WalkOptions) error {
+	if options == nil {
+		options = &WalkOptions{}
+	}
+	w := &walker{client: c, fn: fn, options: options}
+	w.cond = sync.NewCond(&w.mutex)
+
+	if start == nil {
+		groups, err := w.list(&WalkNode{Depth: -1}, GroupContainer, "groups")
+		if err != nil {
+			return err
+		}
+		w.queue = groups
+	} else {
+		value := newContainerValue(start.Type)
+		if value == nil {
+			return errors.New("Cannot walk a container of type " + string(start.Type))
+		}
+		_, err := start.Get(value)
+		if err != nil {
+			return err
+		}
+		w.start = &WalkNode{Container: start, value: value}
+		w.queue = []*WalkNode{w.start}
+	}
+
+	workers := options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for x := 0; x < workers; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work()
+		}()
+	}
+	wg.Wait()
+
+	return w.err
+}
+
+// walker holds the state of a walk. Nodes wait on a stack, so that one worker walks depth-first.
+type walker struct {
+	client  *Client
+	fn      WalkFunc
+	options *WalkOptions
+
+	// Where the walk started, which has already been fetched
+	start *WalkNode
+
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	queue   []*WalkNode
+	active  int
+	stopped bool
+	err     error
+}
+
+func (w *walker) work() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for {
+		for len(w.queue) == 0 && w.active > 0 && !w.stopped {
+			w.cond.Wait()
+		}
+		if w.stopped || len(w.queue) == 0 {
+			w.cond.Broadcast()
+			return
+		}
+
+		node := w.queue[len(w.queue)-1]
+		w.queue = w.queue[:len(w.queue)-1]
+		w.active++
+		w.mutex.Unlock()
+
+		children, err := w.visit(node)
+
+		w.mutex.Lock()
+		w.active--
+		if err != nil {
+			w.stop(err)
+		} else if !w.stopped {
+			for x := len(children) - 1; x >= 0; x-- {
+				w.queue = append(w.queue, children[x])
+			}
+		}
+		w.cond.Broadcast()
+	}
+}
+
+// stop ends the walk with the first error. Callers hold the mutex.
+func (w *walker) stop(err error) {
+	if w.stopped {
+		return
+	}
+	w.stopped = true
+	if err != StopWalk {
+		w.err = err
+	}
+}
+
+// visit calls the WalkFunc for a node, then lists its children.
+func (w *walker) visit(node *WalkNode) ([]*WalkNode, error) {
+	err := w.client.Context().Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if w.options.Full && node != w.start {
+		_, err := node.Get(node.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(w.options.Include) == 0 || containsType(w.options.Include, node.Type) {
+		err := w.fn(node)
+		if err == SkipContainer {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if w.options.MaxDepth > 0 && node.Depth >= w.options.MaxDepth {
+		return nil, nil
+	}
+
+	switch node.Type {
+	case GroupContainer:
+		return w.list(node, ProjectContainer, node.Path()+"/projects")
+	case ProjectContainer:
+		return w.list(node, SessionContainer, node.Path()+"/sessions")
+	case CollectionContainer, SubjectContainer:
+		return w.list(node, SessionContainer, node.Path()+"/sessions")
+	case SessionContainer:
+		if node.collectionId != "" {
+			return w.list(node, AcquisitionContainer, "collections/"+node.collectionId+"/acquisitions?session="+node.Id)
+		}
+		return w.list(node, AcquisitionContainer, node.Path()+"/acquisitions")
+	}
+	return nil, nil
+}
+
+// list returns the children of a node, of one type, as nodes.
+func (w *walker) list(parent *WalkNode, t ContainerType, path string) ([]*WalkNode, error) {
+	if containsType(w.options.Exclude, t) {
+		return nil, nil
+	}
+
+	var aerr *Error
+	var listing []json.RawMessage
+	resp, err := w.client.New().Get(listPath(path, []*ListOptions{w.options.Filter[t]})).Receive(&listing, &aerr)
+	err = CoalesceResponse(resp, err, aerr)
+	if err != nil {
+		return nil, err
+	}
+
+	collectionId := parent.collectionId
+	if parent.Container != nil && parent.Type == CollectionContainer {
+		collectionId = parent.Id
+	}
+
+	nodes := make([]*WalkNode, 0, len(listing))
+	for _, raw := range listing {
+		value := newContainerValue(t)
+		err = json.Unmarshal(raw, value)
+		if err != nil {
+			return nil, err
+		}
+
+		node := &WalkNode{
+			Container:    w.client.Container(t, containerId(value)),
+			Parent:       parent,
+			Depth:        parent.Depth + 1,
+			value:        value,
+			collectionId: collectionId,
+		}
+		if node.Depth == 0 {
+			node.Parent = nil
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// newContainerValue returns a pointer to the struct for a type of container that can be walked.
+func newContainerValue(t ContainerType) interface{} {
+	switch t {
+	case GroupContainer:
+		return &Group{}
+	case ProjectContainer:
+		return &Project{}
+	case SubjectContainer:
+		return &Subject{}
+	case SessionContainer:
+		return &Session{}
+	case AcquisitionContainer:
+		return &Acquisition{}
+	case CollectionContainer:
+		return &Collection{}
+	}
+	return nil
+}
+
+func containerId(value interface{}) string {
+	switch x := value.(type) {
+	case *Group:
+		return x.Id
+	case *Project:
+		return x.Id
+	case *Subject:
+		return x.Id
+	case *Session:
+		return x.Id
+	case *Acquisition:
+		return x.Id
+	case *Collection:
+		return x.Id
+	}
+	return ""
+}
+
+func containsType(types []ContainerType, t ContainerType) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
@@ -35,6 +35,11 @@ func GenerateSignatures(path string) (*token.FileSet, *ParsedSignatures) {
 		parameters := fn.Type.Params.List
 		for _, paramSet := range parameters {
 
+			// Optional trailing parameters, such as list options, are left out
+			if _, variadic := paramSet.Type.(*ast.Ellipsis); variadic {
+				continue
+			}
+
 			pt := "unknown"
 			cgpt := "unknown"
 			ct := "unknown"
@@ -208,12 +213,14 @@ func GetRelevantFunctionSignatures(path string) (*token.FileSet, []*ast.FuncDecl
 			"Upload",
 			"UploadSimple",
 			"UploadToProject",
+			"UploadToSubject",
 			"UploadToSession",
 			"UploadToAcquisition",
 			"UploadToCollection",
 			"Download",
 			"DownloadSimple",
 			"DownloadFromProject",
+			"DownloadFromSubject",
 			"DownloadFromSession",
 			"DownloadFromAcquisition",
 			"DownloadFromCollection",
@@ -221,11 +228,51 @@ func GetRelevantFunctionSignatures(path string) (*token.FileSet, []*ast.FuncDecl
 
 			// Two complex data types in one signature
 			"AddSessionAnalysis",
+
+			// Contexts do not cross the bridge
+			"WithContext",
+			"Context",
+
+			// Capability enum and ServerInfo return
+			"Supports",
+			"GetServerInfo",
+			"RefreshServerInfo",
+
+			// Container handles are not data
+			"Container",
+			"ContainerOf",
+			"PathOf",
+			"Move",
+
+			// Time parameters do not cross the bridge
+			"ModifyProjectIfUnchanged",
+			"ModifySessionIfUnchanged",
+			"ModifyAcquisitionIfUnchanged",
+			"ModifyCollectionIfUnchanged",
+			"ModifySubjectIfUnchanged",
+
+			// Callbacks do not cross the bridge
+			"Walk",
+			"Bulk",
+			"Copy",
+			"CopyProject",
+			"CopySession",
+			"CopyAcquisition",
+			"UpdateProject",
+			"UpdateSession",
+			"UpdateAcquisition",
+			"UpdateCollection",
+			"UpdateSubject",
 		}
 		if stringInSlice(name, blacklist) {
 			return false
 		}
 
+		// Iterators hold a connection open between calls
+		if strings.HasPrefix(name, "Iterate") {
+			return false
+		}
+
 		// Println(name)
 
 		funcs = append(funcs, fn)
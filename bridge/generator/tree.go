@@ -85,7 +85,7 @@ func isDataExpr(ex ast.Expr) (bool, string, bool) {
 	name := ident.Name
 
 	// Whitelist; could replace with lexing later
-	whitelist := []string{"Acquisition", "Analysis", "AnalysisListItem", "Batch", "BatchProposal", "Collection", "Client", "Config", "ContainerReference", "DeletedResponse", "Error", "FileFields", "FileReference", "Formula", "FormulaResult", "Gear", "GearDoc", "GearSource", "Group", "IdResponse", "Input", "Job", "JobLog", "JobLogStatement", "Key", "ModifiedAndJobsResponse", "ModifiedResponse", "Note", "Origin", "Output", "Permission", "ProgressReader", "Project", "Result", "SearchResponse", "RawSearchResponseList", "SearchQuery", "Session", "Subject", "Target", "UploadResponse", "UploadSource", "User", "Version"}
+	whitelist := []string{"Acquisition", "Analysis", "AnalysisListItem", "Batch", "BatchProposal", "Collection", "Client", "Config", "ContainerReference", "DeletedResponse", "Error", "FileFields", "FileReference", "Formula", "FormulaResult", "Gear", "GearDoc", "GearSource", "Group", "IdResponse", "InfoUpdate", "Input", "Job", "JobLog", "JobLogStatement", "Key", "ModifiedAndJobsResponse", "ModifiedResponse", "MoveOptions", "MoveReport", "MoveStep", "Note", "Origin", "Output", "Permission", "ProgressReader", "Project", "ResolvedPath", "Result", "SearchResponse", "RawSearchResponseList", "SearchQuery", "Session", "Subject", "Target", "UploadResponse", "UploadSource", "User", "Version"}
 
 	if stringInSlice(name, whitelist) {
 		return true, "api." + name, true
@@ -0,0 +1,187 @@
+package fake
+
+// routeAnalyses handles analyses by ID. Anything but a plain GET is handled as for other containers.
+func (s *Server) routeAnalyses(req *request) *response {
+	if len(req.path) < 2 {
+		return notFound()
+	}
+
+	analysis, exists := s.store.containers["analyses"][req.path[1]]
+	if !exists {
+		return notFound()
+	}
+
+	if req.is("GET", "analyses", "*") {
+		if req.URL.Query().Get("inflate_job") == "true" {
+			return ok(s.inflateAnalysis(analysis))
+		}
+		return ok(analysis)
+	}
+
+	return s.routeContainers(req)
+}
+
+// routeContainerAnalyses handles the analyses of a container: {type}/{id}/analyses and below.
+func (s *Server) routeContainerAnalyses(req *request, t, id string) *response {
+	analyses := s.store.containers["analyses"]
+
+	switch {
+	case req.is("GET", t, id, "analyses"):
+		return listed(req, s.listAnalyses(t, id, ""), nil)
+
+	case req.is("GET", t, id, "*", "analyses"):
+		if !descends(req.path[2], t) {
+			return notFound()
+		}
+		return listed(req, s.listAnalyses(t, id, req.path[2]), nil)
+
+	case req.is("POST", t, id, "analyses"):
+		return s.addAnalysis(t, id, req)
+	}
+
+	// Everything else is about one analysis, which must belong to this container
+	if len(req.path) < 4 || req.path[2] != "analyses" {
+		return notFound()
+	}
+	analysisId := req.path[3]
+	analysis, exists := analyses[analysisId]
+	if !exists || !isParent(analysis, singular(t), id) {
+		return notFound()
+	}
+
+	switch {
+	case req.is("GET", t, id, "analyses", analysisId):
+		return ok(s.inflateAnalysis(analysis))
+
+	case req.is("POST", t, id, "analyses", analysisId, "notes"):
+		analysis["notes"] = append(analysis.list("notes"), newNote(s.store.newId(), req.body.str("text")))
+		analysis.touch()
+		return modified(1)
+
+	case req.is("GET", t, id, "analyses", analysisId, "files", "*"):
+		file, _ := findFile(analysis, req.path[5])
+		if file == nil {
+			return notFound()
+		}
+		return s.fileContent("analyses", analysisId, file)
+	}
+
+	return notFound()
+}
+
+// addAnalysis creates an analysis. With the job parameter, the body holds both the analysis and a job to run for it.
+func (s *Server) addAnalysis(t, id string, req *request) *response {
+	if req.body == nil {
+		return badRequest("A JSON body is required")
+	}
+
+	body := req.body
+	var job document
+	if req.URL.Query().Get("job") == "true" {
+		body = asDocument(req.body["analysis"])
+		job = asDocument(req.body["job"])
+		if body == nil || job == nil {
+			return badRequest("Both an analysis and a job are required")
+		}
+	}
+
+	analysisId := s.store.newId()
+	analysis := copyDocument(body)
+	analysis["_id"] = analysisId
+	analysis["parent"] = document{"type": singular(t), "id": id}
+	analysis["user"] = UserId
+	analysis["created"] = now()
+	analysis.touch()
+
+	if job != nil {
+		// The job's inputs are recorded as input files of the analysis
+		files := []interface{}{}
+		for _, input := range job.object("inputs") {
+			ref := asDocument(input)
+			container := s.store.containers[ref.str("type")+"s"][ref.str("id")]
+			file, _ := findFile(container, ref.str("name"))
+			if file == nil {
+				return fail(404, "Input file "+ref.str("name")+" not found")
+			}
+			file = copyDocument(file)
+			file["input"] = true
+			files = append(files, file)
+			s.store.files[fileKey("analyses", analysisId, ref.str("name"))] = s.store.files[fileKey(ref.str("type")+"s", ref.str("id"), ref.str("name"))]
+		}
+		analysis["files"] = files
+
+		job = copyDocument(job)
+		job["destination"] = document{"type": "analysis", "id": analysisId}
+
+		jobId, failure := s.addJob(job)
+		if failure != nil {
+			return failure
+		}
+		analysis["job"] = jobId
+	}
+
+	s.store.containers["analyses"][analysisId] = analysis
+	return created(analysisId)
+}
+
+// listAnalyses lists the analyses of a container. If subType is set, it lists those of its descendants of that type instead.
+func (s *Server) listAnalyses(t, id, subType string) []document {
+	result := []document{}
+
+	for _, analysis := range sorted(s.store.containers["analyses"]) {
+		parent := analysis.object("parent")
+		parentType := parent.str("type") + "s"
+
+		if subType == "" && isParent(analysis, singular(t), id) {
+			result = append(result, analysis)
+		} else if subType != "" && parentType == subType && s.isAncestor(t, id, parentType, parent.str("id")) {
+			result = append(result, analysis)
+		}
+	}
+
+	return result
+}
+
+// inflateAnalysis returns an analysis with its job document in place of the job's ID.
+func (s *Server) inflateAnalysis(analysis document) document {
+	result := copyDocument(analysis)
+	if job, exists := s.store.jobs[analysis.str("job")]; exists {
+		result["job"] = job
+	}
+	return result
+}
+
+func isParent(analysis document, parentType, parentId string) bool {
+	parent := analysis.object("parent")
+	return parent.str("type") == parentType && parent.str("id") == parentId
+}
+
+// descends reports whether containers of type t are below those of type ancestorType in the hierarchy.
+func descends(t, ancestorType string) bool {
+	for parent, hasParent := parentTypes[t]; hasParent; parent, hasParent = parentTypes[parent[0]] {
+		if parent[0] == ancestorType {
+			return true
+		}
+	}
+	return false
+}
+
+// isAncestor reports whether a container is an ancestor of another.
+func (s *Server) isAncestor(ancestorType, ancestorId, t, id string) bool {
+	for {
+		parent, hasParent := parentTypes[t]
+		if !hasParent {
+			return false
+		}
+
+		container, exists := s.store.containers[t][id]
+		if !exists {
+			return false
+		}
+
+		t, id = parent[0], container.str(parent[1])
+		if t == ancestorType && id == ancestorId {
+			return true
+		}
+	}
+}
@@ -0,0 +1,744 @@
+package fake
+
+import "strings"
+
+// parentTypes maps each container type to the type of its parent, and the field that holds the parent's ID.
+var parentTypes = map[string][2]string{
+	"projects":     {"groups", "group"},
+	"subjects":     {"projects", "project"},
+	"sessions":     {"projects", "project"},
+	"acquisitions": {"sessions", "session"},
+}
+
+// childTypes maps each container type to the type of its children.
+// Subjects are not in the chain: they belong to a project, and hold sessions, which are listed with subjects/<id>/sessions.
+var childTypes = map[string]string{
+	"groups":   "projects",
+	"projects": "sessions",
+	"sessions": "acquisitions",
+}
+
+// singular returns the name of a container type as used in references, such as "session".
+func singular(t string) string {
+	return t[:len(t)-1]
+}
+
+func (s *Server) routeContainers(req *request) *response {
+	t := req.path[0]
+	containers := s.store.containers[t]
+
+	// Sessions hold a copy of their subject, which follows any change to it
+	if t == "subjects" && len(req.path) > 1 && req.Method != "GET" {
+		defer s.syncSubject(req.path[1])
+	}
+
+	if len(req.path) == 1 {
+		switch req.Method {
+		case "GET":
+			readable := s.readable(req, t, sorted(containers))
+			if t == "groups" {
+				return listed(req, readable, nil)
+			}
+			return listed(req, readable, listView)
+		case "POST":
+			return s.addContainer(req, t)
+		}
+		return notFound()
+	}
+
+	id := req.path[1]
+	container, exists := containers[id]
+	if !exists {
+		return notFound()
+	}
+
+	level := "rw"
+	if req.Method == "GET" {
+		level = "ro"
+	} else if len(req.path) > 2 && req.path[2] == "permissions" {
+		level = "admin"
+	}
+	if !s.allowed(req, t, container, level) {
+		return forbidden(req)
+	}
+
+	switch {
+	case req.is("GET", t, "*"):
+		return ok(s.view(t, container))
+
+	case req.is("PUT", t, "*"):
+		if resp := s.checkIfMatch(req, t, container); resp != nil {
+			return resp
+		}
+		return s.modifyContainer(req, t, container)
+
+	case req.is("DELETE", t, "*"):
+		s.deleteContainer(t, id)
+		return deleted(1)
+
+	case req.is("POST", t, "*", "notes"):
+		container["notes"] = append(container.list("notes"), newNote(s.store.newId(), req.body.str("text")))
+		container.touch()
+		return modified(1)
+
+	case req.is("POST", t, "*", "tags"):
+		tag := req.body.str("value")
+		if tag == "" {
+			return badRequest("Tag value is required")
+		}
+		tags := container.list("tags")
+		if containsString(tags, tag) {
+			return fail(409, "Tag "+tag+" already exists")
+		}
+		container["tags"] = append(tags, tag)
+		container.touch()
+		return modified(1)
+
+	case req.is("PUT", t, "*", "tags", "*"):
+		tag, tags := req.path[3], container.list("tags")
+		newTag := req.body.str("value")
+		if newTag == "" {
+			return badRequest("Tag value is required")
+		}
+		if !containsString(tags, tag) {
+			return notFound()
+		}
+		if newTag != tag && containsString(tags, newTag) {
+			return fail(409, "Tag "+newTag+" already exists")
+		}
+		for x, value := range tags {
+			if value == tag {
+				tags[x] = newTag
+			}
+		}
+		container.touch()
+		return modified(1)
+
+	case req.is("DELETE", t, "*", "tags", "*"):
+		tag, tags := req.path[3], container.list("tags")
+		if !containsString(tags, tag) {
+			return notFound()
+		}
+		container["tags"] = removeString(tags, tag)
+		container.touch()
+		return modified(1)
+
+	case req.is("PUT", t, "*", "notes", "*"):
+		note, _ := findNote(container, req.path[3])
+		if note == nil {
+			return notFound()
+		}
+		note["text"] = req.body.str("text")
+		note.touch()
+		container.touch()
+		return modified(1)
+
+	case req.is("DELETE", t, "*", "notes", "*"):
+		note, index := findNote(container, req.path[3])
+		if note == nil {
+			return notFound()
+		}
+		notes := container.list("notes")
+		container["notes"] = append(notes[:index:index], notes[index+1:]...)
+		container.touch()
+		return modified(1)
+
+	case req.is("POST", t, "*", "info"):
+		return updateInfo(container, req.body)
+
+	case req.is("GET", t, "*", "*") && req.path[2] != "files" && req.path[2] != "analyses" && req.path[2] != "permissions":
+		return s.listChildren(req, t, id, req.path[2])
+	}
+
+	if len(req.path) >= 3 && req.path[2] == "permissions" {
+		return s.routePermissions(req, t, id, container)
+	}
+
+	if len(req.path) >= 3 && req.path[2] == "files" {
+		return s.routeFiles(req, t, id, container)
+	}
+	if (len(req.path) >= 3 && req.path[2] == "analyses") || (len(req.path) >= 4 && req.path[3] == "analyses") {
+		return s.routeContainerAnalyses(req, t, id)
+	}
+
+	return notFound()
+}
+
+func (s *Server) addContainer(req *request, t string) *response {
+	body := req.body
+	if body == nil {
+		return badRequest("A JSON body is required")
+	}
+	container := copyDocument(body)
+
+	id := container.str("_id")
+	if t == "groups" {
+		if id == "" {
+			return badRequest("Group ID is required")
+		}
+		if _, exists := s.store.containers[t][id]; exists {
+			return fail(409, "Group "+id+" already exists")
+		}
+	} else {
+		id = s.store.newId()
+		container["_id"] = id
+	}
+
+	// Permissions are inherited from the parent, and the creator is made an admin, as the real API does
+	permissions := []interface{}{}
+	if parent, hasParent := parentTypes[t]; hasParent {
+		parentId := container.str(parent[1])
+		parentDoc, exists := s.store.containers[parent[0]][parentId]
+		if !exists {
+			return fail(404, "Parent "+singular(parent[0])+" "+parentId+" not found")
+		}
+		if !s.allowed(req, parent[0], parentDoc, "rw") {
+			return forbidden(req)
+		}
+		permissions = copyValue(parentDoc.list("permissions")).([]interface{})
+
+		if t == "sessions" {
+			container["group"] = parentDoc.str("group")
+		}
+	}
+	if findPermission(permissions, UserId) == nil {
+		permissions = append(permissions, document{"_id": UserId, "access": "admin"})
+	}
+	if _, set := container["permissions"]; !set {
+		container["permissions"] = permissions
+	}
+
+	if t == "collections" {
+		container["curator"] = UserId
+	}
+	if t == "subjects" && container.str("code") != "" && s.findSubject(container.str("project"), container.str("code")) != nil {
+		return fail(409, "Subject "+container.str("code")+" already exists in the project")
+	}
+	if t == "sessions" {
+		response := s.linkSubject(container, container.str("project"), copyDocument(container["subject"]))
+		if response != nil {
+			return response
+		}
+	}
+
+	container["created"] = now()
+	container.touch()
+	s.store.containers[t][id] = container
+	return created(id)
+}
+
+func (s *Server) modifyContainer(req *request, t string, container document) *response {
+	body := req.body
+	if body == nil {
+		return badRequest("A JSON body is required")
+	}
+	changes := copyDocument(body)
+	delete(changes, "_id")
+
+	// Collection membership is changed with operations, rather than set directly
+	if contents, isSet := changes["contents"]; isSet && t == "collections" {
+		delete(changes, "contents")
+		response := s.modifyCollectionContents(container.str("_id"), copyDocument(contents))
+		if response != nil {
+			return response
+		}
+	}
+
+	// Moving a container checks its new parent, and updates any denormalized references.
+	// Sessions and acquisitions take the permissions of their new parent, as the real API does.
+	var newParent document
+	if parent, hasParent := parentTypes[t]; hasParent {
+		if parentId, moved := changes[parent[1]].(string); moved && parentId != container.str(parent[1]) {
+			parentDoc, exists := s.store.containers[parent[0]][parentId]
+			if !exists {
+				return fail(404, "Parent "+singular(parent[0])+" "+parentId+" not found")
+			}
+			if !s.allowed(req, parent[0], parentDoc, "rw") {
+				return forbidden(req)
+			}
+			if t == "sessions" {
+				changes["group"] = parentDoc.str("group")
+			}
+			if t == "sessions" || t == "acquisitions" {
+				newParent = parentDoc
+			}
+		}
+	}
+
+	// Subject codes are unique within a project
+	if t == "subjects" {
+		projectId, moved := changes["project"].(string)
+		if !moved {
+			projectId = container.str("project")
+		}
+		code, renamed := changes["code"].(string)
+		if !renamed {
+			code = container.str("code")
+		}
+		if other := s.findSubject(projectId, code); code != "" && other != nil && other.str("_id") != container.str("_id") {
+			return fail(409, "Subject "+code+" already exists in the project")
+		}
+	}
+
+	// Subject fields given to a session change the subject it links to, or link it to another.
+	// A session moved to another project links to the subject there with the same code.
+	if t == "sessions" {
+		subject, subjectSet := changes["subject"].(map[string]interface{})
+		projectId, moved := changes["project"].(string)
+		moved = moved && projectId != container.str("project")
+		delete(changes, "subject")
+
+		if subjectSet || moved {
+			fields := subjectView(asDocument(container["subject"]))
+			if moved {
+				delete(fields, "_id")
+			} else {
+				projectId = container.str("project")
+			}
+			fields.merge(copyDocument(subject))
+
+			response := s.linkSubject(container, projectId, fields)
+			if response != nil {
+				return response
+			}
+		}
+	}
+
+	// Info is merged, rather than replaced
+	if info, isSet := changes["info"].(map[string]interface{}); isSet {
+		container.object("info").merge(document(info))
+		delete(changes, "info")
+	}
+
+	container.merge(changes)
+	container.touch()
+
+	if newParent != nil {
+		container["permissions"] = copyValue(newParent.list("permissions"))
+		s.propagatePermissions(t, container)
+	}
+
+	if t == "projects" {
+		for _, session := range s.store.containers["sessions"] {
+			if session.str("project") == container.str("_id") {
+				session["group"] = container.str("group")
+			}
+		}
+	}
+
+	// Moving a subject moves its sessions
+	if t == "subjects" {
+		project := s.store.containers["projects"][container.str("project")]
+		for _, session := range s.subjectSessions(container.str("_id")) {
+			session["project"] = project.str("_id")
+			session["group"] = project.str("group")
+		}
+	}
+
+	return modified(1)
+}
+
+// deleteContainer removes a container with its descendants, analyses and files.
+// Subjects are removed with their project, and remove their sessions.
+func (s *Server) deleteContainer(t, id string) {
+	switch t {
+	case "projects":
+		for subjectId, subject := range s.store.containers["subjects"] {
+			if subject.str("project") == id {
+				s.deleteContainer("subjects", subjectId)
+			}
+		}
+	case "subjects":
+		for _, session := range s.subjectSessions(id) {
+			s.deleteContainer("sessions", session.str("_id"))
+		}
+	}
+
+	if childType, hasChildren := childTypes[t]; hasChildren {
+		field := parentTypes[childType][1]
+		for childId, child := range s.store.containers[childType] {
+			if child.str(field) == id {
+				s.deleteContainer(childType, childId)
+			}
+		}
+	}
+
+	for analysisId, analysis := range s.store.containers["analyses"] {
+		parent := analysis.object("parent")
+		if parent.str("type") == singular(t) && parent.str("id") == id {
+			s.deleteContainer("analyses", analysisId)
+		}
+	}
+
+	if t == "collections" {
+		for _, acquisition := range s.store.containers["acquisitions"] {
+			acquisition["collections"] = removeString(acquisition.list("collections"), id)
+		}
+	}
+
+	for _, file := range s.store.containers[t][id].list("files") {
+		delete(s.store.files, fileKey(t, id, asDocument(file).str("name")))
+	}
+
+	delete(s.store.containers[t], id)
+}
+
+// listChildren lists the sessions or acquisitions within a container.
+func (s *Server) listChildren(req *request, t, id, childType string) *response {
+	sessionId := req.URL.Query().Get("session")
+	result := []document{}
+
+	if t == "collections" {
+		sessions := map[string]bool{}
+		for _, acquisition := range sorted(s.store.containers["acquisitions"]) {
+			if !containsString(acquisition.list("collections"), id) {
+				continue
+			}
+			if childType == "acquisitions" && (sessionId == "" || acquisition.str("session") == sessionId) {
+				result = append(result, acquisition)
+			}
+			sessions[acquisition.str("session")] = true
+		}
+
+		if childType == "sessions" {
+			for _, session := range sorted(s.store.containers["sessions"]) {
+				if sessions[session.str("_id")] {
+					result = append(result, session)
+				}
+			}
+		} else if childType != "acquisitions" {
+			return notFound()
+		}
+		return listed(req, s.readable(req, childType, result), listView)
+	}
+
+	if t == "subjects" && childType == "sessions" {
+		for _, session := range sorted(s.store.containers["sessions"]) {
+			if asDocument(session["subject"]).str("_id") == id {
+				result = append(result, session)
+			}
+		}
+		return listed(req, s.readable(req, childType, result), listView)
+	}
+
+	if childTypes[t] != childType && !(t == "projects" && childType == "subjects") {
+		return notFound()
+	}
+	field := parentTypes[childType][1]
+	for _, child := range sorted(s.store.containers[childType]) {
+		if child.str(field) == id {
+			result = append(result, child)
+		}
+	}
+	return listed(req, s.readable(req, childType, result), listView)
+}
+
+// findSubject returns the subject of a project with a code, or nil if there is none.
+func (s *Server) findSubject(projectId, code string) document {
+	for _, subject := range s.store.containers["subjects"] {
+		if subject.str("project") == projectId && subject.str("code") == code {
+			return subject
+		}
+	}
+	return nil
+}
+
+// subjectSessions returns the sessions that link to a subject.
+func (s *Server) subjectSessions(id string) []document {
+	var sessions []document
+	for _, session := range sorted(s.store.containers["sessions"]) {
+		if asDocument(session["subject"]).str("_id") == id {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// linkSubject links a session to a subject of a project, as the real API does: the one with the ID in fields,
+// or else the one with the code, or else a new subject. The other fields are applied to the subject.
+func (s *Server) linkSubject(session document, projectId string, fields document) *response {
+	fields = copyDocument(fields)
+	code := fields.str("code")
+
+	var subject document
+	if id := fields.str("_id"); id != "" {
+		subject = s.store.containers["subjects"][id]
+		if subject == nil {
+			return fail(404, "Subject "+id+" not found")
+		}
+		if subject.str("project") != projectId {
+			return badRequest("Subject " + id + " is not in project " + projectId)
+		}
+		if other := s.findSubject(projectId, code); code != "" && other != nil && other.str("_id") != id {
+			return fail(409, "Subject "+code+" already exists in the project")
+		}
+	} else if code != "" {
+		subject = s.findSubject(projectId, code)
+	}
+	delete(fields, "_id")
+
+	if subject == nil {
+		project := s.store.containers["projects"][projectId]
+		subject = document{
+			"_id":         s.store.newId(),
+			"project":     projectId,
+			"permissions": copyValue(project.list("permissions")),
+			"created":     now(),
+		}
+		s.store.containers["subjects"][subject.str("_id")] = subject
+	}
+
+	if info, isSet := fields["info"].(map[string]interface{}); isSet {
+		subject.object("info").merge(document(info))
+		delete(fields, "info")
+	}
+	subject.merge(fields)
+	subject.touch()
+
+	session["subject"] = subjectView(subject)
+	s.syncSubject(subject.str("_id"))
+	return nil
+}
+
+// syncSubject copies a subject's fields to the sessions that link to it.
+func (s *Server) syncSubject(id string) {
+	subject, exists := s.store.containers["subjects"][id]
+	if !exists {
+		return
+	}
+	for _, session := range s.subjectSessions(id) {
+		session["subject"] = subjectView(subject)
+	}
+}
+
+// subjectView returns the fields of a subject that its sessions hold.
+func subjectView(subject document) document {
+	view := document{}
+	for _, key := range []string{"_id", "code", "firstname", "lastname", "sex", "age", "info"} {
+		if value, exists := subject[key]; exists {
+			view[key] = copyValue(value)
+		}
+	}
+	return view
+}
+
+// modifyCollectionContents adds or removes the acquisitions of a collection.
+// Adding a session adds each of its acquisitions.
+func (s *Server) modifyCollectionContents(id string, contents document) *response {
+	operation := contents.str("operation")
+	if operation != "add" && operation != "remove" {
+		return badRequest("Invalid collection operation " + operation)
+	}
+
+	for _, x := range contents.list("nodes") {
+		node := asDocument(x)
+		if node == nil {
+			return badRequest("Invalid collection node")
+		}
+		nodeId := node.str("_id")
+
+		var acquisitions []document
+		switch node.str("level") {
+		case "session":
+			if _, exists := s.store.containers["sessions"][nodeId]; !exists {
+				return fail(404, "Session "+nodeId+" not found")
+			}
+			for _, acquisition := range s.store.containers["acquisitions"] {
+				if acquisition.str("session") == nodeId {
+					acquisitions = append(acquisitions, acquisition)
+				}
+			}
+		case "acquisition":
+			acquisition, exists := s.store.containers["acquisitions"][nodeId]
+			if !exists {
+				return fail(404, "Acquisition "+nodeId+" not found")
+			}
+			acquisitions = append(acquisitions, acquisition)
+		default:
+			return badRequest("Invalid collection node level " + node.str("level"))
+		}
+
+		for _, acquisition := range acquisitions {
+			collections := acquisition.list("collections")
+			if operation == "add" && !containsString(collections, id) {
+				acquisition["collections"] = append(collections, id)
+			} else if operation == "remove" {
+				acquisition["collections"] = removeString(collections, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// listView returns containers as listed by the real API, which leaves out their files, notes, tags, info and analyses.
+func listView(containers []document) []document {
+	result := make([]document, 0, len(containers))
+	for _, container := range containers {
+		container = copyDocument(container)
+		container["files"] = []interface{}{}
+		container["notes"] = []interface{}{}
+		container["tags"] = []interface{}{}
+		container["info"] = document{}
+		delete(container, "analyses")
+
+		if subject := asDocument(container["subject"]); subject != nil {
+			container["subject"] = document{"_id": subject["_id"], "code": subject["code"], "info": document{}}
+		}
+
+		result = append(result, container)
+	}
+	return result
+}
+
+// checkIfMatch returns a failure if the request has an If-Match header that doesn't match the container.
+// As with HTTP, a change made with If-Match applies only to the container as last read.
+func (s *Server) checkIfMatch(req *request, t string, container document) *response {
+	match := req.Header.Get("If-Match")
+	if match == "" || match == "*" {
+		return nil
+	}
+	current := ok(s.view(t, container))
+	current.encode()
+	if match != etagOf(current.content) {
+		return fail(412, "The "+singular(t)+" was modified since it was read")
+	}
+	return nil
+}
+
+// view returns a container as a GET of it does.
+func (s *Server) view(t string, container document) document {
+	if t == "sessions" {
+		return s.inflateSession(container)
+	}
+	return container
+}
+
+// inflateSession returns a session with its analyses, as the real API does.
+func (s *Server) inflateSession(session document) document {
+	result := copyDocument(session)
+
+	analyses := []interface{}{}
+	for _, analysis := range sorted(s.store.containers["analyses"]) {
+		parent := analysis.object("parent")
+		if parent.str("type") == "session" && parent.str("id") == session.str("_id") {
+			analyses = append(analyses, s.inflateAnalysis(analysis))
+		}
+	}
+	if len(analyses) > 0 {
+		result["analyses"] = analyses
+	}
+
+	return result
+}
+
+func newNote(id, text string) document {
+	return document{
+		"id":       id,
+		"user":     UserId,
+		"text":     text,
+		"created":  now(),
+		"modified": now(),
+	}
+}
+
+// findNote returns one of a container's notes, and its index, or nil and -1 if there is no such note.
+func findNote(container document, id string) (document, int) {
+	for x, note := range container.list("notes") {
+		if note := asDocument(note); note.str("id") == id {
+			return note, x
+		}
+	}
+	return nil, -1
+}
+
+// updateInfo applies an info update to a container or file: a replace of its info, or a set and delete of its info fields.
+// As with the API, keys of a set or delete are dotted paths, which reach into nested objects.
+func updateInfo(target, body document) *response {
+	info := target.object("info")
+
+	switch {
+	case body["replace"] != nil:
+		if body["set"] != nil || body["delete"] != nil {
+			return badRequest("Cannot set or delete AND replace info fields")
+		}
+		replace, isMap := body["replace"].(map[string]interface{})
+		if !isMap {
+			return badRequest("Info replacement must be an object")
+		}
+		target["info"] = copyDocument(replace)
+
+	case body["set"] != nil || body["delete"] != nil:
+		set, isMap := body["set"].(map[string]interface{})
+		if body["set"] != nil && !isMap {
+			return badRequest("Info set must be an object")
+		}
+
+		// Checked first, so that a bad update changes nothing
+		for key := range set {
+			if !canSetPath(info, key) {
+				return badRequest("Cannot create info field " + key + " inside a value that is not an object")
+			}
+		}
+		for key, value := range copyDocument(set) {
+			setPath(info, key, value)
+		}
+		for _, key := range body.list("delete") {
+			if key, isString := key.(string); isString {
+				deletePath(info, key)
+			}
+		}
+
+	default:
+		return badRequest("Info update must set, replace, or delete fields")
+	}
+
+	target.touch()
+	return modified(1)
+}
+
+// canSetPath reports whether a dotted path can be set in a document, which it cannot if it passes through a value that is not an object.
+func canSetPath(d document, path string) bool {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		if d[key] == nil {
+			return true
+		}
+		d = asDocument(d[key])
+		if d == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// setPath sets the value at a dotted path in a document, creating objects along the way.
+func setPath(d document, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		d = d.object(key)
+	}
+	d[keys[len(keys)-1]] = value
+}
+
+// deletePath removes the value at a dotted path in a document, if there is one.
+func deletePath(d document, path string) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		d = asDocument(d[key])
+		if d == nil {
+			return
+		}
+	}
+	delete(d, keys[len(keys)-1])
+}
+
+func removeString(list []interface{}, value string) []interface{} {
+	result := []interface{}{}
+	for _, x := range list {
+		if s, _ := x.(string); s != value {
+			result = append(result, x)
+		}
+	}
+	return result
+}
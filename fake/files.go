@@ -0,0 +1,155 @@
+package fake
+
+import (
+	"mime"
+	"path"
+	"strings"
+)
+
+// fileTypes are the types the real API detects from file extensions, in part.
+var fileTypes = map[string]string{
+	".txt":    "text",
+	".csv":    "tabular data",
+	".tsv":    "tabular data",
+	".json":   "source code",
+	".py":     "source code",
+	".pdf":    "pdf",
+	".png":    "image",
+	".jpg":    "image",
+	".dcm":    "dicom",
+	".nii":    "nifti",
+	".nii.gz": "nifti",
+	".zip":    "archive",
+}
+
+// fileType returns the type of a file by its extension, or nil if unknown.
+func fileType(name string) interface{} {
+	for ext, t := range fileTypes {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return t
+		}
+	}
+	return nil
+}
+
+// fileKey identifies the content of a file in the store.
+func fileKey(t, id, name string) string {
+	return t + "/" + id + "/" + name
+}
+
+// findFile returns a file of a container, and its index, or nil and -1 if missing.
+func findFile(container document, name string) (document, int) {
+	for x, file := range container.list("files") {
+		if file := asDocument(file); file.str("name") == name {
+			return file, x
+		}
+	}
+	return nil, -1
+}
+
+// routeFiles handles the files of a container: {type}/{id}/files and below.
+func (s *Server) routeFiles(req *request, t, id string, container document) *response {
+	if req.is("POST", t, id, "files") {
+		return s.uploadFiles(t, id, container, req.uploads)
+	}
+	if len(req.path) < 4 {
+		return notFound()
+	}
+
+	name := req.path[3]
+	file, index := findFile(container, name)
+	if file == nil {
+		return notFound()
+	}
+
+	switch {
+	case req.is("GET", t, id, "files", name):
+		// An empty ticket parameter asks for a ticket, which then allows downloading without credentials
+		if ticket, isSet := req.URL.Query()["ticket"]; isSet && ticket[0] == "" {
+			ticket := s.store.newId()
+			s.store.tickets[ticket] = req.URL.Path
+			return ok(document{"ticket": ticket})
+		}
+		return s.fileContent(t, id, file)
+
+	case req.is("PUT", t, id, "files", name):
+		if resp := s.checkIfMatch(req, t, container); resp != nil {
+			return resp
+		}
+		for _, field := range []string{"modality", "measurements", "type", "tags"} {
+			if value, isSet := req.body[field]; isSet {
+				file[field] = value
+			}
+		}
+		file.touch()
+		return ok(document{"modified": 1, "jobs_triggered": 0})
+
+	case req.is("DELETE", t, id, "files", name):
+		files := container.list("files")
+		container["files"] = append(files[:index:index], files[index+1:]...)
+		delete(s.store.files, fileKey(t, id, name))
+		container.touch()
+		return modified(1)
+
+	case req.is("GET", t, id, "files", name, "info"):
+		return ok(file.object("info"))
+
+	case req.is("POST", t, id, "files", name, "info"):
+		return updateInfo(file, req.body)
+	}
+
+	return notFound()
+}
+
+// uploadFiles adds files to a container, replacing any with the same name.
+func (s *Server) uploadFiles(t, id string, container document, uploads []*upload) *response {
+	if len(uploads) == 0 {
+		return badRequest("No files were uploaded")
+	}
+
+	result := []document{}
+	for _, upload := range uploads {
+		mimetype, _, _ := mime.ParseMediaType(mime.TypeByExtension(path.Ext(upload.name)))
+		if mimetype == "" {
+			mimetype = "application/octet-stream"
+		}
+
+		file := document{
+			"name":         upload.name,
+			"size":         len(upload.content),
+			"mimetype":     mimetype,
+			"type":         fileType(upload.name),
+			"origin":       document{"type": "user", "id": UserId},
+			"tags":         []interface{}{},
+			"measurements": []interface{}{},
+			"info":         document{},
+			"created":      now(),
+			"modified":     now(),
+		}
+		if t == "analyses" {
+			file["output"] = true
+		}
+
+		files := container.list("files")
+		if _, index := findFile(container, upload.name); index >= 0 {
+			files[index] = file
+		} else {
+			files = append(files, file)
+		}
+		container["files"] = files
+
+		s.store.files[fileKey(t, id, upload.name)] = upload.content
+		result = append(result, file)
+	}
+
+	container.touch()
+	return ok(result)
+}
+
+func (s *Server) fileContent(t, id string, file document) *response {
+	content := s.store.files[fileKey(t, id, file.str("name"))]
+	if content == nil {
+		content = []byte{}
+	}
+	return &response{status: 200, content: content, contentType: file.str("mimetype")}
+}
@@ -0,0 +1,348 @@
+package fake
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jobTransitions lists the states each job state may change to.
+var jobTransitions = map[string][]string{
+	"pending": {"running", "cancelled"},
+	"running": {"complete", "failed", "cancelled"},
+}
+
+func (s *Server) routeJobs(req *request) *response {
+	jobs := s.store.jobs
+
+	switch {
+	case req.is("POST", "jobs", "add"):
+		if req.body == nil {
+			return badRequest("A JSON body is required")
+		}
+		id, failure := s.addJob(copyDocument(req.body))
+		if failure != nil {
+			return failure
+		}
+		return created(id)
+
+	case req.is("GET", "jobs", "next"):
+		return s.startNextJob(req.URL.Query()["tags"])
+	}
+
+	if len(req.path) < 2 {
+		return notFound()
+	}
+	id := req.path[1]
+	job, exists := jobs[id]
+	if !exists {
+		return notFound()
+	}
+
+	switch {
+	case req.is("GET", "jobs", "*"):
+		return ok(job)
+
+	case req.is("PUT", "jobs", "*"):
+		if req.body == nil {
+			return badRequest("A JSON body is required")
+		}
+		changes := copyDocument(req.body)
+		delete(changes, "id")
+
+		if state := changes.str("state"); state != "" && state != job.str("state") {
+			allowed := false
+			for _, next := range jobTransitions[job.str("state")] {
+				allowed = allowed || next == state
+			}
+			if !allowed {
+				return badRequest("Cannot change job state from " + job.str("state") + " to " + state)
+			}
+		}
+
+		// An empty modification is a heartbeat, which only updates the modified time
+		job.merge(changes)
+		job.touch()
+		return modified(1)
+
+	case req.is("GET", "jobs", "*", "logs"):
+		logs := s.store.jobLogs[id]
+		if logs == nil {
+			logs = []interface{}{}
+		}
+		return ok(document{"_id": id, "logs": logs})
+
+	case req.is("POST", "jobs", "*", "logs"):
+		var statements []interface{}
+		if json.Unmarshal(req.raw, &statements) != nil {
+			return badRequest("Job logs must be an array of statements")
+		}
+		s.store.jobLogs[id] = append(s.store.jobLogs[id], statements...)
+		return ok(nil)
+	}
+
+	return notFound()
+}
+
+// addJob creates a pending job for an existing gear.
+func (s *Server) addJob(job document) (string, *response) {
+	if _, exists := s.store.gears[job.str("gear_id")]; !exists {
+		return "", fail(404, "Gear "+job.str("gear_id")+" not found")
+	}
+
+	id := s.store.newId()
+	job["id"] = id
+	job["state"] = "pending"
+	job["attempt"] = 1
+	job["origin"] = document{"type": "user", "id": UserId}
+	if _, isSet := job["config"]; !isSet {
+		job["config"] = document{}
+	}
+	if _, isSet := job["tags"]; !isSet {
+		job["tags"] = []interface{}{}
+	}
+	job["created"] = now()
+	job.touch()
+
+	s.store.jobs[id] = job
+	return id, nil
+}
+
+// startNextJob starts the oldest pending job with any of the given tags, or any pending job if none are given.
+func (s *Server) startNextJob(tags []string) *response {
+	for _, job := range sorted(s.store.jobs) {
+		if job.str("state") != "pending" {
+			continue
+		}
+
+		matched := len(tags) == 0
+		for _, tag := range tags {
+			matched = matched || containsString(job.list("tags"), tag)
+		}
+		if !matched {
+			continue
+		}
+
+		job["state"] = "running"
+		job["request"] = s.formula(job)
+		job.touch()
+		return ok(job)
+	}
+
+	return badRequest("No jobs to process")
+}
+
+// formula describes how an engine would run a job: fetch its inputs, run its gear, and upload its outputs.
+func (s *Server) formula(job document) document {
+	inputs := []interface{}{}
+	for name, input := range job.object("inputs") {
+		ref := asDocument(input)
+		uri := "/" + ref.str("type") + "s/" + ref.str("id") + "/files/" + ref.str("name")
+		inputs = append(inputs, document{"type": "scitran", "uri": uri, "location": "/flywheel/v0/input/" + name})
+	}
+
+	destination := job.object("destination")
+	gear := s.store.gears[job.str("gear_id")].object("gear")
+
+	return document{
+		"inputs": inputs,
+		"target": document{
+			"command": strings.Fields(gear.str("command")),
+			"env":     document{},
+			"dir":     "/flywheel/v0",
+		},
+		"outputs": []interface{}{document{
+			"type":     "scitran",
+			"uri":      "/engine?level=" + destination.str("type") + "&id=" + destination.str("id") + "&job=" + job.str("id"),
+			"location": "/flywheel/v0/output",
+		}},
+	}
+}
+
+func (s *Server) routeGears(req *request) *response {
+	gears := s.store.gears
+
+	switch {
+	case req.is("GET", "gears"):
+		return listed(req, sorted(gears), nil)
+
+	case req.is("POST", "gears", "*"):
+		if req.body == nil {
+			return badRequest("A JSON body is required")
+		}
+		doc := copyDocument(req.body)
+		gear := doc.object("gear")
+		if gear.str("name") != req.path[1] {
+			return badRequest("Gear name does not match the URL")
+		}
+		for _, existing := range gears {
+			existingGear := existing.object("gear")
+			if existingGear.str("name") == gear.str("name") && existingGear.str("version") == gear.str("version") {
+				return fail(409, "Gear "+gear.str("name")+" "+gear.str("version")+" already exists")
+			}
+		}
+
+		id := s.store.newId()
+		doc["_id"] = id
+		doc["created"] = now()
+		doc.touch()
+		gears[id] = doc
+		return created(id)
+	}
+
+	if len(req.path) < 2 {
+		return notFound()
+	}
+	doc, exists := gears[req.path[1]]
+	if !exists {
+		return notFound()
+	}
+
+	switch {
+	case req.is("GET", "gears", "*"):
+		return ok(doc)
+
+	case req.is("GET", "gears", "*", "invocation"):
+		gear := doc.object("gear")
+		return ok(document{
+			"$schema":  "http://json-schema.org/draft-04/schema#",
+			"title":    "Invocation manifest for " + gear.str("label"),
+			"type":     "object",
+			"required": []interface{}{"config", "inputs"},
+			"properties": document{
+				"config": document{"type": "object", "properties": gear.object("config")},
+				"inputs": document{"type": "object", "properties": gear.object("inputs")},
+			},
+		})
+
+	case req.is("DELETE", "gears", "*"):
+		delete(gears, req.path[1])
+		return ok(nil)
+	}
+
+	return notFound()
+}
+
+func (s *Server) routeBatches(req *request) *response {
+	batches := s.store.batches
+
+	switch {
+	case req.is("GET", "batch"):
+		return listed(req, sorted(batches), nil)
+
+	case req.is("POST", "batch"):
+		return s.proposeBatch(req.body)
+	}
+
+	if len(req.path) < 2 {
+		return notFound()
+	}
+	batch, exists := batches[req.path[1]]
+	if !exists {
+		return notFound()
+	}
+
+	switch {
+	case req.is("GET", "batch", "*"):
+		return ok(batch)
+
+	case req.is("POST", "batch", "*", "run"):
+		if batch.str("state") != "pending" {
+			return badRequest("Batch has already been started")
+		}
+
+		jobs := []document{}
+		ids := []interface{}{}
+		for _, target := range batch.list("targets") {
+			job := document{
+				"gear_id":     batch.str("gear_id"),
+				"config":      copyDocument(batch.object("config")),
+				"tags":        copyValue(batch.list("tags")),
+				"destination": copyDocument(target),
+				"inputs":      document{},
+			}
+			id, failure := s.addJob(job)
+			if failure != nil {
+				return failure
+			}
+			jobs = append(jobs, job)
+			ids = append(ids, id)
+		}
+
+		batch["jobs"] = ids
+		batch["state"] = "running"
+		batch.touch()
+		return ok(jobs)
+
+	case req.is("POST", "batch", "*", "cancel"):
+		if batch.str("state") != "running" {
+			return badRequest("Only running batches can be cancelled")
+		}
+
+		cancelled := 0
+		for _, id := range batch.list("jobs") {
+			job := s.store.jobs[id.(string)]
+			if state := job.str("state"); state == "pending" || state == "running" {
+				job["state"] = "cancelled"
+				job.touch()
+				cancelled++
+			}
+		}
+
+		batch["state"] = "cancelled"
+		batch.touch()
+		return ok(document{"number_cancelled": cancelled})
+	}
+
+	return notFound()
+}
+
+// proposeBatch creates a pending batch. Every target that exists is matched, without checking it has the gear's inputs.
+func (s *Server) proposeBatch(body document) *response {
+	if body == nil {
+		return badRequest("A JSON body is required")
+	}
+	if _, exists := s.store.gears[body.str("gear_id")]; !exists {
+		return fail(404, "Gear "+body.str("gear_id")+" not found")
+	}
+
+	targets := []interface{}{}
+	matched := []interface{}{}
+	notMatched := []interface{}{}
+	for _, target := range body.list("targets") {
+		ref := asDocument(target)
+		if container, exists := s.store.containers[ref.str("type")+"s"][ref.str("id")]; exists {
+			targets = append(targets, target)
+			matched = append(matched, container)
+		} else {
+			notMatched = append(notMatched, target)
+		}
+	}
+
+	config := body.object("config")
+	tags := body.list("tags")
+	if tags == nil {
+		tags = []interface{}{}
+	}
+
+	id := s.store.newId()
+	batch := document{
+		"_id":      id,
+		"gear_id":  body.str("gear_id"),
+		"config":   copyDocument(config),
+		"tags":     copyValue(tags),
+		"targets":  copyValue(targets),
+		"state":    "pending",
+		"origin":   document{"type": "user", "id": UserId},
+		"jobs":     []interface{}{},
+		"created":  now(),
+		"modified": now(),
+	}
+	s.store.batches[id] = batch
+
+	proposal := copyDocument(batch)
+	proposal["matched"] = matched
+	proposal["not_matched"] = notMatched
+	proposal["ambiguous"] = []interface{}{}
+	proposal["improper_permissions"] = []interface{}{}
+	return ok(proposal)
+}
@@ -0,0 +1,290 @@
+package fake
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// filterOperators are the comparisons a filter condition can use, longest first so that "<=" is not read as "<".
+var filterOperators = []string{"=~", "!=", "<=", ">=", "=", "<", ">"}
+
+// condition is one clause of a listing's filter parameter, such as "tags=reviewed".
+type condition struct {
+	field    string
+	operator string
+	value    string
+	pattern  *regexp.Regexp
+}
+
+// page applies a listing's filter, sort, after_id and limit parameters.
+// Listings are otherwise sorted by ID, as for the real API.
+func page(req *request, docs []document) ([]document, *response) {
+	query := req.URL.Query()
+
+	if filter := query.Get("filter"); filter != "" {
+		var filtered []document
+		conditions, failure := parseFilter(filter)
+		if failure != nil {
+			return nil, failure
+		}
+		for _, doc := range docs {
+			if matchesAll(doc, conditions) {
+				filtered = append(filtered, doc)
+			}
+		}
+		docs = filtered
+	}
+
+	if order := query.Get("sort"); order != "" {
+		failure := sortDocuments(docs, order)
+		if failure != nil {
+			return nil, failure
+		}
+	}
+
+	if afterId := query.Get("after_id"); afterId != "" {
+		var after []document
+		for _, doc := range docs {
+			if doc.str("_id") > afterId {
+				after = append(after, doc)
+			}
+		}
+		docs = after
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return nil, badRequest("Invalid limit " + strconv.Quote(limit))
+		}
+		if len(docs) > n {
+			docs = docs[:n]
+		}
+	}
+
+	if docs == nil {
+		docs = []document{}
+	}
+	return docs, nil
+}
+
+// listed pages a listing, then converts it to the form it is sent in and applies the fields parameter.
+func listed(req *request, docs []document, view func([]document) []document) *response {
+	docs, failure := page(req, docs)
+	if failure != nil {
+		return failure
+	}
+	if view != nil {
+		docs = view(docs)
+	}
+	return ok(project(req, docs))
+}
+
+// project applies a listing's fields parameter, keeping only the named fields of each document, and its ID.
+func project(req *request, docs []document) []document {
+	fields := req.URL.Query().Get("fields")
+	if fields == "" {
+		return docs
+	}
+
+	result := make([]document, 0, len(docs))
+	for _, doc := range docs {
+		projected := document{"_id": doc["_id"]}
+		for _, field := range strings.Split(fields, ",") {
+			value, exists := lookup(doc, field)
+			if !exists {
+				continue
+			}
+
+			path := strings.Split(field, ".")
+			target := projected
+			for _, key := range path[:len(path)-1] {
+				target = target.object(key)
+			}
+			target[path[len(path)-1]] = copyValue(value)
+		}
+		result = append(result, projected)
+	}
+	return result
+}
+
+func parseFilter(filter string) ([]*condition, *response) {
+	var conditions []*condition
+
+	for _, clause := range strings.Split(filter, ",") {
+		start := strings.IndexAny(clause, "=!<>")
+		if start <= 0 {
+			return nil, badRequest("Invalid filter " + strconv.Quote(clause))
+		}
+
+		c := &condition{field: clause[:start]}
+		for _, operator := range filterOperators {
+			if strings.HasPrefix(clause[start:], operator) {
+				c.operator = operator
+				c.value = clause[start+len(operator):]
+				break
+			}
+		}
+		if c.operator == "" {
+			return nil, badRequest("Invalid filter " + strconv.Quote(clause))
+		}
+
+		if c.operator == "=~" {
+			pattern, err := regexp.Compile(c.value)
+			if err != nil {
+				return nil, badRequest("Invalid filter " + strconv.Quote(clause) + ": " + err.Error())
+			}
+			c.pattern = pattern
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+func matchesAll(doc document, conditions []*condition) bool {
+	for _, c := range conditions {
+		value, _ := lookup(doc, c.field)
+		if !c.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches compares a field's value with the condition. As with Mongo, a condition on a list matches any item of it.
+func (c *condition) matches(value interface{}) bool {
+	if list, isList := value.([]interface{}); isList {
+		if c.operator == "!=" {
+			for _, item := range list {
+				if !c.matches(item) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, item := range list {
+			if c.matches(item) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if value == nil {
+		return c.operator == "!="
+	}
+	if c.operator == "=~" {
+		return c.pattern.MatchString(fmt.Sprint(value))
+	}
+
+	order := compareValues(value, c.value)
+	switch c.operator {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+	return false
+}
+
+// compareValues compares a JSON value with a string: numerically for numbers, otherwise as text.
+// Timestamps are stored in one format, so they compare as text too.
+func compareValues(value interface{}, other interface{}) int {
+	a, aIsNumber := value.(float64)
+	b, bIsNumber := other.(float64)
+	if s, isString := other.(string); isString && aIsNumber {
+		parsed, err := strconv.ParseFloat(s, 64)
+		b, bIsNumber = parsed, err == nil
+	}
+
+	if aIsNumber && bIsNumber {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(value), fmt.Sprint(other))
+}
+
+// sortDocuments orders a listing by the sort parameter, such as "label,created:desc". Missing fields sort first.
+func sortDocuments(docs []document, order string) *response {
+	type key struct {
+		field      string
+		descending bool
+	}
+	var keys []key
+
+	for _, clause := range strings.Split(order, ",") {
+		k := key{field: clause}
+		if i := strings.LastIndex(clause, ":"); i >= 0 {
+			k.field = clause[:i]
+			switch clause[i+1:] {
+			case "asc":
+			case "desc":
+				k.descending = true
+			default:
+				return badRequest("Invalid sort " + strconv.Quote(clause))
+			}
+		}
+		keys = append(keys, k)
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, k := range keys {
+			a, _ := lookup(docs[i], k.field)
+			b, _ := lookup(docs[j], k.field)
+
+			var order int
+			switch {
+			case a == nil && b == nil:
+			case a == nil:
+				order = -1
+			case b == nil:
+				order = 1
+			default:
+				order = compareValues(a, b)
+			}
+
+			if k.descending {
+				order = -order
+			}
+			if order != 0 {
+				return order < 0
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// lookup finds a field of a document by its dotted path, such as "subject.code".
+func lookup(doc document, field string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, key := range strings.Split(field, ".") {
+		object := asDocument(value)
+		if object == nil {
+			return nil, false
+		}
+		var exists bool
+		value, exists = object[key]
+		if !exists {
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,133 @@
+package fake
+
+// accessLevels ranks each access level.
+var accessLevels = map[string]int{
+	"ro":    1,
+	"rw":    2,
+	"admin": 3,
+}
+
+func forbidden(req *request) *response {
+	return fail(403, "user not authorized to perform a "+req.Method+" operation on the container.")
+}
+
+// allowed reports whether the request may use a container with the given access level.
+// The fake user is a site admin, so requests in root mode are always allowed.
+func (s *Server) allowed(req *request, t string, container document, level string) bool {
+	// As with the real API, anyone may read a group
+	if req.root || (t == "groups" && level == "ro") {
+		return true
+	}
+
+	// Analyses and collections without permissions take those of their parent
+	permissions := container.list("permissions")
+	if t == "analyses" {
+		parent := container.object("parent")
+		permissions = s.store.containers[parent.str("type")+"s"][parent.str("id")].list("permissions")
+	}
+
+	permission := findPermission(permissions, UserId)
+	return permission != nil && accessLevels[permission.str("access")] >= accessLevels[level]
+}
+
+// readable filters containers to those the request may read.
+func (s *Server) readable(req *request, t string, containers []document) []document {
+	result := []document{}
+	for _, container := range containers {
+		if s.allowed(req, t, container, "ro") {
+			result = append(result, container)
+		}
+	}
+	return result
+}
+
+// findPermission returns the permission of a user, or nil if they have none.
+func findPermission(permissions []interface{}, userId string) document {
+	for _, permission := range permissions {
+		if permission := asDocument(permission); permission.str("_id") == userId {
+			return permission
+		}
+	}
+	return nil
+}
+
+// routePermissions handles the permissions of a container: {type}/{id}/permissions and below.
+func (s *Server) routePermissions(req *request, t, id string, container document) *response {
+	permissions := container.list("permissions")
+
+	if req.is("POST", t, id, "permissions") {
+		userId := req.body.str("_id")
+		if _, exists := accessLevels[req.body.str("access")]; !exists || userId == "" {
+			return badRequest("A permission needs a user ID and an access level of ro, rw, or admin")
+		}
+		if findPermission(permissions, userId) != nil {
+			return fail(409, "User "+userId+" already has a permission")
+		}
+
+		container["permissions"] = append(permissions, document{"_id": userId, "access": req.body.str("access")})
+		s.propagatePermissions(t, container)
+		return modified(1)
+	}
+
+	if len(req.path) != 4 {
+		return notFound()
+	}
+	userId := req.path[3]
+	permission := findPermission(permissions, userId)
+	if permission == nil {
+		return notFound()
+	}
+
+	switch req.Method {
+	case "GET":
+		return ok(permission)
+
+	case "PUT":
+		access := req.body.str("access")
+		if _, exists := accessLevels[access]; !exists {
+			return badRequest("Access must be ro, rw, or admin")
+		}
+		permission["access"] = access
+
+	case "DELETE":
+		result := []interface{}{}
+		for _, other := range permissions {
+			if asDocument(other).str("_id") != userId {
+				result = append(result, other)
+			}
+		}
+		container["permissions"] = result
+
+	default:
+		return notFound()
+	}
+
+	s.propagatePermissions(t, container)
+	return modified(1)
+}
+
+// propagatePermissions copies the permissions of a project to its subjects, sessions and acquisitions, as the real API does.
+func (s *Server) propagatePermissions(t string, container document) {
+	container.touch()
+
+	if t == "projects" {
+		for _, subject := range s.store.containers["subjects"] {
+			if subject.str("project") == container.str("_id") {
+				subject["permissions"] = copyValue(container.list("permissions"))
+			}
+		}
+	}
+
+	childType, hasChildren := childTypes[t]
+	if t == "groups" || !hasChildren {
+		return
+	}
+
+	field := parentTypes[childType][1]
+	for _, child := range s.store.containers[childType] {
+		if child.str(field) == container.str("_id") {
+			child["permissions"] = copyValue(container.list("permissions"))
+			s.propagatePermissions(childType, child)
+		}
+	}
+}
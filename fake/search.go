@@ -0,0 +1,126 @@
+package fake
+
+import (
+	"strconv"
+	"strings"
+)
+
+// routeSearch answers searches by matching the search string against labels and file names.
+// Filters are ignored.
+func (s *Server) routeSearch(req *request) *response {
+	if !req.is("POST", "dataexplorer", "search") {
+		if req.is(req.Method, "dataexplorer", "search") {
+			return fail(405, "The method is not allowed for the requested URL.")
+		}
+		return notFound()
+	}
+	if req.body == nil {
+		return badRequest("A JSON body is required")
+	}
+
+	query := strings.ToLower(req.body.str("search_string"))
+
+	var results []document
+	switch returnType := req.body.str("return_type"); returnType {
+	case "session", "acquisition", "collection", "analysis":
+		for _, container := range sorted(s.store.containers[returnType+"s"]) {
+			if strings.Contains(strings.ToLower(container.str("label")), query) {
+				results = append(results, s.searchResult(returnType, container))
+			}
+		}
+
+	case "file":
+		for _, t := range containerTypes {
+			for _, container := range sorted(s.store.containers[t]) {
+				for _, file := range container.list("files") {
+					file := asDocument(file)
+					if !strings.Contains(strings.ToLower(file.str("name")), query) {
+						continue
+					}
+
+					result := s.searchResult(singular(t), container)
+					result["file"] = document{
+						"name":         file.str("name"),
+						"size":         file["size"],
+						"type":         file["type"],
+						"measurements": file["measurements"],
+						"created":      file.str("created"),
+					}
+					result["parent"] = document{"type": singular(t), "_id": container.str("_id")}
+					results = append(results, result)
+				}
+			}
+		}
+
+	default:
+		return badRequest("Invalid return type " + strconv.Quote(returnType))
+	}
+
+	if size := req.URL.Query().Get("size"); size != "" && size != "all" {
+		limit, err := strconv.Atoi(size)
+		if err != nil {
+			return badRequest("Invalid size " + strconv.Quote(size))
+		}
+		if len(results) > limit {
+			results = results[:limit]
+		}
+	}
+
+	// Simple searches return the results themselves, rather than search engine hits
+	if req.URL.Query().Get("simple") == "true" {
+		if results == nil {
+			results = []document{}
+		}
+		return ok(results)
+	}
+
+	hits := []document{}
+	for _, result := range results {
+		id := ""
+		for _, field := range []string{"file", "analysis", "acquisition", "session", "collection"} {
+			if hit, isSet := result[field].(document); isSet {
+				id = hit.str("_id") + hit.str("name")
+				break
+			}
+		}
+		hits = append(hits, document{"_id": id, "_source": result})
+	}
+	return ok(document{"results": hits})
+}
+
+// searchResult describes a container and its ancestors, as search results do.
+func (s *Server) searchResult(t string, container document) document {
+	result := document{
+		"permissions": container["permissions"],
+	}
+
+	summarize := func(t string, container document) {
+		summary := document{"_id": container.str("_id"), "label": container.str("label")}
+		for _, field := range []string{"created", "timestamp", "curator", "user"} {
+			if value, isSet := container[field]; isSet {
+				summary[field] = value
+			}
+		}
+		result[t] = summary
+	}
+	summarize(t, container)
+
+	if t == "session" {
+		result["subject"] = document{"code": container.object("subject").str("code")}
+	}
+	if t == "analysis" {
+		parent := container.object("parent")
+		result["parent"] = document{"type": parent.str("type"), "_id": parent.str("id")}
+	}
+
+	// Add each ancestor, such as the session and project of an acquisition
+	for parent, hasParent := parentTypes[t+"s"]; hasParent; parent, hasParent = parentTypes[parent[0]] {
+		container = s.store.containers[parent[0]][container.str(parent[1])]
+		if container == nil {
+			break
+		}
+		summarize(singular(parent[0]), container)
+	}
+
+	return result
+}
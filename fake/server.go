@@ -0,0 +1,324 @@
+// Package fake provides an in-process fake of the Flywheel API, for testing code that uses the SDK without a real site.
+//
+//	server := fake.NewServer()
+//	defer server.Close()
+//
+//	client := server.Client()
+//	id, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+//
+// The fake implements the endpoints the SDK uses with an in-memory store.
+// It authenticates every request as UserId, a site admin: as with the real API, permissions are enforced unless a request is in root mode.
+// It checks what it needs to behave plausibly, not everything the real API checks.
+package fake
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"flywheel.io/sdk/api"
+)
+
+// Key is the API key the fake accepts, without its host and port.
+const Key = "fake-key"
+
+// UserId is the ID of the user that the fake authenticates every request as.
+const UserId = "fake-user@example.com"
+
+// Server is a running fake. Its methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mutex sync.Mutex
+	store *store
+}
+
+// NewServer starts a fake with an empty store, apart from the current user.
+func NewServer() *Server {
+	s := &Server{
+		store: newStore(),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// ApiKey returns an API key for the fake, for use with api.NewApiKeyClient and the api.InsecureUsePlaintext option.
+func (s *Server) ApiKey() string {
+	return strings.TrimPrefix(s.URL, "http://") + ":" + Key
+}
+
+// Client returns a client of the fake. Any options given are applied after api.InsecureUsePlaintext.
+func (s *Server) Client(options ...api.ApiKeyClientOption) *api.Client {
+	options = append([]api.ApiKeyClientOption{api.InsecureUsePlaintext}, options...)
+	return api.NewApiKeyClient(s.ApiKey(), options...)
+}
+
+// request is a parsed API request.
+type request struct {
+	*http.Request
+
+	// Path segments after /api/
+	path []string
+
+	// Decoded JSON body, if any
+	body document
+	raw  []byte
+
+	// Multipart upload, if any
+	metadata []byte
+	uploads  []*upload
+
+	// Whether permissions are bypassed, by root mode or a download ticket
+	root bool
+}
+
+// upload is one file of a multipart upload.
+type upload struct {
+	name    string
+	content []byte
+}
+
+// response is the outcome of a request: a JSON document, raw content, or an error.
+type response struct {
+	status int
+	json   interface{}
+
+	content     []byte
+	contentType string
+	etag        string
+}
+
+func ok(v interface{}) *response {
+	return &response{status: 200, json: v}
+}
+
+func fail(status int, message string) *response {
+	return &response{status: status, json: map[string]interface{}{"status_code": status, "message": message}}
+}
+
+func notFound() *response {
+	return fail(404, "The resource could not be found.")
+}
+
+func badRequest(message string) *response {
+	return fail(400, message)
+}
+
+func modified(count int) *response {
+	return ok(map[string]interface{}{"modified": count})
+}
+
+func deleted(count int) *response {
+	return ok(map[string]interface{}{"deleted": count})
+}
+
+func created(id string) *response {
+	return ok(map[string]interface{}{"_id": id})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		writeResponse(w, notFound())
+		return
+	}
+
+	req := &request{
+		Request: r,
+		path:    strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/"), "/"),
+	}
+
+	// Bodies are read before locking the store, so that slow uploads do not hold up other requests
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		err := req.readMultipart()
+		if err != nil {
+			writeResponse(w, badRequest(err.Error()))
+			return
+		}
+	} else {
+		raw, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeResponse(w, badRequest(err.Error()))
+			return
+		}
+		req.raw = raw
+
+		if len(raw) > 0 {
+			var body interface{}
+			if json.Unmarshal(raw, &body) != nil {
+				writeResponse(w, badRequest("Invalid JSON"))
+				return
+			}
+			if m, isMap := body.(map[string]interface{}); isMap {
+				req.body = document(m)
+			}
+		}
+	}
+
+	// Responses are encoded while locked, as they may share state with the store, but written after
+	s.mutex.Lock()
+	var resp *response
+	if s.authorized(req) {
+		resp = s.route(req)
+	} else {
+		resp = fail(401, "Invalid credentials")
+	}
+	resp.encode()
+	s.mutex.Unlock()
+
+	// Successful reads carry an ETag, so that clients can revalidate what they have cached
+	if r.Method == "GET" && resp.status == 200 {
+		resp.etag = etagOf(resp.content)
+
+		if r.Header.Get("If-None-Match") == resp.etag {
+			w.Header().Set("ETag", resp.etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	writeResponse(w, resp)
+}
+
+// etagOf returns the ETag of response content.
+func etagOf(content []byte) string {
+	sum := sha1.Sum(content)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// readMultipart reads the metadata and files of an upload.
+func (req *request) readMultipart() error {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return err
+		}
+
+		if part.FormName() == "metadata" {
+			req.metadata = content
+		} else {
+			req.uploads = append(req.uploads, &upload{name: part.FileName(), content: content})
+		}
+	}
+}
+
+// authorized checks the request's API key, or its download ticket.
+func (s *Server) authorized(req *request) bool {
+	if req.Header.Get("Authorization") == "scitran-user "+Key {
+		req.root = req.URL.Query().Get("root") == "true"
+		s.store.users[UserId].object("api_key")["last_used"] = now()
+		return true
+	}
+
+	ticket := req.URL.Query().Get("ticket")
+	req.root = ticket != "" && s.store.tickets[ticket] == req.URL.Path
+	return req.root
+}
+
+// encode converts a JSON response to content.
+func (resp *response) encode() {
+	if resp.content != nil {
+		return
+	}
+
+	raw, err := json.Marshal(resp.json)
+	if err != nil {
+		resp.status = 500
+		raw, _ = json.Marshal(fail(500, err.Error()).json)
+	}
+
+	resp.content = raw
+	resp.contentType = "application/json; charset=utf-8"
+}
+
+func writeResponse(w http.ResponseWriter, resp *response) {
+	resp.encode()
+
+	w.Header().Set("Content-Type", resp.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.content)))
+	if resp.etag != "" {
+		w.Header().Set("ETag", resp.etag)
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.content)
+}
+
+// route dispatches a request by its first path segment.
+func (s *Server) route(req *request) *response {
+	switch req.path[0] {
+	case "users":
+		return s.routeUsers(req)
+	case "groups", "projects", "subjects", "sessions", "acquisitions", "collections":
+		return s.routeContainers(req)
+	case "analyses":
+		return s.routeAnalyses(req)
+	case "jobs":
+		return s.routeJobs(req)
+	case "gears":
+		return s.routeGears(req)
+	case "batch":
+		return s.routeBatches(req)
+	case "dataexplorer":
+		return s.routeSearch(req)
+	case "config":
+		return s.routeConfig(req)
+	case "version":
+		return s.routeVersion(req)
+	}
+	return notFound()
+}
+
+// is reports whether a request has the given method and path shape. A "*" segment matches anything.
+func (req *request) is(method string, shape ...string) bool {
+	if req.Method != method || len(req.path) != len(shape) {
+		return false
+	}
+	for x, segment := range shape {
+		if segment != "*" && segment != req.path[x] {
+			return false
+		}
+	}
+	return true
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func (s *Server) routeConfig(req *request) *response {
+	if !req.is("GET", "config") {
+		return notFound()
+	}
+	return ok(document{
+		"auth":     document{"api-key": document{}},
+		"site":     document{"id": "local", "name": "Fake"},
+		"created":  s.store.started,
+		"modified": s.store.started,
+	})
+}
+
+func (s *Server) routeVersion(req *request) *response {
+	if !req.is("GET", "version") {
+		return notFound()
+	}
+	return ok(document{"database": 45})
+}
@@ -0,0 +1,158 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// document is a stored object, kept in the same JSON form the API sends.
+type document map[string]interface{}
+
+// store holds everything the fake knows. Callers hold the server's mutex.
+type store struct {
+	started string
+	lastId  int
+
+	users map[string]document
+
+	// Containers by type, as named in URLs ("projects"), then ID
+	containers map[string]map[string]document
+
+	// File contents by URL path, such as /api/projects/<id>/files/<name>
+	files map[string][]byte
+
+	// URL paths by download ticket
+	tickets map[string]string
+
+	jobs    map[string]document
+	jobLogs map[string][]interface{}
+	gears   map[string]document
+	batches map[string]document
+}
+
+var containerTypes = []string{"groups", "projects", "subjects", "sessions", "acquisitions", "collections", "analyses"}
+
+func newStore() *store {
+	st := &store{
+		started:    now(),
+		users:      map[string]document{},
+		containers: map[string]map[string]document{},
+		files:      map[string][]byte{},
+		tickets:    map[string]string{},
+		jobs:       map[string]document{},
+		jobLogs:    map[string][]interface{}{},
+		gears:      map[string]document{},
+		batches:    map[string]document{},
+	}
+
+	for _, t := range containerTypes {
+		st.containers[t] = map[string]document{}
+	}
+
+	st.users[UserId] = document{
+		"_id":       UserId,
+		"email":     UserId,
+		"firstname": "Fake",
+		"lastname":  "User",
+		"root":      true,
+		"api_key":   document{"key": Key, "created": st.started},
+		"created":   st.started,
+		"modified":  st.started,
+	}
+
+	return st
+}
+
+// newId returns a unique ID in the style of a Mongo ObjectId.
+func (st *store) newId() string {
+	st.lastId++
+	return fmt.Sprintf("%024x", st.lastId)
+}
+
+// copyValue deep-copies a JSON value, so that responses do not share state with the store.
+func copyValue(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var result interface{}
+	err = json.Unmarshal(raw, &result)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// copyDocument deep-copies a JSON object.
+func copyDocument(v interface{}) document {
+	return asDocument(copyValue(v))
+}
+
+// sorted returns the documents of a map in ID order, which matches creation order for generated IDs.
+func sorted(docs map[string]document) []document {
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := make([]document, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, docs[id])
+	}
+	return result
+}
+
+// asDocument converts a decoded JSON object to a document, returning nil for anything else.
+func asDocument(v interface{}) document {
+	switch d := v.(type) {
+	case document:
+		return d
+	case map[string]interface{}:
+		return document(d)
+	}
+	return nil
+}
+
+// str returns a string field of a document, or "" if missing.
+func (d document) str(key string) string {
+	s, _ := d[key].(string)
+	return s
+}
+
+// list returns an array field of a document, or nil if missing.
+func (d document) list(key string) []interface{} {
+	l, _ := d[key].([]interface{})
+	return l
+}
+
+// object returns an object field of a document, creating it if missing.
+func (d document) object(key string) document {
+	o := asDocument(d[key])
+	if o == nil {
+		o = document{}
+	}
+	d[key] = o
+	return o
+}
+
+// merge copies fields onto a document, replacing any existing values.
+func (d document) merge(fields document) {
+	for key, value := range fields {
+		d[key] = value
+	}
+}
+
+func (d document) touch() {
+	d["modified"] = now()
+}
+
+func containsString(list []interface{}, value string) bool {
+	for _, x := range list {
+		if s, _ := x.(string); s == value {
+			return true
+		}
+	}
+	return false
+}
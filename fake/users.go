@@ -0,0 +1,67 @@
+package fake
+
+func (s *Server) routeUsers(req *request) *response {
+	users := s.store.users
+
+	switch {
+	case req.is("GET", "users"):
+		return listed(req, sorted(users), func(listing []document) []document {
+			result := []document{}
+			for _, user := range listing {
+				result = append(result, withoutApiKey(user))
+			}
+			return result
+		})
+
+	case req.is("GET", "users", "self"):
+		return ok(users[UserId])
+
+	case req.is("GET", "users", "*"):
+		user, exists := users[req.path[1]]
+		if !exists {
+			return notFound()
+		}
+		return ok(withoutApiKey(user))
+
+	case req.is("POST", "users"):
+		id := req.body.str("_id")
+		if id == "" {
+			return badRequest("User ID is required")
+		}
+		if _, exists := users[id]; exists {
+			return fail(409, "User "+id+" already exists")
+		}
+
+		user := copyDocument(req.body)
+		user["created"] = now()
+		user.touch()
+		users[id] = user
+		return created(id)
+
+	case req.is("PUT", "users", "*"):
+		user, exists := users[req.path[1]]
+		if !exists {
+			return notFound()
+		}
+		delete(req.body, "_id")
+		user.merge(req.body)
+		user.touch()
+		return modified(1)
+
+	case req.is("DELETE", "users", "*"):
+		if _, exists := users[req.path[1]]; !exists || req.path[1] == UserId {
+			return notFound()
+		}
+		delete(users, req.path[1])
+		return deleted(1)
+	}
+
+	return notFound()
+}
+
+// withoutApiKey returns a user without their API key, which only the user themselves may see.
+func withoutApiKey(user document) document {
+	user = copyDocument(user)
+	delete(user, "api_key")
+	return user
+}
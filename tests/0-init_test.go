@@ -26,12 +26,12 @@ import (
 	> which uses t.Parallel() under the hood for every test case. - @mdwhatcott
 	> https://github.com/smartystreets/goconvey/issues/360
 
-	For goal #4, my plan is to incorporate go-vcr:
+	For goal #4, the SDK ships a go-vcr style transport (api.ReplayTransport), inspired by:
 	https://github.com/dnaeon/go-vcr
 
-	The implementation throws requests in YAML files, which... eh, let's try it maybe.
-	There will have to be some setup trickery to transparently hit live or recorded.
-	I think the vcr transport should handle that.
+	Setting SdkReplay in the environment records or replays the clients from makeClient, which talk to the API under test.
+	Tests that run their own servers, such as with httptest or the fake package, are left alone.
+	Tests that send random names still replay, as requests are matched by method and URL.
 
 
 	Test requirements:
@@ -108,8 +108,6 @@ const (
 
 // makeClient reads settings from the environment and returns the corresponding client
 func makeClient(root bool) *api.Client {
-	var client *api.Client
-
 	key, keySet := os.LookupEnv(SdkTestKey)
 	protocol, protocolSet := os.LookupEnv(SdkProtocolKey)
 
@@ -121,19 +119,21 @@ func makeClient(root bool) *api.Client {
 		protocol = DefaultProtocol
 	}
 
-	opts := []api.ApiKeyClientOption{api.InsecureNoSSLVerification}
-
-	if protocol == "http" {
-		opts = append(opts, api.InsecureUsePlaintext)
-	} else if protocol != "https" {
+	if protocol != "http" && protocol != "https" {
 		panic("Protocol must be http or https, was " + protocol)
 	}
 
-	if root {
-		opts = append(opts, api.EnableRoot)
+	profile := &api.Profile{
+		Key:                  key,
+		Root:                 root,
+		InsecureSkipVerify:   true,
+		InsecureUsePlaintext: protocol == "http",
 	}
 
-	client = api.NewApiKeyClient(key, opts...)
+	client, err := api.NewClientFromProfile(profile, api.RecordReplayFromEnv())
+	if err != nil {
+		panic(err)
+	}
 
 	return client
 }
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+// authServer accepts requests bearing the given Authorization header, and echoes that header as the user ID.
+func authServer(hits *int32, accept string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+
+		auth := r.Header.Get("Authorization")
+		if auth != accept {
+			w.WriteHeader(401)
+			w.Write([]byte(`{"status_code": 401, "message": "Invalid token"}`))
+			return
+		}
+		w.Write([]byte(`{"_id": "` + auth + `"}`))
+	}))
+}
+
+// countingTokenSource returns a new bearer token each time it is called.
+func countingTokenSource(calls *int32, lifetime time.Duration) api.TokenSource {
+	return api.TokenSourceFunc(func() (*api.Token, error) {
+		count := atomic.AddInt32(calls, 1)
+		token := &api.Token{Scheme: "Bearer", Value: "token-" + strconv.Itoa(int(count))}
+
+		if lifetime != 0 {
+			token.Expiry = time.Now().Add(lifetime)
+		}
+		return token, nil
+	})
+}
+
+func (t *F) TestAuthApiKey() {
+	var hits int32
+	server := authServer(&hits, "scitran-user change-me")
+	defer server.Close()
+
+	user, _, err := makeServerClient(server).GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "scitran-user change-me")
+
+	source, err := api.ApiKeyTokenSource("hostname.example:my-key")
+	t.So(err, ShouldBeNil)
+	token, _ := source.Token()
+	t.So(token.Header(), ShouldEqual, "scitran-user my-key")
+
+	_, err = api.ApiKeyTokenSource("bad-format")
+	t.So(err, ShouldNotBeNil)
+}
+
+func (t *F) TestAuthTokenSources() {
+	var hits int32
+	server := authServer(&hits, "Bearer access-token")
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	client := api.NewTokenClient(host, api.BearerTokenSource("access-token"), api.InsecureUsePlaintext)
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "Bearer access-token")
+
+	// A source option replaces the API key
+	client = makeServerClient(server, api.Authenticate(api.BearerTokenSource("access-token")))
+	_, _, err = client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+
+	// Rejected static tokens are not retried
+	atomic.StoreInt32(&hits, 0)
+	client = makeServerClient(server, api.Authenticate(api.BearerTokenSource("wrong-token")))
+	_, _, err = client.GetCurrentUser()
+	t.So(api.IsUnauthorized(err), ShouldBeTrue)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 1)
+
+	t.So(func() { api.NewTokenClient("hostname.example:not-a-port", api.BearerTokenSource("x")) }, ShouldPanic)
+}
+
+func (t *F) TestAuthRefresh() {
+	var hits, calls int32
+	server := authServer(&hits, "Bearer token-2")
+	defer server.Close()
+
+	// The first token is rejected, so it is replaced and the request sent again
+	source := api.ReuseTokenSource(countingTokenSource(&calls, 0))
+	client := makeServerClient(server, api.Authenticate(source))
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "Bearer token-2")
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+
+	// The refreshed token is reused
+	_, _, err = client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(atomic.LoadInt32(&calls), ShouldEqual, 2)
+
+	// Only one retry is made
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&calls, 5)
+	source = api.ReuseTokenSource(countingTokenSource(&calls, 0))
+	client = makeServerClient(server, api.Authenticate(source))
+	_, _, err = client.GetCurrentUser()
+	t.So(api.IsUnauthorized(err), ShouldBeTrue)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+
+	// Expired tokens are replaced before use
+	atomic.StoreInt32(&calls, 0)
+	source = api.ReuseTokenSource(countingTokenSource(&calls, time.Second))
+	first, _ := source.Token()
+	second, _ := source.Token()
+	t.So(first.Valid(), ShouldBeFalse)
+	t.So(second.Value, ShouldEqual, "token-2")
+}
+
+// rotatingTokenSource is a caching source of its own, which moves to the next token when told one was rejected.
+type rotatingTokenSource struct {
+	mutex sync.Mutex
+	count int
+	token *api.Token
+}
+
+func (s *rotatingTokenSource) Token() (*api.Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.token == nil {
+		s.count++
+		s.token = &api.Token{Scheme: "Bearer", Value: "token-" + strconv.Itoa(s.count)}
+	}
+	return s.token, nil
+}
+
+func (s *rotatingTokenSource) Invalidate(token *api.Token) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+func (t *F) TestAuthInvalidator() {
+	var hits int32
+	server := authServer(&hits, "Bearer token-2")
+	defer server.Close()
+
+	// Any source that can be told a token was rejected gets to refresh it
+	source := &rotatingTokenSource{}
+	user, _, err := makeServerClient(server, api.Authenticate(source)).GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "Bearer token-2")
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+
+	// A body that cannot be recreated for the second attempt fails the request
+	gone := errors.New("Body is gone")
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader("{}"))
+	t.So(err, ShouldBeNil)
+	req.GetBody = func() (io.ReadCloser, error) { return nil, gone }
+	transport := &api.AuthTransport{Source: &rotatingTokenSource{}}
+	_, err = transport.RoundTrip(req)
+	t.So(err, ShouldEqual, gone)
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestBulk() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	_, acquisitionIds := makeHierarchy(t, client)
+
+	var targets []*api.ContainerReference
+	for _, id := range acquisitionIds {
+		targets = append(targets, client.Container(api.AcquisitionContainer, id).Reference())
+	}
+	missing := &api.ContainerReference{Type: "acquisition", Id: "000000000000000000000000"}
+	targets = append(targets, missing)
+
+	_, err := client.AddAcquisitionTag(acquisitionIds[0], "reviewed")
+	t.So(err, ShouldBeNil)
+
+	// Every item runs; those already tagged are skipped, and the missing one fails
+	report, err := client.Bulk(targets, api.BulkAddTag("reviewed"), &api.BulkOptions{Workers: 4})
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldStartWith, "1 of 9 items failed")
+	t.So(report.Results, ShouldHaveLength, 9)
+	t.So(report.Count(api.BulkSucceeded), ShouldEqual, 7)
+	t.So(report.Count(api.BulkSkipped), ShouldEqual, 1)
+	t.So(report.Results[0].Status, ShouldEqual, api.BulkSkipped)
+	t.So(report.Results[8].Status, ShouldEqual, api.BulkFailed)
+	t.So(api.IsNotFound(report.Results[8].Err), ShouldBeTrue)
+	t.So(report.FailedTargets(), ShouldResemble, []*api.ContainerReference{missing})
+
+	for _, id := range acquisitionIds {
+		acquisition, _, err := client.GetAcquisition(id)
+		t.So(err, ShouldBeNil)
+		t.So(acquisition.Tags, ShouldResemble, []string{"reviewed"})
+	}
+
+	// Reports survive serialization, with their errors, and failures can be run again
+	raw, err := json.Marshal(report)
+	t.So(err, ShouldBeNil)
+	var saved api.BulkReport
+	t.So(json.Unmarshal(raw, &saved), ShouldBeNil)
+	t.So(saved.Results, ShouldHaveLength, 9)
+	t.So(api.IsNotFound(saved.Results[8].Err), ShouldBeTrue)
+	t.So(saved.Results[8].Err.Error(), ShouldEqual, report.Results[8].Err.Error())
+
+	report, err = client.Bulk(saved.FailedTargets(), func(container *api.Container) error {
+		return errors.New("still missing")
+	}, nil)
+	t.So(err, ShouldNotBeNil)
+	t.So(report.Results[0].Err.Error(), ShouldEqual, "still missing")
+	t.So(report.Results[0].StatusCode, ShouldEqual, 0)
+
+	// Info, across types
+	report, err = client.Bulk(targets[:2], api.BulkSetInfo(map[string]interface{}{"checked": true}), nil)
+	t.So(err, ShouldBeNil)
+	t.So(report.Count(api.BulkSucceeded), ShouldEqual, 2)
+	acquisition, _, err := client.GetAcquisition(acquisitionIds[1])
+	t.So(err, ShouldBeNil)
+	t.So(acquisition.Info["checked"], ShouldEqual, true)
+}
+
+func (t *F) TestBulkRetries() {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every other request is throttled
+		if atomic.AddInt32(&requests, 1)%2 == 1 {
+			w.WriteHeader(429)
+			w.Write([]byte(`{"message":"Slow down","status_code":429}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/tags") {
+			w.Write([]byte(`{"modified":1}`))
+		}
+	}))
+	defer server.Close()
+	client := makeServerClient(server)
+
+	targets := []*api.ContainerReference{{Type: "session", Id: "a"}, {Type: "session", Id: "b"}}
+	policy := &api.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}
+
+	report, err := client.Bulk(targets, api.BulkAddTag("reviewed"), &api.BulkOptions{Retry: policy})
+	t.So(err, ShouldBeNil)
+	t.So(report.Results[0].Attempts, ShouldEqual, 2)
+	t.So(report.Results[1].Attempts, ShouldEqual, 2)
+
+	// Without retries, throttling is a failure
+	report, err = client.Bulk(targets[:1], api.BulkAddTag("reviewed"), nil)
+	t.So(err, ShouldNotBeNil)
+	t.So(report.Results[0].StatusCode, ShouldEqual, 429)
+}
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+// requestCounter is a hook that counts the requests a client sends, and the responses it receives, by path.
+type requestCounter struct {
+	mutex    sync.Mutex
+	requests map[string]int
+	statuses map[int]int
+}
+
+func newRequestCounter() *requestCounter {
+	return &requestCounter{requests: map[string]int{}, statuses: map[int]int{}}
+}
+
+func (c *requestCounter) hook() api.Hook {
+	return api.StatsHook(func(stats *api.RequestStats) {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.requests[stats.Request.Method+" "+stats.Request.URL.Path]++
+		c.statuses[stats.StatusCode]++
+	})
+}
+
+func (c *requestCounter) count(request string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.requests[request]
+}
+
+func (c *requestCounter) status(code int) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.statuses[code]
+}
+
+func (t *F) TestCacheTransport() {
+	server := fake.NewServer()
+	defer server.Close()
+	counter := newRequestCounter()
+	client := server.Client(api.CacheResponses(api.CachePolicy{TTL: time.Hour}), api.AddHooks(counter.hook()))
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Before", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+
+	// Repeated reads are answered from the cache
+	for x := 0; x < 3; x++ {
+		project, _, err := client.GetProject(projectId)
+		t.So(err, ShouldBeNil)
+		t.So(project.Name, ShouldEqual, "Before")
+	}
+	t.So(counter.count("GET /api/projects/"+projectId), ShouldEqual, 1)
+
+	projects, _, err := client.GetAllProjects()
+	t.So(err, ShouldBeNil)
+	t.So(projects, ShouldHaveLength, 1)
+
+	// Writes invalidate the container and listings
+	_, err = client.ModifyProject(projectId, &api.Project{Name: "After"})
+	t.So(err, ShouldBeNil)
+	project, _, err := client.GetProject(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(project.Name, ShouldEqual, "After")
+
+	_, _, err = client.AddProject(&api.Project{Name: "Another", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+	projects, _, err = client.GetAllProjects()
+	t.So(err, ShouldBeNil)
+	t.So(projects, ShouldHaveLength, 2)
+
+	// Downloads are not cached
+	_, result := client.UploadToProject(projectId, UploadSourceFromString("yeats.txt", "The centre cannot hold"))
+	t.So(<-result, ShouldBeNil)
+	for x := 0; x < 2; x++ {
+		buffer, dest := DownloadSourceToBuffer()
+		_, result = client.DownloadFromProject(projectId, "yeats.txt", dest)
+		t.So(<-result, ShouldBeNil)
+		t.So(buffer.String(), ShouldEqual, "The centre cannot hold")
+	}
+	t.So(counter.count("GET /api/projects/"+projectId+"/files/yeats.txt"), ShouldEqual, 2)
+
+	_, err = client.DeleteProject(projectId)
+	t.So(err, ShouldBeNil)
+	_, _, err = client.GetProject(projectId)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+}
+
+func (t *F) TestCacheRevalidation() {
+	server := fake.NewServer()
+	defer server.Close()
+	counter := newRequestCounter()
+	client := server.Client(api.CacheResponses(api.CachePolicy{}), api.AddHooks(counter.hook()))
+
+	gearId, _, err := client.AddGear(&api.GearDoc{Gear: &api.Gear{Name: "test-gear", Version: "1"}})
+	t.So(err, ShouldBeNil)
+
+	// Without a TTL, every read is revalidated
+	for x := 0; x < 3; x++ {
+		gear, _, err := client.GetGear(gearId)
+		t.So(err, ShouldBeNil)
+		t.So(gear.Gear.Name, ShouldEqual, "test-gear")
+	}
+	t.So(counter.count("GET /api/gears/"+gearId), ShouldEqual, 3)
+	t.So(counter.status(http.StatusNotModified), ShouldEqual, 2)
+}
+
+func (t *F) TestCacheCollapsesRequests() {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"_id":"a-gear","gear":{"name":"test-gear"}}]`))
+	}))
+	defer server.Close()
+	client := makeServerClient(server, api.EnableCache)
+
+	var wg sync.WaitGroup
+	for x := 0; x < 5; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gears, _, err := client.GetAllGears()
+			t.So(err, ShouldBeNil)
+			t.So(gears, ShouldHaveLength, 1)
+		}()
+	}
+	wg.Wait()
+
+	t.So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+}
+
+func (t *F) TestMemoryCache() {
+	response := func(body string) *api.CachedResponse {
+		return &api.CachedResponse{StatusCode: 200, Body: []byte(body), Stored: time.Now()}
+	}
+
+	// The least recently used entry is evicted
+	cache := api.NewMemoryCache(2, 0)
+	cache.Set("a", response("a"))
+	cache.Set("b", response("b"))
+	_, found := cache.Get("a")
+	t.So(found, ShouldBeTrue)
+	cache.Set("c", response("c"))
+
+	_, found = cache.Get("b")
+	t.So(found, ShouldBeFalse)
+	t.So(cache.Len(), ShouldEqual, 2)
+
+	// As are entries over the byte limit
+	cache = api.NewMemoryCache(0, 10)
+	cache.Set("a", response("aaaaaa"))
+	cache.Set("b", response("bbbbbb"))
+	_, found = cache.Get("a")
+	t.So(found, ShouldBeFalse)
+	cache.Set("c", response("ccccccccccc"))
+	_, found = cache.Get("c")
+	t.So(found, ShouldBeFalse)
+	t.So(cache.Keys(), ShouldResemble, []string{"b"})
+}
+
+func (t *F) TestDiskCache() {
+	dir, err := ioutil.TempDir("", "sdk-cache")
+	t.So(err, ShouldBeNil)
+	defer os.RemoveAll(dir)
+
+	cache, err := api.NewDiskCache(dir)
+	t.So(err, ShouldBeNil)
+	cache.Set("key", &api.CachedResponse{StatusCode: 200, Header: http.Header{"Etag": {`"1"`}}, Body: []byte("{}"), Stored: time.Now()})
+
+	// Entries outlive the store
+	cache, err = api.NewDiskCache(dir)
+	t.So(err, ShouldBeNil)
+	t.So(cache.Keys(), ShouldResemble, []string{"key"})
+	response, found := cache.Get("key")
+	t.So(found, ShouldBeTrue)
+	t.So(string(response.Body), ShouldEqual, "{}")
+	t.So(response.Header.Get("ETag"), ShouldEqual, `"1"`)
+
+	cache.Delete("key")
+	_, found = cache.Get("key")
+	t.So(found, ShouldBeFalse)
+	t.So(cache.Keys(), ShouldBeEmpty)
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+// makeOldServer returns a server with the given database schema level and config features, which has no other routes.
+func makeOldServer(database int, features string, versionRequests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/version":
+			atomic.AddInt32(versionRequests, 1)
+			w.Write([]byte(`{"database":` + strconv.Itoa(database) + `}`))
+		case "/api/config":
+			w.Write([]byte(`{"auth":{},"site":{},"features":` + features + `}`))
+		default:
+			w.WriteHeader(404)
+			w.Write([]byte(`{"message":"The resource could not be found.","status_code":404}`))
+		}
+	}))
+}
+
+func (t *F) TestServerCapabilities() {
+	var versionRequests int32
+	server := makeOldServer(20, `{"batches":true,"analysis_jobs":false}`, &versionRequests)
+	defer server.Close()
+	client := makeServerClient(server)
+
+	info, err := client.GetServerInfo()
+	t.So(err, ShouldBeNil)
+	t.So(info.Version.Database, ShouldEqual, 20)
+
+	// Feature flags take precedence over the server's routes
+	t.So(info.Missing, ShouldResemble, []api.Capability{api.Batches, api.DataExplorerSearch})
+	t.So(info.Supports(api.DataExplorerSearch), ShouldBeFalse)
+	t.So(info.Supports(api.Batches), ShouldBeTrue)
+
+	supported, err := client.Supports(api.AnalysisJobs)
+	t.So(err, ShouldBeNil)
+	t.So(supported, ShouldBeFalse)
+
+	// Server info is cached, including by copies of the client
+	_, err = client.WithContext(context.Background()).GetServerInfo()
+	t.So(err, ShouldBeNil)
+	t.So(atomic.LoadInt32(&versionRequests), ShouldEqual, 1)
+
+	_, err = client.RefreshServerInfo()
+	t.So(err, ShouldBeNil)
+	t.So(atomic.LoadInt32(&versionRequests), ShouldEqual, 2)
+}
+
+func (t *F) TestUnsupportedError() {
+	var versionRequests int32
+	server := makeOldServer(20, `{"batches":true,"analysis_jobs":false}`, &versionRequests)
+	defer server.Close()
+	client := makeServerClient(server)
+
+	_, _, err := client.Search(&api.SearchQuery{ReturnType: api.SessionString})
+	t.So(api.IsUnsupported(err), ShouldBeTrue)
+	t.So(err.Error(), ShouldEqual, "Server does not support search (database schema level 20)")
+	t.So(err.(*api.UnsupportedError).Capability, ShouldEqual, api.DataExplorerSearch)
+
+	// The server's response is still available
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	_, _, err = client.AddSessionAnalysis("a-session", &api.Analysis{Name: "Analysis"}, &api.Job{})
+	t.So(api.IsUnsupported(err), ShouldBeTrue)
+
+	// Failures of supported capabilities are left alone
+	_, _, err = client.GetBatch("a-batch")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+	t.So(api.IsUnsupported(err), ShouldBeFalse)
+
+	// As are failures on servers that support everything
+	fakeServer := fake.NewServer()
+	defer fakeServer.Close()
+	_, _, err = fakeServer.Client().GetBatch("a-batch")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+	t.So(api.IsUnsupported(err), ShouldBeFalse)
+
+	// A capability without a route of its own is taken to be supported, unless the config says otherwise
+	info, err := fakeServer.Client().GetServerInfo()
+	t.So(err, ShouldBeNil)
+	t.So(info.Missing, ShouldBeEmpty)
+	t.So(info.Supports(api.AnalysisJobs), ShouldBeTrue)
+	t.So(info.Supports(api.DataExplorerSearch), ShouldBeTrue)
+	t.So(info.Supports(api.Batches), ShouldBeTrue)
+}
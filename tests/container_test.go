@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestContainerOperations() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Project", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+	sessionId, _, err := client.AddSession(&api.Session{Name: "Session", ProjectId: projectId})
+	t.So(err, ShouldBeNil)
+	session, _, err := client.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	acquisitionId, _, err := client.AddAcquisition(&api.Acquisition{Name: "Acquisition", SessionId: sessionId})
+	t.So(err, ShouldBeNil)
+	collectionId, _, err := client.AddCollection(&api.Collection{Name: "Collection"})
+	t.So(err, ShouldBeNil)
+	gearId, _, err := client.AddGear(&api.GearDoc{Gear: &api.Gear{Name: "test-gear", Version: "1"}})
+	t.So(err, ShouldBeNil)
+	analysisId, _, err := client.AddSessionAnalysis(sessionId, &api.Analysis{Name: "Analysis"}, &api.Job{GearId: gearId})
+	t.So(err, ShouldBeNil)
+
+	ids := map[api.ContainerType]string{
+		api.GroupContainer:       groupId,
+		api.ProjectContainer:     projectId,
+		api.SubjectContainer:     session.Subject.Id,
+		api.SessionContainer:     sessionId,
+		api.AcquisitionContainer: acquisitionId,
+		api.CollectionContainer:  collectionId,
+		api.AnalysisContainer:    analysisId,
+	}
+
+	// Every kind of container supports the same operations
+	for _, containerType := range api.ContainerTypes {
+		container := client.Container(containerType, ids[containerType])
+
+		_, err = container.AddTag("blue")
+		t.So(err, ShouldBeNil)
+		_, err = container.AddNote("A note")
+		t.So(err, ShouldBeNil)
+		_, err = container.SetInfo(map[string]interface{}{"a": 1, "b": 2})
+		t.So(err, ShouldBeNil)
+		_, err = container.DeleteInfoFields([]string{"a"})
+		t.So(err, ShouldBeNil)
+
+		_, result := container.Upload(UploadSourceFromString("yeats.txt", "Things fall apart"))
+		t.So(<-result, ShouldBeNil)
+		_, _, err = container.ModifyFile("yeats.txt", &api.FileFields{Modality: "MR"})
+		t.So(err, ShouldBeNil)
+		_, err = container.SetFileInfo("yeats.txt", map[string]interface{}{"poet": "Yeats"})
+		t.So(err, ShouldBeNil)
+
+		buffer, dest := DownloadSourceToBuffer()
+		_, result = container.Download("yeats.txt", dest)
+		t.So(<-result, ShouldBeNil)
+		t.So(buffer.String(), ShouldEqual, "Things fall apart")
+
+		var doc struct {
+			Tags  []string               `json:"tags"`
+			Notes []*api.Note            `json:"notes"`
+			Info  map[string]interface{} `json:"info"`
+			Files []*api.File            `json:"files"`
+		}
+		_, err = container.Get(&doc)
+		t.So(err, ShouldBeNil)
+		t.So(doc.Tags, ShouldResemble, []string{"blue"})
+		t.So(doc.Notes, ShouldHaveLength, 1)
+		t.So(doc.Info, ShouldResemble, map[string]interface{}{"b": 2.0})
+		t.So(doc.Files, ShouldHaveLength, 1)
+		t.So(doc.Files[0].Modality, ShouldEqual, "MR")
+
+		// Tags, notes and file tags can be changed and removed
+		_, err = container.RenameTag("blue", "green")
+		t.So(err, ShouldBeNil)
+		_, err = container.DeleteTag("green")
+		t.So(err, ShouldBeNil)
+		_, err = container.ModifyNote(doc.Notes[0].Id, "An edited note")
+		t.So(err, ShouldBeNil)
+		_, err = container.AddFileTag("yeats.txt", "poem")
+		t.So(err, ShouldBeNil)
+		_, err = container.RenameFileTag("yeats.txt", "poem", "poetry")
+		t.So(err, ShouldBeNil)
+		_, err = container.Get(&doc)
+		t.So(err, ShouldBeNil)
+		t.So(doc.Tags, ShouldBeEmpty)
+		t.So(doc.Notes[0].Text, ShouldEqual, "An edited note")
+		t.So(doc.Files[0].Tags, ShouldResemble, []string{"poetry"})
+
+		_, err = container.DeleteNote(doc.Notes[0].Id)
+		t.So(err, ShouldBeNil)
+		_, err = container.DeleteFileTag("yeats.txt", "poetry")
+		t.So(err, ShouldBeNil)
+		_, err = container.DeleteFile("yeats.txt")
+		t.So(err, ShouldBeNil)
+	}
+
+	// Per-type methods are the same operations
+	_, err = client.AddSessionTag(sessionId, "green")
+	t.So(err, ShouldBeNil)
+	session, _, err = client.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(session.Tags, ShouldResemble, []string{"green"})
+
+	ref := client.Container(api.AcquisitionContainer, acquisitionId).Reference()
+	t.So(ref.Type, ShouldEqual, "acquisition")
+	t.So(client.ContainerOf(ref).Path(), ShouldEqual, "acquisitions/"+acquisitionId)
+
+	_, err = client.Container(api.ProjectContainer, projectId).Modify(map[string]interface{}{"label": "Renamed"})
+	t.So(err, ShouldBeNil)
+	project, _, err := client.GetProject(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(project.Name, ShouldEqual, "Renamed")
+
+	_, err = client.Container(api.CollectionContainer, collectionId).Delete()
+	t.So(err, ShouldBeNil)
+	_, err = client.Container(api.AnalysisContainer, analysisId).Delete()
+	t.So(err, ShouldBeNil)
+	_, _, err = client.GetAnalysis(analysisId)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+}
+
+func (t *F) TestFileTagsPastCache() {
+	server := fake.NewServer()
+	defer server.Close()
+
+	// Cached reads must not hide another client's change
+	client := server.Client(api.CacheResponses(api.CachePolicy{TTL: time.Hour}))
+	other := server.Client()
+	_, acquisitionIds := makeHierarchy(t, client)
+	acquisition := client.Container(api.AcquisitionContainer, acquisitionIds[0])
+
+	_, err := acquisition.AddFileTag("yeats.txt", "poem")
+	t.So(err, ShouldBeNil)
+	_, _, err = client.GetAcquisition(acquisitionIds[0])
+	t.So(err, ShouldBeNil)
+	_, err = other.AddAcquisitionFileTag(acquisitionIds[0], "yeats.txt", "irish")
+	t.So(err, ShouldBeNil)
+	_, err = acquisition.AddFileTag("yeats.txt", "modern")
+	t.So(err, ShouldBeNil)
+
+	result, _, err := other.GetAcquisition(acquisitionIds[0])
+	t.So(err, ShouldBeNil)
+	t.So(result.Files[0].Tags, ShouldResemble, []string{"poem", "irish", "modern"})
+}
+
+func (t *F) TestFileTagsRace() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	other := server.Client()
+	_, acquisitionIds := makeHierarchy(t, client)
+
+	// A tag added by someone else between reading and writing the tags is kept
+	racing := server.Client(api.AddHooks(&interleave{
+		match: func(req *http.Request) bool { return req.Method == "PUT" },
+		run: func() {
+			_, err := other.AddAcquisitionFileTag(acquisitionIds[0], "yeats.txt", "irish")
+			t.So(err, ShouldBeNil)
+		},
+	}))
+	_, err := racing.AddAcquisitionFileTag(acquisitionIds[0], "yeats.txt", "poem")
+	t.So(err, ShouldBeNil)
+
+	result, _, err := client.GetAcquisition(acquisitionIds[0])
+	t.So(err, ShouldBeNil)
+	t.So(result.Files[0].Tags, ShouldResemble, []string{"irish", "poem"})
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+// stallingServer reads the request, writes prefix, then waits for the request to be abandoned.
+func stallingServer(prefix string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+
+		if prefix != "" {
+			w.Header().Set("Content-Length", "1000")
+			w.Write([]byte(prefix))
+			w.(http.Flusher).Flush()
+		}
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+}
+
+func (t *F) TestContextCancelsRequests() {
+	server := stallingServer("")
+	defer server.Close()
+
+	client := makeServerClient(server)
+	t.So(client.Context() == context.Background(), ShouldBeTrue)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	ctxClient := client.WithContext(ctx)
+	t.So(ctxClient.Context() == ctx, ShouldBeTrue)
+
+	_, _, err := ctxClient.GetAllSessions()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, context.DeadlineExceeded.Error())
+	t.So(time.Since(begin), ShouldBeLessThan, 5*time.Second)
+
+	// The original client is unaffected
+	t.So(client.Context() == context.Background(), ShouldBeTrue)
+}
+
+func (t *F) TestContextCancelsRetries() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(503)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	client := makeServerClient(server, api.EnableRetry).WithContext(ctx)
+	_, _, err := client.GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, context.DeadlineExceeded.Error())
+	t.So(time.Since(begin), ShouldBeLessThan, 5*time.Second)
+}
+
+func (t *F) TestContextCancelsDownloads() {
+	prefix := "That is no country for old men."
+	server := stallingServer(prefix)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := makeServerClient(server).WithContext(ctx)
+
+	buffer, dest := DownloadSourceToBuffer()
+	progress, result := client.DownloadSimple("projects/some-project/files/yeats.txt", dest)
+
+	// Cancel once the first bytes arrive; the progress channel should still close
+	t.So(<-progress, ShouldEqual, len(prefix))
+	cancel()
+	for range progress {
+	}
+
+	t.So(<-result == context.Canceled, ShouldBeTrue)
+	t.So(buffer.String(), ShouldEqual, prefix)
+}
+
+// endlessReader produces bytes forever.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func (t *F) TestContextCancelsUploads() {
+	server := stallingServer("")
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	client := makeServerClient(server).WithContext(ctx)
+
+	source := &api.UploadSource{Name: "endless.txt", Reader: ioutil.NopCloser(io.MultiReader(bytes.NewBufferString("The young in one another's arms, "), endlessReader{}))}
+	progress, result := client.UploadSimple("projects/some-project/files", nil, source)
+
+	for range progress {
+	}
+	t.So(<-result == context.DeadlineExceeded, ShouldBeTrue)
+}
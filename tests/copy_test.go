@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestCopy() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	groupId, acquisitionIds := makeHierarchy(t, client)
+
+	projects, _, err := client.GetGroupProjects(groupId)
+	t.So(err, ShouldBeNil)
+	sourceId := projects[0].Id
+
+	_, err = client.AddAcquisitionNote(acquisitionIds[0], "Motion in the second half")
+	t.So(err, ShouldBeNil)
+	_, err = client.SetAcquisitionInfo(acquisitionIds[0], map[string]interface{}{"quality": "poor"})
+	t.So(err, ShouldBeNil)
+	_, err = client.SetAcquisitionFileInfo(acquisitionIds[0], "yeats.txt", map[string]interface{}{"poet": "Yeats"})
+	t.So(err, ShouldBeNil)
+	_, err = client.AddAcquisitionFileTag(acquisitionIds[0], "yeats.txt", "poem")
+	t.So(err, ShouldBeNil)
+
+	// Copy only the tagged sessions
+	copyId, err := client.CopyProject(sourceId, groupId, &api.CopyOptions{
+		Label: "Teaching",
+		Include: func(node *api.WalkNode) bool {
+			return node.Type != api.SessionContainer || len(node.Session().Tags) > 0
+		},
+		Workers: 4,
+	})
+	t.So(err, ShouldBeNil)
+
+	project, _, err := client.GetProject(copyId)
+	t.So(err, ShouldBeNil)
+	t.So(project.Name, ShouldEqual, "Teaching")
+	sessions, _, err := client.GetProjectSessions(copyId)
+	t.So(err, ShouldBeNil)
+	t.So(sessions, ShouldHaveLength, 1)
+	t.So(sessions[0].Name, ShouldEqual, "p1s1")
+
+	session, _, err := client.GetSession(sessions[0].Id)
+	t.So(err, ShouldBeNil)
+	t.So(session.Tags, ShouldResemble, []string{"first"})
+	acquisitions, _, err := client.GetSessionAcquisitions(session.Id)
+	t.So(err, ShouldBeNil)
+	t.So(acquisitions, ShouldHaveLength, 2)
+
+	// Workers copy in any order
+	first := acquisitions[0]
+	if first.Name != "p1s1a1" {
+		first = acquisitions[1]
+	}
+	acquisition, _, err := client.GetAcquisition(first.Id)
+	t.So(err, ShouldBeNil)
+	t.So(acquisition.Name, ShouldEqual, "p1s1a1")
+	t.So(acquisition.Notes, ShouldHaveLength, 1)
+	t.So(acquisition.Notes[0].Text, ShouldEqual, "Motion in the second half")
+	t.So(acquisition.Info, ShouldResemble, map[string]interface{}{"quality": "poor"})
+	t.So(acquisition.Files, ShouldHaveLength, 1)
+	t.So(acquisition.Files[0].Info, ShouldResemble, map[string]interface{}{"poet": "Yeats"})
+	t.So(acquisition.Files[0].Tags, ShouldResemble, []string{"poem"})
+
+	buffer, dest := DownloadSourceToBuffer()
+	_, result := client.DownloadFromAcquisition(acquisition.Id, "yeats.txt", dest)
+	t.So(<-result, ShouldBeNil)
+	t.So(buffer.String(), ShouldEqual, "Things fall apart")
+
+	_, err = client.Copy(client.Container(api.GroupContainer, groupId), groupId, nil)
+	t.So(err, ShouldNotBeNil)
+}
+
+func (t *F) TestCopyResume() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	groupId, _ := makeHierarchy(t, client)
+
+	projects, _, err := client.GetGroupProjects(groupId)
+	t.So(err, ShouldBeNil)
+	sourceId := projects[0].Id
+
+	dir, err := ioutil.TempDir("", "sdk-copy")
+	t.So(err, ShouldBeNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "copy.log")
+
+	// Interrupt the copy when it reaches the second session
+	ctx, cancel := context.WithCancel(context.Background())
+	log, err := api.OpenCopyLog(path)
+	t.So(err, ShouldBeNil)
+	_, err = client.WithContext(ctx).CopyProject(sourceId, groupId, &api.CopyOptions{
+		Label: "Copy",
+		Include: func(node *api.WalkNode) bool {
+			if node.Type == api.SessionContainer && node.Session().Name == "p1s2" {
+				cancel()
+			}
+			return true
+		},
+		Log: log,
+	})
+	t.So(err, ShouldNotBeNil)
+	t.So(log.Close(), ShouldBeNil)
+
+	// A torn last line is ignored
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	t.So(err, ShouldBeNil)
+	_, err = file.WriteString(`{"source":"ab`)
+	t.So(err, ShouldBeNil)
+	t.So(file.Close(), ShouldBeNil)
+
+	// Resuming copies only what is missing
+	log, err = api.OpenCopyLog(path)
+	t.So(err, ShouldBeNil)
+	defer log.Close()
+	copyId, err := client.CopyProject(sourceId, groupId, &api.CopyOptions{Log: log})
+	t.So(err, ShouldBeNil)
+	copied, _ := log.CopyOf(sourceId)
+	t.So(copyId, ShouldEqual, copied)
+
+	projects, _, err = client.GetGroupProjects(groupId)
+	t.So(err, ShouldBeNil)
+	t.So(projects, ShouldHaveLength, 3)
+	sessions, _, err := client.GetProjectSessions(copyId)
+	t.So(err, ShouldBeNil)
+	t.So(sessions, ShouldHaveLength, 2)
+	for _, session := range sessions {
+		acquisitions, _, err := client.GetSessionAcquisitions(session.Id)
+		t.So(err, ShouldBeNil)
+		t.So(acquisitions, ShouldHaveLength, 2)
+		for _, acquisition := range acquisitions {
+			acquisition, _, err = client.GetAcquisition(acquisition.Id)
+			t.So(err, ShouldBeNil)
+			t.So(acquisition.Files, ShouldHaveLength, 1)
+		}
+	}
+}
+
+// failOnce fails the first request that matches, as if the connection dropped, and lets the rest through.
+type failOnce struct {
+	match func(req *http.Request) bool
+
+	mutex  sync.Mutex
+	failed bool
+}
+
+func (h *failOnce) Request(req *http.Request) *http.Request {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.failed || !h.match(req) {
+		return req
+	}
+	h.failed = true
+	ctx, cancel := context.WithCancel(req.Context())
+	cancel()
+	return req.WithContext(ctx)
+}
+
+func (h *failOnce) Response(req *http.Request, resp *http.Response, err error) {}
+func (h *failOnce) Done(stats *api.RequestStats)                               {}
+
+func (t *F) TestCopyResumeMidContainer() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	groupId, _ := makeHierarchy(t, client)
+
+	projects, _, err := client.GetGroupProjects(groupId)
+	t.So(err, ShouldBeNil)
+	sourceId := projects[0].Id
+	sessions, _, err := client.GetProjectSessions(sourceId)
+	t.So(err, ShouldBeNil)
+	for _, session := range sessions {
+		_, err = client.AddSessionNote(session.Id, "Reviewed")
+		t.So(err, ShouldBeNil)
+	}
+
+	// The first session's copy is made and tagged, but adding its note fails
+	failing := server.Client(api.AddHooks(&failOnce{match: func(req *http.Request) bool {
+		return req.Method == "POST" && strings.HasPrefix(req.URL.Path, "/api/sessions/") && strings.HasSuffix(req.URL.Path, "/notes")
+	}}))
+	log := api.NewCopyLog()
+	_, err = failing.CopyProject(sourceId, groupId, &api.CopyOptions{Label: "Copy", Log: log})
+	t.So(err, ShouldNotBeNil)
+
+	// Resuming finishes that session, rather than making another
+	copyId, err := client.CopyProject(sourceId, groupId, &api.CopyOptions{Label: "Copy", Log: log})
+	t.So(err, ShouldBeNil)
+	copies, _, err := client.GetProjectSessions(copyId)
+	t.So(err, ShouldBeNil)
+	t.So(copies, ShouldHaveLength, 2)
+	for _, session := range copies {
+		session, _, err = client.GetSession(session.Id)
+		t.So(err, ShouldBeNil)
+		t.So(session.Notes, ShouldHaveLength, 1)
+		if session.Name == "p1s1" {
+			t.So(session.Tags, ShouldResemble, []string{"first"})
+		}
+		acquisitions, _, err := client.GetSessionAcquisitions(session.Id)
+		t.So(err, ShouldBeNil)
+		t.So(acquisitions, ShouldHaveLength, 2)
+	}
+}
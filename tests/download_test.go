@@ -31,9 +31,14 @@ func (t *F) TestBadDownloads() {
 	progress, result = t.DownloadSimple("not-an-endpoint", source)
 	t.So(<-progress, ShouldEqual, 0)
 
-	// Could improve this in the future
 	err := <-result
-	t.So(err.Error(), ShouldMatchRegex, "\\{\"status_code\": 404, \"message\": \"The resource could not be found.\"\\, \"request_id\": \"[^\"]+\"}")
+	t.So(err.Error(), ShouldEqual, "(404) The resource could not be found.")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	aerr, ok := api.AsError(err)
+	t.So(ok, ShouldBeTrue)
+	t.So(aerr.Method, ShouldEqual, "GET")
+	t.So(aerr.Url, ShouldEndWith, "/api/not-an-endpoint")
 	t.So(buffer.String(), ShouldEqual, "")
 }
 
@@ -44,6 +49,8 @@ func (t *F) TestBadUrlDownload() {
 	t.So(url, ShouldEqual, "")
 	t.So(err, ShouldNotBeNil)
 	t.So(err.Error(), ShouldEqual, "(404) The resource could not be found.")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+	t.So(api.IsForbidden(err), ShouldBeFalse)
 }
 
 func (t *F) TestTruncatedDownloads() {
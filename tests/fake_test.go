@@ -0,0 +1,232 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestFakeContainers() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, fake.UserId)
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests", Name: "Unit Tests"})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+
+	projectId, _, err := client.AddProject(&api.Project{Name: "Project", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+	sessionId, _, err := client.AddSession(&api.Session{Name: "Session", ProjectId: projectId})
+	t.So(err, ShouldBeNil)
+	acquisitionId, _, err := client.AddAcquisition(&api.Acquisition{Name: "Acquisition", SessionId: sessionId})
+	t.So(err, ShouldBeNil)
+
+	// Parents must exist
+	_, _, err = client.AddSession(&api.Session{Name: "Orphan", ProjectId: "not-a-project"})
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	session, _, err := client.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(session.GroupId, ShouldEqual, groupId)
+	t.So(session.Subject, ShouldNotBeNil)
+	t.So(session.Subject.Id, ShouldNotBeEmpty)
+	t.So(session.Permissions, ShouldHaveLength, 1)
+	t.So(session.Permissions[0].Id, ShouldEqual, fake.UserId)
+
+	acquisitions, _, err := client.GetSessionAcquisitions(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(acquisitions, ShouldHaveLength, 1)
+	t.So(acquisitions[0].Id, ShouldEqual, acquisitionId)
+
+	// Info
+	_, err = client.SetAcquisitionInfo(acquisitionId, map[string]interface{}{"a": 1, "b": 2})
+	t.So(err, ShouldBeNil)
+	_, err = client.DeleteAcquisitionInfoFields(acquisitionId, []string{"a"})
+	t.So(err, ShouldBeNil)
+	acquisition, _, err := client.GetAcquisition(acquisitionId)
+	t.So(err, ShouldBeNil)
+	t.So(acquisition.Info, ShouldResemble, map[string]interface{}{"b": 2.0})
+
+	_, err = client.ReplaceAcquisitionInfo(acquisitionId, map[string]interface{}{"c": "d"})
+	t.So(err, ShouldBeNil)
+	acquisition, _, err = client.GetAcquisition(acquisitionId)
+	t.So(err, ShouldBeNil)
+	t.So(acquisition.Info, ShouldResemble, map[string]interface{}{"c": "d"})
+
+	// Tags and notes
+	_, err = client.AddAcquisitionTag(acquisitionId, "blue")
+	t.So(err, ShouldBeNil)
+	_, err = client.AddAcquisitionTag(acquisitionId, "blue")
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	_, err = client.AddAcquisitionNote(acquisitionId, "A note")
+	t.So(err, ShouldBeNil)
+	acquisition, _, err = client.GetAcquisition(acquisitionId)
+	t.So(err, ShouldBeNil)
+	t.So(acquisition.Tags, ShouldResemble, []string{"blue"})
+	t.So(acquisition.Notes, ShouldHaveLength, 1)
+	t.So(acquisition.Notes[0].Text, ShouldEqual, "A note")
+
+	// Deleting a project deletes its contents
+	_, err = client.DeleteProject(projectId)
+	t.So(err, ShouldBeNil)
+	_, _, err = client.GetAcquisition(acquisitionId)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	// Stores are not shared
+	other := fake.NewServer()
+	defer other.Close()
+	_, _, err = other.Client().GetGroup(groupId)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+}
+
+func (t *F) TestFakeFiles() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Project", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+
+	poem := "Turning and turning in the widening gyre"
+	_, result := client.UploadToProject(projectId, UploadSourceFromString("yeats.txt", poem))
+	t.So(<-result, ShouldBeNil)
+
+	project, _, err := client.GetProject(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(project.Files, ShouldHaveLength, 1)
+	t.So(project.Files[0].Name, ShouldEqual, "yeats.txt")
+	t.So(project.Files[0].Size, ShouldEqual, len(poem))
+	t.So(project.Files[0].Mimetype, ShouldEqual, "text/plain")
+
+	buffer, dest := DownloadSourceToBuffer()
+	_, result = client.DownloadFromProject(projectId, "yeats.txt", dest)
+	t.So(<-result, ShouldBeNil)
+	t.So(buffer.String(), ShouldEqual, poem)
+
+	// Tickets allow downloading without credentials
+	url, _, err := client.GetProjectDownloadUrl(projectId, "yeats.txt")
+	t.So(err, ShouldBeNil)
+	resp, err := http.Get(url)
+	t.So(err, ShouldBeNil)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	t.So(err, ShouldBeNil)
+	t.So(resp.StatusCode, ShouldEqual, 200)
+	t.So(string(body), ShouldEqual, poem)
+
+	// Attributes and info
+	_, modified, err := client.ModifyProjectFile(projectId, "yeats.txt", &api.FileFields{Modality: "MR"})
+	t.So(err, ShouldBeNil)
+	t.So(modified.ModifiedCount, ShouldEqual, 1)
+	_, err = client.SetProjectFileInfo(projectId, "yeats.txt", map[string]interface{}{"poet": "Yeats"})
+	t.So(err, ShouldBeNil)
+
+	project, _, err = client.GetProject(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(project.Files[0].Modality, ShouldEqual, "MR")
+	t.So(project.Files[0].Info, ShouldResemble, map[string]interface{}{"poet": "Yeats"})
+
+	_, err = client.DeleteProjectFile(projectId, "yeats.txt")
+	t.So(err, ShouldBeNil)
+	_, result = client.DownloadFromProject(projectId, "yeats.txt", dest)
+	t.So(api.IsNotFound(<-result), ShouldBeTrue)
+}
+
+func (t *F) TestFakeJobsAndSearch() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Project", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+	sessionId, _, err := client.AddSession(&api.Session{Name: "Findable", ProjectId: projectId})
+	t.So(err, ShouldBeNil)
+
+	gearId, _, err := client.AddGear(&api.GearDoc{Gear: &api.Gear{Name: "test-gear", Version: "1"}})
+	t.So(err, ShouldBeNil)
+
+	// Jobs are started in order, by tag
+	jobId, _, err := client.AddJob(&api.Job{GearId: gearId, Tags: []string{"queue"}})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.AddJob(&api.Job{GearId: "not-a-gear"})
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	retrieval, job, _, err := client.StartNextPendingJob("queue")
+	t.So(err, ShouldBeNil)
+	t.So(retrieval, ShouldEqual, api.JobAquired)
+	t.So(job.Id, ShouldEqual, jobId)
+	t.So(job.State, ShouldEqual, api.Running)
+
+	retrieval, _, _, err = client.StartNextPendingJob("queue")
+	t.So(err, ShouldBeNil)
+	t.So(retrieval, ShouldEqual, api.NoPendingJobs)
+
+	// Invalid state changes are rejected
+	_, err = client.ChangeJobState(jobId, api.Complete)
+	t.So(err, ShouldBeNil)
+	_, err = client.ChangeJobState(jobId, api.Running)
+	t.So(err, ShouldNotBeNil)
+
+	// Batches run a job for each target
+	targets := []*api.ContainerReference{{Id: sessionId, Type: "session"}}
+	proposal, _, err := client.ProposeBatch(gearId, nil, nil, targets)
+	t.So(err, ShouldBeNil)
+	t.So(proposal.Matched, ShouldHaveLength, 1)
+	jobs, _, err := client.StartBatch(proposal.Id)
+	t.So(err, ShouldBeNil)
+	t.So(jobs, ShouldHaveLength, 1)
+	t.So(jobs[0].Destination.Id, ShouldEqual, sessionId)
+	cancelled, _, err := client.CancelBatch(proposal.Id)
+	t.So(err, ShouldBeNil)
+	t.So(cancelled, ShouldEqual, 1)
+
+	// Search matches labels
+	results, _, err := client.Search(&api.SearchQuery{ReturnType: api.SessionString, SearchString: "findable"})
+	t.So(err, ShouldBeNil)
+	t.So(results, ShouldHaveLength, 1)
+	t.So(results[0].Session.Id, ShouldEqual, sessionId)
+	t.So(results[0].Project.Id, ShouldEqual, projectId)
+	t.So(results[0].Group.Id, ShouldEqual, groupId)
+
+	results, _, err = client.Search(&api.SearchQuery{ReturnType: api.SessionString, SearchString: "missing"})
+	t.So(err, ShouldBeNil)
+	t.So(results, ShouldBeEmpty)
+}
+
+func (t *F) TestFakeAuthentication() {
+	server := fake.NewServer()
+	defer server.Close()
+
+	address := server.URL[len("http://"):]
+	client := api.NewApiKeyClient(address+":wrong-key", api.InsecureUsePlaintext)
+
+	_, _, err := client.GetCurrentUser()
+	t.So(api.IsUnauthorized(err), ShouldBeTrue)
+
+	// Permissions are enforced unless in root mode
+	groupId, _, err := server.Client().AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	var aerr *api.Error
+	resp, err := server.Client().New().Delete("groups/"+groupId+"/permissions/"+fake.UserId).Receive(nil, &aerr)
+	t.So(api.CoalesceResponse(resp, err, aerr), ShouldBeNil)
+
+	_, _, err = server.Client().AddProject(&api.Project{Name: "Project", GroupId: groupId})
+	t.So(api.IsForbidden(err), ShouldBeTrue)
+
+	_, _, err = server.Client(api.EnableRoot).AddProject(&api.Project{Name: "Project", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+// recordingHook adds a trace header to each request, and records what it sees.
+type recordingHook struct {
+	mutex     sync.Mutex
+	responses []int
+	stats     []*api.RequestStats
+}
+
+func (h *recordingHook) Request(req *http.Request) *http.Request {
+	traced := new(http.Request)
+	*traced = *req
+	traced.Header = http.Header{}
+	for key, values := range req.Header {
+		traced.Header[key] = values
+	}
+	traced.Header.Set("X-Trace-Id", "trace-1")
+	return traced
+}
+
+func (h *recordingHook) Response(req *http.Request, resp *http.Response, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.responses = append(h.responses, resp.StatusCode)
+}
+
+func (h *recordingHook) Done(stats *api.RequestStats) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.stats = append(h.stats, stats)
+}
+
+// hookServer echoes the trace header as an ID, and serves binary files.
+func hookServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if strings.Contains(r.URL.Path, "/files/") {
+			w.Header().Set("Content-Length", "40000")
+			w.Write(bytes.Repeat([]byte{0, 1, 2, 3}, 10000))
+			return
+		}
+		if len(body) > 0 {
+			w.Write([]byte(`{"_id": "` + strings.Repeat("x", 5000) + `"}`))
+			return
+		}
+		w.Write([]byte(`{"_id": "` + r.Header.Get("X-Trace-Id") + `"}`))
+	}))
+}
+
+func (t *F) TestHooks() {
+	server := hookServer()
+	defer server.Close()
+
+	hook := &recordingHook{}
+	var count int
+	counter := api.StatsHook(func(stats *api.RequestStats) { count++ })
+
+	client := makeServerClient(server, api.AddHooks(hook), api.AddHooks(counter))
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "trace-1")
+
+	_, _, err = client.AddProject(&api.Project{Name: "Byzantium"})
+	t.So(err, ShouldBeNil)
+
+	t.So(count, ShouldEqual, 2)
+	t.So(hook.responses, ShouldResemble, []int{200, 200})
+	t.So(hook.stats, ShouldHaveLength, 2)
+
+	stats := hook.stats[0]
+	t.So(stats.StatusCode, ShouldEqual, 200)
+	t.So(stats.Err, ShouldBeNil)
+	t.So(stats.Request.Method, ShouldEqual, "GET")
+	t.So(stats.Request.Header.Get("X-Trace-Id"), ShouldEqual, "trace-1")
+	t.So(stats.BytesSent, ShouldEqual, 0)
+	t.So(stats.BytesReceived, ShouldEqual, len(`{"_id": "trace-1"}`))
+	t.So(stats.Duration, ShouldBeGreaterThanOrEqualTo, stats.Latency)
+
+	stats = hook.stats[1]
+	t.So(stats.Request.Method, ShouldEqual, "POST")
+	t.So(stats.BytesSent, ShouldBeGreaterThan, len("Byzantium"))
+	t.So(stats.BytesReceived, ShouldBeGreaterThan, 5000)
+
+	// Failed requests are done immediately
+	hook = &recordingHook{}
+	client = api.NewApiKeyClient("hostname.example:80:my-key", api.AddHooks(api.StatsHook(hook.Done)))
+	_, _, err = client.GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(hook.stats, ShouldHaveLength, 1)
+	t.So(hook.stats[0].Err, ShouldNotBeNil)
+	t.So(hook.stats[0].StatusCode, ShouldEqual, 0)
+}
+
+func (t *F) TestDebugHook() {
+	server := hookServer()
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	client := makeServerClient(server, api.DebugLogRequests(&buffer))
+
+	// Credentials are redacted
+	_, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	message := buffer.String()
+	t.So(message, ShouldContainSubstring, "Authorization: [redacted]")
+	t.So(message, ShouldNotContainSubstring, "change-me")
+	t.So(message, ShouldContainSubstring, `{"_id": ""}`)
+	t.So(message, ShouldContainSubstring, "END HTTP")
+
+	// Large bodies are truncated, but still received in full
+	buffer.Reset()
+	id, _, err := client.AddProject(&api.Project{Name: "Byzantium"})
+	t.So(err, ShouldBeNil)
+	t.So(id, ShouldHaveLength, 5000)
+	message = buffer.String()
+	t.So(message, ShouldContainSubstring, `"label":"Byzantium"`)
+	t.So(message, ShouldContainSubstring, "[body truncated]")
+	t.So(len(message), ShouldBeLessThan, api.DebugBodyLimit+2000)
+
+	// Binary bodies are omitted
+	buffer.Reset()
+	download, dest := DownloadSourceToBuffer()
+	_, result := client.DownloadSimple("projects/some-project/files/sailing.bin?ticket=secret-ticket", dest)
+	t.So(<-result, ShouldBeNil)
+	t.So(download.Len(), ShouldEqual, 40000)
+	message = buffer.String()
+	t.So(message, ShouldContainSubstring, "[binary body omitted]")
+	t.So(message, ShouldNotContainSubstring, "secret-ticket")
+}
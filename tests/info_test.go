@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestInfoPaths() {
+	info := map[string]interface{}{}
+	api.SetInfoPath(info, "qa.motion.fd_mean", 0.2)
+	api.SetInfoPath(info, "qa.snr", 40)
+	t.So(info, ShouldResemble, map[string]interface{}{
+		"qa": map[string]interface{}{"motion": map[string]interface{}{"fd_mean": 0.2}, "snr": 40},
+	})
+
+	value, exists := api.GetInfoPath(info, "qa.motion.fd_mean")
+	t.So(exists, ShouldBeTrue)
+	t.So(value, ShouldEqual, 0.2)
+	_, exists = api.GetInfoPath(info, "qa.snr.db")
+	t.So(exists, ShouldBeFalse)
+
+	t.So(api.DeleteInfoPath(info, "qa.motion"), ShouldBeTrue)
+	t.So(api.DeleteInfoPath(info, "qa.motion"), ShouldBeFalse)
+	t.So(info, ShouldResemble, map[string]interface{}{"qa": map[string]interface{}{"snr": 40}})
+}
+
+func (t *F) TestDiffInfo() {
+	current := map[string]interface{}{
+		"site": "Stanford",
+		"qa": map[string]interface{}{
+			"motion": map[string]interface{}{"fd_mean": 0.2, "fd_max": 1.5},
+			"snr":    40.0,
+		},
+		"legacy": true,
+	}
+
+	update, err := api.DiffInfo(current, map[string]interface{}{
+		"site": "Stanford",
+		"qa": map[string]interface{}{
+			"motion": map[string]interface{}{"fd_mean": 0.3, "fd_max": 1.5},
+			"snr":    40,
+		},
+		"reviewed": true,
+	})
+	t.So(err, ShouldBeNil)
+	t.So(update.Set, ShouldResemble, map[string]interface{}{"qa.motion.fd_mean": 0.3, "reviewed": true})
+	t.So(update.Delete, ShouldResemble, []string{"legacy"})
+	t.So(update.Replace, ShouldBeNil)
+
+	update, err = api.DiffInfo(current, current)
+	t.So(err, ShouldBeNil)
+	t.So(update.IsEmpty(), ShouldBeTrue)
+
+	// Keys with dots are set whole, one level up
+	update, err = api.DiffInfo(map[string]interface{}{"versions": map[string]interface{}{"1.0": "a"}}, map[string]interface{}{"versions": map[string]interface{}{"1.0": "b"}})
+	t.So(err, ShouldBeNil)
+	t.So(update.Set, ShouldResemble, map[string]interface{}{"versions": map[string]interface{}{"1.0": "b"}})
+	update, err = api.DiffInfo(map[string]interface{}{"1.0": "a"}, map[string]interface{}{})
+	t.So(err, ShouldBeNil)
+	t.So(update.Replace, ShouldResemble, map[string]interface{}{})
+}
+
+func (t *F) TestSyncInfo() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	_, acquisitionIds := makeHierarchy(t, client)
+	acquisitionId := acquisitionIds[0]
+
+	_, err := client.SetAcquisitionInfo(acquisitionId, map[string]interface{}{
+		"qa":   map[string]interface{}{"motion": map[string]interface{}{"fd_mean": 0.2, "fd_max": 1.5}},
+		"site": "Stanford",
+	})
+	t.So(err, ShouldBeNil)
+
+	// Dotted paths reach into nested objects
+	acquisition := client.Container(api.AcquisitionContainer, acquisitionId)
+	_, err = acquisition.SetInfoPath("qa.motion.fd_mean", 0.3)
+	t.So(err, ShouldBeNil)
+	_, err = acquisition.DeleteInfoPath("qa.motion.fd_max")
+	t.So(err, ShouldBeNil)
+	value, exists, _, err := acquisition.GetInfoPath("qa.motion.fd_mean")
+	t.So(err, ShouldBeNil)
+	t.So(exists, ShouldBeTrue)
+	t.So(value, ShouldEqual, 0.3)
+	_, exists, _, err = acquisition.GetInfoPath("qa.motion.fd_max")
+	t.So(err, ShouldBeNil)
+	t.So(exists, ShouldBeFalse)
+
+	// Sync sends only the difference
+	desired := map[string]interface{}{
+		"qa":       map[string]interface{}{"motion": map[string]interface{}{"fd_mean": 0.3}, "snr": 40},
+		"reviewed": true,
+	}
+	update, _, err := client.SyncAcquisitionInfo(acquisitionId, desired)
+	t.So(err, ShouldBeNil)
+	t.So(update.Set, ShouldResemble, map[string]interface{}{"qa.snr": 40.0, "reviewed": true})
+	t.So(update.Delete, ShouldResemble, []string{"site"})
+
+	result, _, err := client.GetAcquisition(acquisitionId)
+	t.So(err, ShouldBeNil)
+	t.So(result.Info, ShouldResemble, map[string]interface{}{
+		"qa":       map[string]interface{}{"motion": map[string]interface{}{"fd_mean": 0.3}, "snr": 40.0},
+		"reviewed": true,
+	})
+
+	update, _, err = client.SyncAcquisitionInfo(acquisitionId, desired)
+	t.So(err, ShouldBeNil)
+	t.So(update.IsEmpty(), ShouldBeTrue)
+
+	// Files too
+	update, _, err = client.SyncAcquisitionFileInfo(acquisitionId, "yeats.txt", map[string]interface{}{"poet": map[string]interface{}{"name": "Yeats"}})
+	t.So(err, ShouldBeNil)
+	t.So(update.Set, ShouldResemble, map[string]interface{}{"poet": map[string]interface{}{"name": "Yeats"}})
+	_, err = acquisition.SetFileInfo("yeats.txt", map[string]interface{}{"poet.born": 1865})
+	t.So(err, ShouldBeNil)
+	result, _, err = client.GetAcquisition(acquisitionId)
+	t.So(err, ShouldBeNil)
+	t.So(result.Files[0].Info, ShouldResemble, map[string]interface{}{"poet": map[string]interface{}{"name": "Yeats", "born": 1865.0}})
+
+	_, _, err = client.SyncAcquisitionFileInfo(acquisitionId, "missing.txt", desired)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+}
+
+func (t *F) TestSyncInfoPastCache() {
+	server := fake.NewServer()
+	defer server.Close()
+
+	// Cached reads must not hide another client's change
+	client := server.Client(api.CacheResponses(api.CachePolicy{TTL: time.Hour}))
+	other := server.Client()
+	_, acquisitionIds := makeHierarchy(t, client)
+	acquisitionId := acquisitionIds[0]
+
+	_, _, err := client.GetAcquisition(acquisitionId)
+	t.So(err, ShouldBeNil)
+	_, err = other.SetAcquisitionInfo(acquisitionId, map[string]interface{}{"site": "Stanford"})
+	t.So(err, ShouldBeNil)
+	_, err = other.SetAcquisitionFileInfo(acquisitionId, "yeats.txt", map[string]interface{}{"poet": "Yeats"})
+	t.So(err, ShouldBeNil)
+
+	update, _, err := client.SyncAcquisitionInfo(acquisitionId, map[string]interface{}{})
+	t.So(err, ShouldBeNil)
+	t.So(update.Delete, ShouldResemble, []string{"site"})
+	update, _, err = client.SyncAcquisitionFileInfo(acquisitionId, "yeats.txt", map[string]interface{}{})
+	t.So(err, ShouldBeNil)
+	t.So(update.Delete, ShouldResemble, []string{"poet"})
+
+	result, _, err := other.GetAcquisition(acquisitionId)
+	t.So(err, ShouldBeNil)
+	t.So(result.Info, ShouldBeEmpty)
+	t.So(result.Files[0].Info, ShouldBeEmpty)
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestIterators() {
+	server := fake.NewServer()
+	defer server.Close()
+	counter := newRequestCounter()
+	client := server.Client(api.AddHooks(counter.hook()))
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	var projectIds []string
+	for x := 0; x < 4; x++ {
+		projectId, _, err := client.AddProject(&api.Project{Name: "Project " + strconv.Itoa(x), GroupId: groupId})
+		t.So(err, ShouldBeNil)
+		projectIds = append(projectIds, projectId)
+	}
+
+	// Pages are requested as needed
+	it := client.IterateProjects()
+	it.PageSize = 2
+	var seen []string
+	for it.Next() {
+		seen = append(seen, it.Project().Id)
+	}
+	t.So(it.Err(), ShouldBeNil)
+	t.So(seen, ShouldResemble, projectIds)
+	t.So(counter.count("GET /api/projects"), ShouldEqual, 3)
+
+	// Stopping early is fine
+	it = client.IterateProjects()
+	t.So(it.Next(), ShouldBeTrue)
+	t.So(it.Project().Id, ShouldEqual, projectIds[0])
+	t.So(it.Close(), ShouldBeNil)
+	t.So(it.Next(), ShouldBeFalse)
+	t.So(it.Err(), ShouldBeNil)
+
+	users := client.IterateUsers()
+	t.So(users.Next(), ShouldBeTrue)
+	t.So(users.User().Id, ShouldEqual, fake.UserId)
+	t.So(users.Next(), ShouldBeFalse)
+
+	projects, _, err := client.GetAllProjects()
+	t.So(err, ShouldBeNil)
+	t.So(projects, ShouldHaveLength, 4)
+
+	// The client can be used while iterating, even one request at a time
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	limited := server.Client(api.LimitRequests(api.Limits{MaxInFlight: 1})).WithContext(ctx)
+	it = limited.IterateProjects()
+	it.PageSize = 3
+	seen = nil
+	for it.Next() {
+		project, _, err := limited.GetProject(it.Project().Id)
+		t.So(err, ShouldBeNil)
+		seen = append(seen, project.Id)
+	}
+	t.So(it.Err(), ShouldBeNil)
+	t.So(seen, ShouldResemble, projectIds)
+}
+
+func (t *F) TestIteratorsWithoutPagination() {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/api/sessions" {
+			w.Write([]byte(`[{"_id":"a"},{"_id":"b"}]`))
+			return
+		}
+		w.WriteHeader(500)
+		w.Write([]byte(`{"message":"Something broke","status_code":500}`))
+	}))
+	defer server.Close()
+	client := makeServerClient(server)
+
+	// A server that ignores the parameters returns everything at once, or the same page again
+	it := client.IterateSessions()
+	it.PageSize = 2
+	var seen []string
+	for it.Next() {
+		seen = append(seen, it.Session().Id)
+	}
+	t.So(it.Err(), ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{"a", "b"})
+	t.So(atomic.LoadInt32(&requests), ShouldEqual, 3)
+
+	acquisitions := client.IterateAcquisitions()
+	t.So(acquisitions.Next(), ShouldBeFalse)
+	t.So(api.StatusCode(acquisitions.Err()), ShouldEqual, 500)
+
+	_, _, err := client.GetAllAcquisitions()
+	t.So(api.StatusCode(err), ShouldEqual, 500)
+}
+
+func (t *F) TestIteratorsWithoutAfterId() {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		// Applies the limit, but always starts from the beginning
+		count := 2500
+		if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit < count {
+			count = limit
+		}
+		body := "["
+		for x := 0; x < count; x++ {
+			if x > 0 {
+				body += ","
+			}
+			body += fmt.Sprintf(`{"_id":"%05d"}`, x)
+		}
+		w.Write([]byte(body + "]"))
+	}))
+	defer server.Close()
+	client := makeServerClient(server)
+
+	// Once the first page comes back again, the rest of the listing is requested at once
+	projects, _, err := client.GetAllProjects()
+	t.So(err, ShouldBeNil)
+	t.So(len(projects), ShouldEqual, 2500)
+	for x, project := range projects {
+		if project.Id != fmt.Sprintf("%05d", x) {
+			t.So(project.Id, ShouldEqual, fmt.Sprintf("%05d", x))
+			break
+		}
+	}
+	t.So(atomic.LoadInt32(&requests), ShouldEqual, 3)
+
+	projects, _, err = client.GetAllProjects(&api.ListOptions{Limit: 1500})
+	t.So(err, ShouldBeNil)
+	t.So(len(projects), ShouldEqual, 1500)
+	t.So(projects[1499].Id, ShouldEqual, "01499")
+}
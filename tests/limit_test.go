@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+// transferServer stalls file downloads after their first bytes, and answers everything else immediately.
+// It records the most requests it has seen at once.
+func transferServer(maxInFlight *int32) *httptest.Server {
+	var inFlight int32
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			seen := atomic.LoadInt32(maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		if strings.Contains(r.URL.Path, "/files/") {
+			w.Header().Set("Content-Length", "1000")
+			w.Write([]byte("prefix"))
+			w.(http.Flusher).Flush()
+
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(`{"_id": "yeats@example.com"}`))
+	}))
+}
+
+func (t *F) TestLimitInFlight() {
+	var maxInFlight int32
+	server := transferServer(&maxInFlight)
+	defer server.Close()
+
+	client := makeServerClient(server, api.LimitRequests(api.Limits{MaxInFlight: 2}))
+
+	var wg sync.WaitGroup
+	for x := 0; x < 10; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := client.GetCurrentUser()
+			t.So(err, ShouldBeNil)
+		}()
+	}
+	wg.Wait()
+
+	t.So(atomic.LoadInt32(&maxInFlight), ShouldBeBetweenOrEqual, 1, 2)
+}
+
+func (t *F) TestLimitRate() {
+	var maxInFlight int32
+	server := transferServer(&maxInFlight)
+	defer server.Close()
+
+	client := makeServerClient(server, api.LimitRequests(api.Limits{RequestsPerSecond: 50, Burst: 2}))
+
+	// After the burst, each request waits 20ms for its turn
+	begin := time.Now()
+	for x := 0; x < 7; x++ {
+		_, _, err := client.GetCurrentUser()
+		t.So(err, ShouldBeNil)
+	}
+	t.So(time.Since(begin), ShouldBeGreaterThanOrEqualTo, 90*time.Millisecond)
+
+	// Waiting for a turn respects the context
+	limiter := api.NewLimiter(api.Limits{RequestsPerSecond: 0.1})
+	done, err := limiter.Wait(context.Background())
+	t.So(err, ShouldBeNil)
+	done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Wait(ctx)
+	t.So(err == context.DeadlineExceeded, ShouldBeTrue)
+}
+
+func (t *F) TestLimitTransfers() {
+	var maxInFlight int32
+	server := transferServer(&maxInFlight)
+	defer server.Close()
+
+	// startDownload begins a download that holds its connection open until cancelled
+	startDownload := func(client *api.Client) context.CancelFunc {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, dest := DownloadSourceToBuffer()
+		progress, _ := client.WithContext(ctx).DownloadSimple("projects/some-project/files/yeats.txt", dest)
+		<-progress
+		return cancel
+	}
+
+	// With separate limits, a stalled download does not hold up other requests
+	limits := api.Limits{MaxInFlight: 1}
+	client := makeServerClient(server, api.LimitRequests(limits), api.LimitTransfers(limits))
+	cancel := startDownload(client)
+
+	ctx, cancelRequest := context.WithTimeout(context.Background(), 2*time.Second)
+	_, _, err := client.WithContext(ctx).GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	cancelRequest()
+	cancel()
+
+	// With shared limits, it does
+	client = makeServerClient(server, api.LimitRequests(limits))
+	cancel = startDownload(client)
+	defer cancel()
+
+	ctx, cancelRequest = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelRequest()
+	_, _, err = client.WithContext(ctx).GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, context.DeadlineExceeded.Error())
+}
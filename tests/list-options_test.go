@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestListOptions() {
+	server := fake.NewServer()
+	defer server.Close()
+	counter := newRequestCounter()
+	client := server.Client(api.AddHooks(counter.hook()))
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Project", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+
+	for _, code := range []string{"d", "c", "b", "a"} {
+		sessionId, _, err := client.AddSession(&api.Session{Name: "Session " + code, ProjectId: projectId, Subject: &api.Subject{Code: code}})
+		t.So(err, ShouldBeNil)
+		if code != "c" {
+			_, err = client.AddSessionTag(sessionId, "reviewed")
+			t.So(err, ShouldBeNil)
+		}
+	}
+
+	// Filter, sort and project on the server
+	since := time.Now().Add(-time.Hour).UTC()
+	sessions, _, err := client.GetProjectSessions(projectId, &api.ListOptions{
+		Filter: []string{"tags=reviewed", "modified>" + since.Format(time.RFC3339)},
+		Sort:   []string{"label"},
+		Fields: []string{"label", "subject.code"},
+	})
+	t.So(err, ShouldBeNil)
+	t.So(sessions, ShouldHaveLength, 3)
+	t.So(sessions[0].Name, ShouldEqual, "Session a")
+	t.So(sessions[1].Name, ShouldEqual, "Session b")
+	t.So(sessions[2].Name, ShouldEqual, "Session d")
+	t.So(sessions[0].Id, ShouldNotBeEmpty)
+	t.So(sessions[0].Subject.Code, ShouldEqual, "a")
+	t.So(sessions[0].ProjectId, ShouldBeEmpty)
+
+	sessions, _, err = client.GetAllSessions(&api.ListOptions{Filter: []string{"label=~[bc]$"}, Sort: []string{"subject.code:desc"}})
+	t.So(err, ShouldBeNil)
+	t.So(sessions, ShouldHaveLength, 2)
+	t.So(sessions[0].Name, ShouldEqual, "Session c")
+	t.So(sessions[1].Name, ShouldEqual, "Session b")
+
+	// A limit spans pages; the last page only asks for what is left
+	requests := counter.count("GET /api/sessions")
+	it := client.IterateSessions(&api.ListOptions{Limit: 3})
+	it.PageSize = 2
+	var seen []string
+	for it.Next() {
+		seen = append(seen, it.Session().Subject.Code)
+	}
+	t.So(it.Err(), ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{"d", "c", "b"})
+	t.So(counter.count("GET /api/sessions")-requests, ShouldEqual, 2)
+
+	// Sorted listings are not paged by ID
+	sessions, _, err = client.GetAllSessions(&api.ListOptions{Sort: []string{"label:desc"}, Limit: 1})
+	t.So(err, ShouldBeNil)
+	t.So(sessions, ShouldHaveLength, 1)
+	t.So(sessions[0].Name, ShouldEqual, "Session d")
+
+	_, _, err = client.GetAllSessions(&api.ListOptions{Filter: []string{"label"}})
+	t.So(api.StatusCode(err), ShouldEqual, 400)
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestMove() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	unsortedId, _, err := client.AddProject(&api.Project{Name: "Unsorted", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+	studyId, _, err := client.AddProject(&api.Project{Name: "Study", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+
+	// The same visit landed in both projects, with different acquisitions
+	strayId, _, err := client.AddSession(&api.Session{Name: "Visit 1", ProjectId: unsortedId, Subject: &api.Subject{Code: "ex1"}})
+	t.So(err, ShouldBeNil)
+	visitId, _, err := client.AddSession(&api.Session{Name: "Visit 1", ProjectId: studyId, Subject: &api.Subject{Code: "ex1"}})
+	t.So(err, ShouldBeNil)
+	var strayAcquisitionIds []string
+	for _, name := range []string{"T1", "T2"} {
+		acquisitionId, _, err := client.AddAcquisition(&api.Acquisition{Name: name, SessionId: strayId})
+		t.So(err, ShouldBeNil)
+		strayAcquisitionIds = append(strayAcquisitionIds, acquisitionId)
+	}
+	_, _, err = client.AddAcquisition(&api.Acquisition{Name: "T3", SessionId: visitId})
+	t.So(err, ShouldBeNil)
+
+	// Their tags, notes and info partly overlap
+	_, err = client.AddSessionTag(strayId, "blue")
+	t.So(err, ShouldBeNil)
+	_, err = client.AddSessionTag(strayId, "green")
+	t.So(err, ShouldBeNil)
+	_, err = client.AddSessionNote(strayId, "Rescheduled")
+	t.So(err, ShouldBeNil)
+	_, err = client.SetSessionInfo(strayId, map[string]interface{}{"site": "Stanford", "scanner": "Prisma"})
+	t.So(err, ShouldBeNil)
+	_, err = client.AddSessionTag(visitId, "green")
+	t.So(err, ShouldBeNil)
+	_, err = client.SetSessionInfo(visitId, map[string]interface{}{"site": "Stanford"})
+	t.So(err, ShouldBeNil)
+
+	// Labels that are taken are a conflict, and nothing moves
+	report, err := client.MoveSession(strayId, studyId, nil)
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	t.So(err.Error(), ShouldContainSubstring, `session "Visit 1" of subject "ex1" already exists`)
+	t.So(report.Steps, ShouldBeEmpty)
+
+	// A dry run reports the plan
+	report, err = client.MoveSession(strayId, studyId, &api.MoveOptions{Merge: true, DryRun: true})
+	t.So(err, ShouldBeNil)
+	t.So(report.Steps, ShouldHaveLength, 3)
+	t.So(report.Steps[2].Tags, ShouldResemble, []string{"blue"})
+	t.So(report.Steps[2].Notes, ShouldResemble, []string{"Rescheduled"})
+	t.So(report.Steps[2].Info, ShouldResemble, map[string]interface{}{"scanner": "Prisma"})
+	visit, _, err := client.GetSession(visitId)
+	t.So(err, ShouldBeNil)
+	t.So(visit.Tags, ShouldResemble, []string{"green"})
+	acquisitions, _, err := client.GetSessionAcquisitions(strayId)
+	t.So(err, ShouldBeNil)
+	t.So(acquisitions, ShouldHaveLength, 2)
+
+	// Merging moves the children, carries over what the session lacks, then removes the emptied session
+	report, err = client.MoveSession(strayId, studyId, &api.MoveOptions{Merge: true})
+	t.So(err, ShouldBeNil)
+	t.So(report.Steps, ShouldResemble, []*api.MoveStep{
+		{Container: &api.ContainerReference{Type: "acquisition", Id: strayAcquisitionIds[0]}, Target: &api.ContainerReference{Type: "session", Id: visitId}},
+		{Container: &api.ContainerReference{Type: "acquisition", Id: strayAcquisitionIds[1]}, Target: &api.ContainerReference{Type: "session", Id: visitId}},
+		{
+			Container: &api.ContainerReference{Type: "session", Id: strayId}, Target: &api.ContainerReference{Type: "session", Id: visitId}, Merged: true,
+			Tags: []string{"blue"}, Notes: []string{"Rescheduled"}, Info: map[string]interface{}{"scanner": "Prisma"},
+		},
+	})
+	acquisitions, _, err = client.GetSessionAcquisitions(visitId)
+	t.So(err, ShouldBeNil)
+	t.So(acquisitions, ShouldHaveLength, 3)
+	_, _, err = client.GetSession(strayId)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+	visit, _, err = client.GetSession(visitId)
+	t.So(err, ShouldBeNil)
+	t.So(visit.Tags, ShouldResemble, []string{"green", "blue"})
+	t.So(visit.Notes, ShouldHaveLength, 1)
+	t.So(visit.Notes[0].Text, ShouldEqual, "Rescheduled")
+	t.So(visit.Info, ShouldResemble, map[string]interface{}{"site": "Stanford", "scanner": "Prisma"})
+
+	// Without a conflict, the container simply moves, and its subject follows
+	otherId, _, err := client.AddSession(&api.Session{Name: "Visit 1", ProjectId: unsortedId, Subject: &api.Subject{Code: "ex2"}})
+	t.So(err, ShouldBeNil)
+	report, err = client.MoveSession(otherId, studyId, nil)
+	t.So(err, ShouldBeNil)
+	t.So(report.Steps, ShouldHaveLength, 1)
+	session, _, err := client.GetSession(otherId)
+	t.So(err, ShouldBeNil)
+	t.So(session.ProjectId, ShouldEqual, studyId)
+	subject, _, err := client.GetSubject(session.Subject.Id)
+	t.So(err, ShouldBeNil)
+	t.So(subject.ProjectId, ShouldEqual, studyId)
+
+	report, err = client.MoveAcquisition(strayAcquisitionIds[0], visitId, nil)
+	t.So(err, ShouldBeNil)
+	t.So(report.Steps, ShouldBeEmpty)
+
+	// Files are not merged
+	_, result := client.UploadToAcquisition(strayAcquisitionIds[0], UploadSourceFromString("yeats.txt", "Things fall apart"))
+	t.So(<-result, ShouldBeNil)
+	_, _, err = client.AddAcquisition(&api.Acquisition{Name: "T1", SessionId: otherId})
+	t.So(err, ShouldBeNil)
+	_, err = client.MoveAcquisition(strayAcquisitionIds[0], otherId, &api.MoveOptions{Merge: true})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	t.So(err.Error(), ShouldContainSubstring, "has files")
+
+	// Info that differs, and analyses, are not merged
+	strayId, _, err = client.AddSession(&api.Session{Name: "Visit 2", ProjectId: unsortedId, Subject: &api.Subject{Code: "ex1"}})
+	t.So(err, ShouldBeNil)
+	visitId, _, err = client.AddSession(&api.Session{Name: "Visit 2", ProjectId: studyId, Subject: &api.Subject{Code: "ex1"}})
+	t.So(err, ShouldBeNil)
+	_, err = client.SetSessionInfo(strayId, map[string]interface{}{"site": "Stanford"})
+	t.So(err, ShouldBeNil)
+	_, err = client.SetSessionInfo(visitId, map[string]interface{}{"site": "Berkeley"})
+	t.So(err, ShouldBeNil)
+	_, err = client.MoveSession(strayId, studyId, &api.MoveOptions{Merge: true, DryRun: true})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	t.So(err.Error(), ShouldContainSubstring, `info field "site"`)
+
+	_, err = client.DeleteSessionInfoFields(strayId, []string{"site"})
+	t.So(err, ShouldBeNil)
+	gearId, _, err := client.AddGear(&api.GearDoc{Gear: &api.Gear{Name: "test-gear", Version: "1"}})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.AddSessionAnalysis(strayId, &api.Analysis{Name: "Analysis"}, &api.Job{GearId: gearId})
+	t.So(err, ShouldBeNil)
+	_, err = client.MoveSession(strayId, studyId, &api.MoveOptions{Merge: true})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	t.So(err.Error(), ShouldContainSubstring, "has analyses")
+	_, _, err = client.GetSession(strayId)
+	t.So(err, ShouldBeNil)
+
+	// The new parent must be writable
+	_, err = client.DeleteProjectPermission(unsortedId, fake.UserId)
+	t.So(err, ShouldBeNil)
+	_, err = client.MoveSession(visitId, unsortedId, nil)
+	t.So(api.IsForbidden(err), ShouldBeTrue)
+
+	_, err = client.Move(client.Container(api.CollectionContainer, "abc"), groupId, nil)
+	t.So(err, ShouldNotBeNil)
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestPathEscaping() {
+	labels := []string{"unit-tests", "Before/After", `C:\data`, ""}
+	path := api.JoinPath(labels...)
+	t.So(path, ShouldEqual, `unit-tests/Before\/After/C:\\data/`)
+	t.So(api.SplitPath(path), ShouldResemble, labels)
+	t.So(api.SplitPath("a"), ShouldResemble, []string{"a"})
+}
+
+func (t *F) TestResolvePath() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests", Name: "Unit Tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Before/After", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+	sessionId, _, err := client.AddSession(&api.Session{Name: "Visit 1", ProjectId: projectId, Subject: &api.Subject{Code: "ex1"}})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.AddSession(&api.Session{Name: "Visit 1", ProjectId: projectId, Subject: &api.Subject{Code: "ex2"}})
+	t.So(err, ShouldBeNil)
+	acquisitionId, _, err := client.AddAcquisition(&api.Acquisition{Name: "T1", SessionId: sessionId})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.AddAcquisition(&api.Acquisition{Name: "T1", SessionId: sessionId})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.AddAcquisition(&api.Acquisition{Name: "T2", SessionId: sessionId})
+	t.So(err, ShouldBeNil)
+	_, result := client.Container(api.AcquisitionContainer, acquisitionId).Upload(UploadSourceFromString("yeats.txt", "Things fall apart"))
+	t.So(<-result, ShouldBeNil)
+
+	// Groups resolve by ID or label
+	resolved, _, err := client.ResolvePath(`Unit Tests/Before\/After/ex1/Visit 1`)
+	t.So(err, ShouldBeNil)
+	t.So(resolved.Group.Id, ShouldEqual, groupId)
+	t.So(resolved.Project.Id, ShouldEqual, projectId)
+	t.So(resolved.Subject.Code, ShouldEqual, "ex1")
+	t.So(resolved.Session.Id, ShouldEqual, sessionId)
+	t.So(resolved.Acquisition, ShouldBeNil)
+	t.So(resolved.Container().Path(), ShouldEqual, "sessions/"+sessionId)
+
+	// Labels can collide
+	_, _, err = client.ResolvePath(`unit-tests/Before\/After/ex1/Visit 1/T1`)
+	t.So(api.IsAmbiguous(err), ShouldBeTrue)
+	t.So(err.(*api.PathError).Matches, ShouldHaveLength, 2)
+	t.So(err.Error(), ShouldEqual, `Path "unit-tests/Before\\/After/ex1/Visit 1/T1" is ambiguous: 2 acquisitions are labeled "T1"`)
+
+	_, _, err = client.ResolvePath(`unit-tests/Before\/After/ex3`)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+	t.So(api.IsAmbiguous(err), ShouldBeFalse)
+	t.So(err.Error(), ShouldEqual, `No subject "ex3" in path "unit-tests/Before\\/After/ex3"`)
+
+	// Once a path is unique, files resolve too
+	_, err = client.ModifyAcquisition(acquisitionId, &api.Acquisition{Name: "T1 repeat"})
+	t.So(err, ShouldBeNil)
+	resolved, _, err = client.ResolvePath(`unit-tests/Before\/After/ex1/Visit 1/T1 repeat/yeats.txt`)
+	t.So(err, ShouldBeNil)
+	t.So(resolved.Acquisition.Id, ShouldEqual, acquisitionId)
+	t.So(resolved.File.Name, ShouldEqual, "yeats.txt")
+
+	// The inverse
+	path, _, err := client.PathOf(client.Container(api.AcquisitionContainer, acquisitionId))
+	t.So(err, ShouldBeNil)
+	t.So(path, ShouldEqual, `unit-tests/Before\/After/ex1/Visit 1/T1 repeat`)
+	resolved, _, err = client.ResolvePath(path)
+	t.So(err, ShouldBeNil)
+	t.So(resolved.Acquisition.Id, ShouldEqual, acquisitionId)
+
+	// Subjects need not have sessions
+	subjectId, _, err := client.AddSubject(&api.Subject{Code: "ex4", ProjectId: projectId})
+	t.So(err, ShouldBeNil)
+	path, _, err = client.PathOf(client.Container(api.SubjectContainer, subjectId))
+	t.So(err, ShouldBeNil)
+	t.So(path, ShouldEqual, `unit-tests/Before\/After/ex4`)
+	resolved, _, err = client.ResolvePath(path)
+	t.So(err, ShouldBeNil)
+	t.So(resolved.Subject.Id, ShouldEqual, subjectId)
+	t.So(resolved.Session, ShouldBeNil)
+	t.So(resolved.Container().Path(), ShouldEqual, "subjects/"+subjectId)
+	_, _, err = client.ResolvePath(path + "/Visit 1")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	path, _, err = client.PathOf(client.Container(api.ProjectContainer, projectId))
+	t.So(err, ShouldBeNil)
+	t.So(path, ShouldEqual, `unit-tests/Before\/After`)
+
+	_, _, err = client.PathOf(client.Container(api.CollectionContainer, "abc"))
+	t.So(err, ShouldNotBeNil)
+	_, _, err = client.PathOf(client.Container(api.AnalysisContainer, "abc"))
+	t.So(err.Error(), ShouldEqual, "An analysis does not have a path")
+}
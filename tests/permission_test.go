@@ -0,0 +1,91 @@
+package tests
+
+import (
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestPermissions() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Permissions", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+	sessionId, _, err := client.AddSession(&api.Session{Name: "Visit 1", ProjectId: projectId})
+	t.So(err, ShouldBeNil)
+	acquisitionId, _, err := client.AddAcquisition(&api.Acquisition{Name: "T1", SessionId: sessionId})
+	t.So(err, ShouldBeNil)
+
+	// Group roles
+	_, err = client.AddGroupPermission(groupId, &api.Permission{Id: "jane@example.com", Level: api.ReadOnlyAccess})
+	t.So(err, ShouldBeNil)
+	_, err = client.AddGroupPermission(groupId, &api.Permission{Id: "jane@example.com", Level: api.ReadOnlyAccess})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	_, err = client.ModifyGroupPermission(groupId, "jane@example.com", api.AdminAccess)
+	t.So(err, ShouldBeNil)
+	permissions, _, err := client.GetGroupPermissions(groupId)
+	t.So(err, ShouldBeNil)
+	t.So(permissions, ShouldContain, &api.Permission{Id: "jane@example.com", Level: api.AdminAccess})
+	_, err = client.DeleteGroupPermission(groupId, "jane@example.com")
+	t.So(err, ShouldBeNil)
+	_, err = client.DeleteGroupPermission(groupId, "jane@example.com")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	// Project changes reach sessions and acquisitions
+	jane := &api.Permission{Id: "jane@example.com", Level: api.ReadWriteAccess}
+	_, err = client.AddProjectPermission(projectId, jane)
+	t.So(err, ShouldBeNil)
+	permissions, _, err = client.GetProjectPermissions(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(permissions, ShouldContain, jane)
+	permissions, _, err = client.Container(api.AcquisitionContainer, acquisitionId).Permissions()
+	t.So(err, ShouldBeNil)
+	t.So(permissions, ShouldContain, jane)
+
+	// Children that drift can be brought back in line
+	_, err = client.Container(api.SessionContainer, sessionId).DeletePermission("jane@example.com")
+	t.So(err, ShouldBeNil)
+	bob := &api.Permission{Id: "bob@example.com", Level: api.ReadOnlyAccess}
+	_, err = client.Container(api.AcquisitionContainer, acquisitionId).AddPermission(bob)
+	t.So(err, ShouldBeNil)
+	permissions, _, err = client.Container(api.AcquisitionContainer, acquisitionId).Permissions()
+	t.So(err, ShouldBeNil)
+	t.So(permissions, ShouldNotContain, jane)
+
+	_, err = client.PropagateProjectPermissions(projectId)
+	t.So(err, ShouldBeNil)
+	projectPermissions, _, err := client.GetProjectPermissions(projectId)
+	t.So(err, ShouldBeNil)
+	for _, child := range []*api.Container{
+		client.Container(api.SessionContainer, sessionId),
+		client.Container(api.AcquisitionContainer, acquisitionId),
+	} {
+		permissions, _, err = child.Permissions()
+		t.So(err, ShouldBeNil)
+		t.So(permissions, ShouldResemble, projectPermissions)
+	}
+
+	_, err = client.DeleteProjectPermission(projectId, "jane@example.com")
+	t.So(err, ShouldBeNil)
+	permissions, _, err = client.Container(api.SessionContainer, sessionId).Permissions()
+	t.So(err, ShouldBeNil)
+	t.So(permissions, ShouldNotContain, jane)
+
+	// Collections
+	collectionId, _, err := client.AddCollection(&api.Collection{Name: "Permissions"})
+	t.So(err, ShouldBeNil)
+	_, err = client.AddCollectionPermission(collectionId, jane)
+	t.So(err, ShouldBeNil)
+	_, err = client.ModifyCollectionPermission(collectionId, "jane@example.com", api.ReadOnlyAccess)
+	t.So(err, ShouldBeNil)
+	permissions, _, err = client.GetCollectionPermissions(collectionId)
+	t.So(err, ShouldBeNil)
+	t.So(permissions, ShouldContain, &api.Permission{Id: "jane@example.com", Level: api.ReadOnlyAccess})
+	_, err = client.DeleteCollectionPermission(collectionId, "jane@example.com")
+	t.So(err, ShouldBeNil)
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+const testProfileConfig = `{
+	"default": "work",
+	"profiles": {
+		"work":  { "key": "flywheel.example.com:work-key", "root": true },
+		"local": { "host": "localhost:8443", "key": "change-me", "insecure_skip_verify": true }
+	}
+}`
+
+// writeProfileConfig writes a config file to a temporary folder, returning its path and a cleanup function.
+func writeProfileConfig(contents string) (string, func()) {
+	dir, err := ioutil.TempDir("", "sdk-profile")
+	if err != nil {
+		panic(err)
+	}
+
+	path := filepath.Join(dir, "profiles.json")
+	err = ioutil.WriteFile(path, []byte(contents), 0600)
+	if err != nil {
+		panic(err)
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func (t *F) TestProfileConfig() {
+	path, cleanup := writeProfileConfig(testProfileConfig)
+	defer cleanup()
+
+	config, err := api.LoadProfileConfig(path)
+	t.So(err, ShouldBeNil)
+	t.So(config.Profiles, ShouldHaveLength, 2)
+
+	// Default profile
+	profile, err := config.Profile("")
+	t.So(err, ShouldBeNil)
+	t.So(profile.Root, ShouldBeTrue)
+	apiKey, err := profile.ApiKey()
+	t.So(err, ShouldBeNil)
+	t.So(apiKey, ShouldEqual, "flywheel.example.com:work-key")
+	options, err := profile.Options()
+	t.So(err, ShouldBeNil)
+	t.So(options, ShouldHaveLength, 1)
+
+	// Named profile with a separate host
+	profile, err = config.Profile("local")
+	t.So(err, ShouldBeNil)
+	t.So(profile.InsecureSkipVerify, ShouldBeTrue)
+	apiKey, err = profile.ApiKey()
+	t.So(err, ShouldBeNil)
+	t.So(apiKey, ShouldEqual, "localhost:8443:change-me")
+
+	// Profiles are copies
+	profile.Key = "modified"
+	profile, _ = config.Profile("local")
+	t.So(profile.Key, ShouldEqual, "change-me")
+
+	_, err = config.Profile("not-a-profile")
+	t.So(err, ShouldNotBeNil)
+
+	_, err = api.NewClientFromProfile(&api.Profile{Key: "no-host"})
+	t.So(err, ShouldNotBeNil)
+
+	_, err = api.NewClientFromProfile(&api.Profile{Key: "hostname.example:my-key", CaBundle: path + ".missing"})
+	t.So(err, ShouldNotBeNil)
+
+	// Malformed and missing files
+	badPath, badCleanup := writeProfileConfig("{")
+	defer badCleanup()
+	_, err = api.LoadProfileConfig(badPath)
+	t.So(err, ShouldNotBeNil)
+
+	_, err = api.LoadProfileConfig(path + ".missing")
+	t.So(os.IsNotExist(err), ShouldBeTrue)
+}
+
+// TestClientFromEnv is the only test that sets the Sdk* environment variables; keep it that way, as tests run in parallel.
+func (t *F) TestClientFromEnv() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"_id": "` + r.URL.Query().Get("root") + `"}`))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	path, cleanup := writeProfileConfig(testProfileConfig)
+	defer cleanup()
+
+	env := map[string]string{
+		api.SdkConfigKey:    path,
+		api.SdkProfileKey:   "local",
+		api.SdkHostKey:      host,
+		api.SdkPlaintextKey: "true",
+		api.SdkRootKey:      "1",
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	// Environment overrides the selected profile
+	profile, err := api.ProfileFromEnv()
+	t.So(err, ShouldBeNil)
+	t.So(profile.Host, ShouldEqual, host)
+	t.So(profile.Key, ShouldEqual, "change-me")
+	t.So(profile.Root, ShouldBeTrue)
+	t.So(profile.InsecureUsePlaintext, ShouldBeTrue)
+
+	client, err := api.NewClientFromEnv()
+	t.So(err, ShouldBeNil)
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "true")
+
+	// A full key replaces the profile's host
+	os.Setenv(api.SdkApiKeyKey, "flywheel.example.com:other-key")
+	os.Unsetenv(api.SdkHostKey)
+	profile, err = api.ProfileFromEnv()
+	t.So(err, ShouldBeNil)
+	apiKey, _ := profile.ApiKey()
+	t.So(apiKey, ShouldEqual, "flywheel.example.com:other-key")
+	os.Unsetenv(api.SdkApiKeyKey)
+
+	os.Setenv(api.SdkRootKey, "sometimes")
+	_, err = api.ProfileFromEnv()
+	t.So(err, ShouldNotBeNil)
+	os.Setenv(api.SdkRootKey, "1")
+
+	os.Setenv(api.SdkProfileKey, "not-a-profile")
+	_, err = api.ProfileFromEnv()
+	t.So(err, ShouldNotBeNil)
+	os.Setenv(api.SdkProfileKey, "local")
+
+	// An explicit config file must exist
+	os.Setenv(api.SdkConfigKey, path+".missing")
+	_, err = api.ProfileFromEnv()
+	t.So(err, ShouldNotBeNil)
+}
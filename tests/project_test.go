@@ -218,11 +218,7 @@ func (t *F) TestCreateProjectInRootMode() {
 	userId := group.Permissions[0].Id
 
 	// Remove permissions
-	var aerr *api.Error
-	var response *api.ModifiedResponse
-
-	_, err = t.New().Delete("groups/"+groupId+"/permissions/"+userId).Receive(&response, &aerr)
-	err = api.Coalesce(err, aerr)
+	_, err = t.DeleteGroupPermission(groupId, userId)
 	t.So(err, ShouldBeNil)
 
 	group2, _, err := t.GetGroup(groupId)
@@ -236,6 +232,7 @@ func (t *F) TestCreateProjectInRootMode() {
 	_, _, err = t.AddProject(project)
 	t.So(err, ShouldNotBeNil)
 	t.So(err.Error(), ShouldEqual, "(403) user not authorized to perform a POST operation on the container.")
+	t.So(api.IsForbidden(err), ShouldBeTrue)
 
 	// But we shouldn't get an error in root mode
 	projectId, _, err := t.RootClient.AddProject(project)
@@ -243,8 +240,7 @@ func (t *F) TestCreateProjectInRootMode() {
 	t.So(projectId, ShouldNotBeNil)
 
 	// Delete the implicit permission from the project
-	_, err = t.New().Delete("projects/"+projectId+"/permissions/"+userId).Receive(&response, &aerr)
-	err = api.Coalesce(err, aerr)
+	_, err = t.DeleteProjectPermission(projectId, userId)
 	t.So(err, ShouldBeNil)
 
 	// Should get 403 error
@@ -252,6 +248,7 @@ func (t *F) TestCreateProjectInRootMode() {
 	t.So(rProject, ShouldBeNil)
 	t.So(err, ShouldNotBeNil)
 	t.So(err.Error(), ShouldEqual, "(403) user not authorized to perform a GET operation on the container.")
+	t.So(api.IsForbidden(err), ShouldBeTrue)
 
 	// Should be able to retrieve the project as root
 	rProject, _, err = t.RootClient.GetProject(projectId)
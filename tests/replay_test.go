@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+var replayFile = bytes.Repeat([]byte{0, 1, 2, 3}, 100)
+
+// replayServer answers the requests made by replayRequests.
+func replayServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+
+		switch {
+		case r.URL.Path == "/api/users/self":
+			w.Write([]byte(`{"_id": "yeats@example.com", "api_key": {"key": "change-me"}}`))
+		case r.URL.Path == "/api/projects":
+			w.Write([]byte(`{"_id": "project-1"}`))
+		case strings.Contains(r.URL.Path, "/files/"):
+			w.Write(replayFile)
+		default:
+			w.WriteHeader(404)
+			w.Write([]byte(`{"status_code": 404, "message": "The resource could not be found."}`))
+		}
+	}))
+}
+
+// replayRequests makes a series of requests, checking each response.
+func (t *F) replayRequests(client *api.Client, projectName string) {
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "yeats@example.com")
+
+	id, _, err := client.AddProject(&api.Project{Name: projectName})
+	t.So(err, ShouldBeNil)
+	t.So(id, ShouldEqual, "project-1")
+
+	buffer, dest := DownloadSourceToBuffer()
+	_, result := client.DownloadSimple("projects/project-1/files/tower.bin?ticket=secret-ticket", dest)
+	t.So(<-result, ShouldBeNil)
+	t.So(buffer.Bytes(), ShouldResemble, replayFile)
+
+	_, _, err = client.GetProject("not-a-project")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+}
+
+func (t *F) TestRecordReplay() {
+	dir, err := ioutil.TempDir("", "sdk-replay")
+	t.So(err, ShouldBeNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "recording.json")
+
+	server := replayServer()
+	client := makeServerClient(server, api.RecordReplay(path, api.Record))
+	t.replayRequests(client, "The Tower")
+	server.Close()
+
+	// Secrets are scrubbed, and binary bodies encoded
+	raw, err := ioutil.ReadFile(path)
+	t.So(err, ShouldBeNil)
+	recording := string(raw)
+	t.So(recording, ShouldNotContainSubstring, "change-me")
+	t.So(recording, ShouldNotContainSubstring, "secret-ticket")
+	t.So(recording, ShouldContainSubstring, "[scrubbed]")
+	t.So(recording, ShouldContainSubstring, `"body_encoding": "base64"`)
+	t.So(recording, ShouldNotContainSubstring, "127.0.0.1")
+
+	// Replays without a server, even with different request bodies
+	client = api.NewApiKeyClient("hostname.example:80:other-key", api.InsecureUsePlaintext, api.RecordReplay(path, api.Replay))
+	t.replayRequests(client, "The Winding Stair")
+
+	// Each interaction is replayed once
+	_, _, err = client.GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, "No recorded interaction for GET /api/users/self")
+
+	// Body matching is optional. Transports with the same file share its interactions, so use a copy.
+	copyPath := filepath.Join(dir, "copy.json")
+	t.So(ioutil.WriteFile(copyPath, raw, 0600), ShouldBeNil)
+
+	hc := &http.Client{Transport: &api.ReplayTransport{Path: copyPath, Mode: api.Replay, Matcher: api.MatchRequestsAndBodies}}
+	client = &api.Client{Doer: hc, Sling: client.Sling.New().Doer(hc)}
+	_, _, err = client.AddProject(&api.Project{Name: "The Winding Stair"})
+	t.So(err, ShouldNotBeNil)
+	id, _, err := client.AddProject(&api.Project{Name: "The Tower"})
+	t.So(err, ShouldBeNil)
+	t.So(id, ShouldEqual, "project-1")
+}
+
+func (t *F) TestRecordReplayAuto() {
+	dir, err := ioutil.TempDir("", "sdk-replay")
+	t.So(err, ShouldBeNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nested", "recording.json")
+
+	// Missing recordings cannot be replayed
+	client := api.NewApiKeyClient("hostname.example:80:my-key", api.InsecureUsePlaintext, api.RecordReplay(path, api.Replay))
+	_, _, err = client.GetCurrentUser()
+	t.So(os.IsNotExist(err), ShouldBeFalse)
+	t.So(err, ShouldNotBeNil)
+
+	// ...but are recorded in auto mode
+	server := replayServer()
+	defer server.Close()
+	client = makeServerClient(server, api.RecordReplay(path, api.ReplayOrRecord))
+	t.replayRequests(client, "The Tower")
+
+	_, err = os.Stat(path)
+	t.So(err, ShouldBeNil)
+
+	_, _, err = api.NewApiKeyClient("hostname.example:80:my-key", api.RecordReplay(path, "sometimes")).GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, "Invalid recording mode")
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+var fastRetry = api.RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  10 * time.Millisecond,
+}
+
+// failingServer responds with status until it has been hit failures times, then with body.
+func failingServer(hits *int32, failures int32, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+
+		if atomic.AddInt32(hits, 1) <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"status_code": ` + strconv.Itoa(status) + `, "message": "` + http.StatusText(status) + `"}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+}
+
+func (t *F) TestRetryIdempotentRequests() {
+	var hits int32
+	server := failingServer(&hits, 2, 503, `{"_id": "yeats@example.com"}`)
+	defer server.Close()
+
+	client := makeServerClient(server, api.RetryRequests(fastRetry))
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "yeats@example.com")
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 3)
+
+	// Attempts are limited
+	atomic.StoreInt32(&hits, 0)
+	server2 := failingServer(&hits, 10, 502, "")
+	defer server2.Close()
+
+	client = makeServerClient(server2, api.RetryRequests(fastRetry))
+	_, _, err = client.GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(api.IsRetryable(err), ShouldBeTrue)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 3)
+
+	// Without a policy, nothing is retried
+	atomic.StoreInt32(&hits, 0)
+	client = makeServerClient(server2)
+	_, _, err = client.GetCurrentUser()
+	t.So(api.StatusCode(err), ShouldEqual, 502)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 1)
+}
+
+func (t *F) TestRetryPost() {
+	var hits int32
+	server := failingServer(&hits, 1, 503, `{"_id": "some-project"}`)
+	defer server.Close()
+
+	// POST is not retried by default
+	client := makeServerClient(server, api.RetryRequests(fastRetry))
+	_, _, err := client.AddProject(&api.Project{Name: RandString()})
+	t.So(api.StatusCode(err), ShouldEqual, 503)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 1)
+
+	// Unless opted into
+	atomic.StoreInt32(&hits, 0)
+	policy := fastRetry
+	policy.RetryPost = true
+	client = makeServerClient(server, api.RetryRequests(policy))
+	projectId, _, err := client.AddProject(&api.Project{Name: RandString()})
+	t.So(err, ShouldBeNil)
+	t.So(projectId, ShouldEqual, "some-project")
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+}
+
+func (t *F) TestRetryAfter() {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(429)
+			return
+		}
+		w.Write([]byte(`{"_id": "yeats@example.com"}`))
+	}))
+	defer server.Close()
+
+	policy := fastRetry
+	policy.MaxBackoff = 2 * time.Second
+	client := makeServerClient(server, api.RetryRequests(policy))
+	begin := time.Now()
+	_, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(time.Since(begin), ShouldBeGreaterThanOrEqualTo, time.Second)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+
+	// A longer wait than the policy allows is left to the caller
+	atomic.StoreInt32(&hits, 0)
+	client = makeServerClient(server, api.RetryRequests(fastRetry))
+	begin = time.Now()
+	_, _, err = client.GetCurrentUser()
+	t.So(api.StatusCode(err), ShouldEqual, 429)
+	t.So(api.IsRetryable(err), ShouldBeTrue)
+	t.So(time.Since(begin), ShouldBeLessThan, time.Second)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 1)
+}
+
+// seekCloser is a replayable upload source
+type seekCloser struct {
+	*bytes.Reader
+}
+
+func (seekCloser) Close() error { return nil }
+
+func (t *F) TestRetryUploads() {
+	poem := "The best lack all conviction, while the worst"
+
+	var hits int32
+	var mutex sync.Mutex
+	var received [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := ioutil.ReadAll(r.Body)
+		mutex.Lock()
+		received = append(received, raw)
+		mutex.Unlock()
+
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(502)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := makeServerClient(server, api.RetryRequests(fastRetry))
+
+	// Path sources are opened again
+	file, err := ioutil.TempFile("", "sdk-retry")
+	t.So(err, ShouldBeNil)
+	defer os.Remove(file.Name())
+	file.WriteString(poem)
+	file.Close()
+
+	progress, result := client.UploadSimple("projects/some-project/files", nil, &api.UploadSource{Path: file.Name(), Name: "yeats.txt"})
+	t.checkProgressChanEndsWith(progress, int64(len(poem)))
+	t.So(<-result, ShouldBeNil)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+	t.So(received, ShouldHaveLength, 2)
+	t.So(string(received[1]), ShouldContainSubstring, poem)
+	t.So(received[1], ShouldResemble, received[0])
+
+	// Seekable readers are rewound
+	atomic.StoreInt32(&hits, 0)
+	received = nil
+	source := &api.UploadSource{Name: "yeats.txt", Reader: seekCloser{bytes.NewReader([]byte(poem))}}
+	progress, result = client.UploadSimple("projects/some-project/files", nil, source)
+	t.checkProgressChanEndsWith(progress, int64(len(poem)))
+	t.So(<-result, ShouldBeNil)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+	t.So(string(received[1]), ShouldContainSubstring, poem)
+
+	// Other readers cannot be replayed
+	atomic.StoreInt32(&hits, 0)
+	_, result = client.UploadSimple("projects/some-project/files", nil, UploadSourceFromString("yeats.txt", poem))
+	err = <-result
+	t.So(api.StatusCode(err), ShouldEqual, 502)
+	t.So(atomic.LoadInt32(&hits), ShouldEqual, 1)
+}
+
+func (t *F) TestRetryUntrustedCertificate() {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"_id": "yeats@example.com"}`))
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+	apiKey := strings.TrimPrefix(server.URL, "https://") + ":change-me"
+
+	// A certificate that cannot be verified will not become trusted by trying again
+	_, _, err := api.NewApiKeyClient(apiKey, api.RetryRequests(fastRetry)).GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, "certificate")
+	t.So(atomic.LoadInt32(&connections), ShouldEqual, 1)
+}
@@ -223,6 +223,65 @@ func (t *F) TestSessionFiles() {
 	t.So(len(rSession.Files), ShouldEqual, 0)
 }
 
+func (t *F) TestSessionTagsAndNotes() {
+	_, _, sessionId := t.createTestSession()
+
+	_, err := t.AddSessionNote(sessionId, "First draft")
+	t.So(err, ShouldBeNil)
+	_, err = t.AddSessionNote(sessionId, "Keep this")
+	t.So(err, ShouldBeNil)
+	for _, tag := range []string{"one", "two", "three"} {
+		_, err = t.AddSessionTag(sessionId, tag)
+		t.So(err, ShouldBeNil)
+	}
+
+	// Rename and remove tags
+	_, err = t.RenameSessionTag(sessionId, "two", "second")
+	t.So(err, ShouldBeNil)
+	_, err = t.DeleteSessionTag(sessionId, "three")
+	t.So(err, ShouldBeNil)
+	_, err = t.DeleteSessionTag(sessionId, "three")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	// Edit and remove notes
+	rSession, _, err := t.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(rSession.Notes, ShouldHaveLength, 2)
+	_, err = t.ModifySessionNote(sessionId, rSession.Notes[0].Id, "Final draft")
+	t.So(err, ShouldBeNil)
+	_, err = t.DeleteSessionNote(sessionId, rSession.Notes[1].Id)
+	t.So(err, ShouldBeNil)
+
+	rSession, _, err = t.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(rSession.Tags, ShouldResemble, []string{"one", "second"})
+	t.So(rSession.Notes, ShouldHaveLength, 1)
+	t.So(rSession.Notes[0].Text, ShouldEqual, "Final draft")
+
+	// File tags
+	src := UploadSourceFromString("yeats.txt", "Things fall apart")
+	progress, resultChan := t.UploadToSession(sessionId, src)
+	for range progress {
+	}
+	t.So(<-resultChan, ShouldBeNil)
+
+	_, err = t.AddSessionFileTag(sessionId, "yeats.txt", "poem")
+	t.So(err, ShouldBeNil)
+	_, err = t.AddSessionFileTag(sessionId, "yeats.txt", "irish")
+	t.So(err, ShouldBeNil)
+	_, err = t.RenameSessionFileTag(sessionId, "yeats.txt", "poem", "poetry")
+	t.So(err, ShouldBeNil)
+	_, err = t.DeleteSessionFileTag(sessionId, "yeats.txt", "irish")
+	t.So(err, ShouldBeNil)
+	_, err = t.AddSessionFileTag(sessionId, "keats.txt", "poem")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	rSession, _, err = t.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(rSession.Files, ShouldHaveLength, 1)
+	t.So(rSession.Files[0].Tags, ShouldResemble, []string{"poetry"})
+}
+
 func (t *F) createTestSession() (string, string, string) {
 	groupId, projectId := t.createTestProject()
 
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestSubjects() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Subjects", GroupId: groupId})
+	t.So(err, ShouldBeNil)
+
+	// Add
+	subjectId, _, err := client.AddSubject(&api.Subject{Code: "ex1", ProjectId: projectId, Sex: "other"})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.AddSubject(&api.Subject{Code: "ex1", ProjectId: projectId})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+
+	// Sessions with the subject's code are linked to it
+	visit1, _, err := client.AddSession(&api.Session{Name: "Visit 1", ProjectId: projectId, Subject: &api.Subject{Code: "ex1", Age: 56}})
+	t.So(err, ShouldBeNil)
+	visit2, _, err := client.AddSession(&api.Session{Name: "Visit 2", ProjectId: projectId, Subject: &api.Subject{Id: subjectId}})
+	t.So(err, ShouldBeNil)
+	other, _, err := client.AddSession(&api.Session{Name: "Visit 1", ProjectId: projectId, Subject: &api.Subject{Code: "ex2"}})
+	t.So(err, ShouldBeNil)
+
+	// Get
+	subject, _, err := client.GetSubject(subjectId)
+	t.So(err, ShouldBeNil)
+	t.So(subject.Code, ShouldEqual, "ex1")
+	t.So(subject.ProjectId, ShouldEqual, projectId)
+	t.So(subject.Sex, ShouldEqual, "other")
+	t.So(subject.Age, ShouldEqual, 56)
+
+	// Navigation
+	subjects, _, err := client.GetProjectSubjects(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(subjects, ShouldHaveLength, 2)
+	sessions, _, err := client.GetSubjectSessions(subjectId)
+	t.So(err, ShouldBeNil)
+	t.So(sessions, ShouldHaveLength, 2)
+	t.So(sessions[0].Id, ShouldEqual, visit1)
+	t.So(sessions[1].Id, ShouldEqual, visit2)
+	subjects, _, err = client.GetAllSubjects(&api.ListOptions{Filter: []string{"code=ex2"}})
+	t.So(err, ShouldBeNil)
+	t.So(subjects, ShouldHaveLength, 1)
+
+	// Changes reach each session's copy of the subject
+	_, err = client.ModifySubject(subjectId, &api.Subject{Code: "ex1-renamed"})
+	t.So(err, ShouldBeNil)
+	_, err = client.SetSubjectInfo(subjectId, map[string]interface{}{"handedness": "left"})
+	t.So(err, ShouldBeNil)
+	session, _, err := client.GetSession(visit2)
+	t.So(err, ShouldBeNil)
+	t.So(session.Subject.Id, ShouldEqual, subjectId)
+	t.So(session.Subject.Code, ShouldEqual, "ex1-renamed")
+	t.So(session.Subject.Info, ShouldResemble, map[string]interface{}{"handedness": "left"})
+
+	_, err = client.DeleteSubjectInfoFields(subjectId, []string{"handedness"})
+	t.So(err, ShouldBeNil)
+	_, err = client.ModifySubject(subjectId, &api.Subject{Code: "ex2"})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+
+	// And changes through a session reach the subject
+	_, err = client.ModifySession(visit1, &api.Session{Subject: &api.Subject{Firstname: "Jane"}})
+	t.So(err, ShouldBeNil)
+	subject, _, err = client.GetSubject(subjectId)
+	t.So(err, ShouldBeNil)
+	t.So(subject.Firstname, ShouldEqual, "Jane")
+	t.So(subject.Info, ShouldBeEmpty)
+
+	// Files, notes and tags
+	_, result := client.UploadToSubject(subjectId, UploadSourceFromString("consent.txt", "Signed"))
+	t.So(<-result, ShouldBeNil)
+	_, err = client.SetSubjectFileInfo(subjectId, "consent.txt", map[string]interface{}{"version": 2})
+	t.So(err, ShouldBeNil)
+	_, err = client.AddSubjectTag(subjectId, "enrolled")
+	t.So(err, ShouldBeNil)
+	_, err = client.AddSubjectNote(subjectId, "Prefers mornings")
+	t.So(err, ShouldBeNil)
+	subject, _, err = client.GetSubject(subjectId)
+	t.So(err, ShouldBeNil)
+	t.So(subject.Files, ShouldHaveLength, 1)
+	t.So(subject.Files[0].Info["version"], ShouldEqual, 2)
+	t.So(subject.Tags, ShouldResemble, []string{"enrolled"})
+	t.So(subject.Notes, ShouldHaveLength, 1)
+
+	path, _, err := client.PathOf(client.Container(api.SubjectContainer, subjectId))
+	t.So(err, ShouldBeNil)
+	t.So(path, ShouldEqual, "unit-tests/Subjects/ex1-renamed")
+	resolved, _, err := client.ResolvePath(path)
+	t.So(err, ShouldBeNil)
+	t.So(resolved.Container().Path(), ShouldEqual, "subjects/"+subjectId)
+
+	// Deleting a subject deletes its sessions
+	_, err = client.DeleteSubject(subjectId)
+	t.So(err, ShouldBeNil)
+	_, _, err = client.GetSession(visit1)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+	_, _, err = client.GetSession(other)
+	t.So(err, ShouldBeNil)
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+)
+
+// writeTempFiles writes each of contents to a temporary folder, returning their paths and a cleanup function.
+func writeTempFiles(contents ...[]byte) ([]string, func()) {
+	dir, err := ioutil.TempDir("", "sdk-tls")
+	if err != nil {
+		panic(err)
+	}
+
+	paths := []string{}
+	for x, content := range contents {
+		path := filepath.Join(dir, "file"+strconv.Itoa(x)+".pem")
+		err = ioutil.WriteFile(path, content, 0600)
+		if err != nil {
+			panic(err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, func() { os.RemoveAll(dir) }
+}
+
+// makeClientCertificate generates a self-signed client certificate, returning its certificate and key as PEM.
+func makeClientCertificate() ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sdk-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// tlsServer responds with the common name of the client certificate, if any, as the user ID.
+func tlsServer(requireClientCert bool) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := ""
+		if len(r.TLS.PeerCertificates) > 0 {
+			name = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		w.Write([]byte(`{"_id": "` + name + `"}`))
+	}))
+
+	if requireClientCert {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	}
+	server.StartTLS()
+	return server
+}
+
+func (t *F) TestTLSRootCAs() {
+	server := tlsServer(false)
+	defer server.Close()
+	apiKey := strings.TrimPrefix(server.URL, "https://") + ":change-me"
+
+	paths, cleanup := writeTempFiles(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), []byte("not a certificate"))
+	defer cleanup()
+
+	// Self-signed certificates are not trusted by default
+	_, _, err := api.NewApiKeyClient(apiKey).GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, "certificate")
+
+	pool, err := api.LoadCertPool(paths[0])
+	t.So(err, ShouldBeNil)
+	_, _, err = api.NewApiKeyClient(apiKey, api.TrustRootCAs(pool)).GetCurrentUser()
+	t.So(err, ShouldBeNil)
+
+	client, err := api.NewClientFromProfile(&api.Profile{Key: apiKey, CaBundle: paths[0]})
+	t.So(err, ShouldBeNil)
+	_, _, err = client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+
+	_, err = api.LoadCertPool(paths[1])
+	t.So(err, ShouldNotBeNil)
+}
+
+func (t *F) TestTLSClientCertificate() {
+	server := tlsServer(true)
+	defer server.Close()
+	apiKey := strings.TrimPrefix(server.URL, "https://") + ":change-me"
+
+	certPem, keyPem := makeClientCertificate()
+	paths, cleanup := writeTempFiles(certPem, keyPem)
+	defer cleanup()
+
+	_, _, err := api.NewApiKeyClient(apiKey, api.InsecureNoSSLVerification).GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+
+	cert, err := tls.X509KeyPair(certPem, keyPem)
+	t.So(err, ShouldBeNil)
+	user, _, err := api.NewApiKeyClient(apiKey, api.InsecureNoSSLVerification, api.ClientCertificate(cert)).GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "sdk-test-client")
+
+	profile := &api.Profile{Key: apiKey, InsecureSkipVerify: true, ClientCertificate: paths[0], ClientKey: paths[1]}
+	client, err := api.NewClientFromProfile(profile)
+	t.So(err, ShouldBeNil)
+	user, _, err = client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "sdk-test-client")
+
+	profile.ClientKey = paths[0]
+	_, err = api.NewClientFromProfile(profile)
+	t.So(err, ShouldNotBeNil)
+}
+
+func (t *F) TestProxy() {
+	// A plaintext proxy receives the full URL of each request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"_id": "` + r.URL.Host + `"}`))
+	}))
+	defer proxy.Close()
+
+	proxyUrl, err := url.Parse(proxy.URL)
+	t.So(err, ShouldBeNil)
+
+	client := api.NewApiKeyClient("hostname.example:80:my-key", api.InsecureUsePlaintext, api.UseProxy(http.ProxyURL(proxyUrl)))
+	user, _, err := client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "hostname.example:80")
+
+	client, err = api.NewClientFromProfile(&api.Profile{Key: "hostname.example:80:my-key", InsecureUsePlaintext: true, Proxy: proxy.URL})
+	t.So(err, ShouldBeNil)
+	user, _, err = client.GetCurrentUser()
+	t.So(err, ShouldBeNil)
+	t.So(user.Id, ShouldEqual, "hostname.example:80")
+
+	// Environment variables are only used when asked for
+	t.So(api.DefaultApiKeyClientOptions.Proxy, ShouldBeNil)
+	_, err = api.NewClientFromProfile(&api.Profile{Key: "hostname.example:80:my-key", Proxy: api.ProfileProxyFromEnvironment})
+	t.So(err, ShouldBeNil)
+}
+
+func (t *F) TestTimeouts() {
+	server := stallingServer("")
+	defer server.Close()
+
+	timeouts := api.DefaultTimeouts
+	timeouts.ResponseHeader = 50 * time.Millisecond
+
+	begin := time.Now()
+	client := makeServerClient(server, api.SetTimeouts(timeouts), api.ConnectionPool(10, 10))
+	_, _, err := client.GetCurrentUser()
+	t.So(err, ShouldNotBeNil)
+	t.So(err.Error(), ShouldContainSubstring, "timeout")
+	t.So(time.Since(begin), ShouldBeLessThan, 5*time.Second)
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+func (t *F) TestModifyIfUnchanged() {
+	server := fake.NewServer()
+	defer server.Close()
+
+	// Cached reads must not hide another client's change
+	client := server.Client(api.CacheResponses(api.CachePolicy{TTL: time.Hour}))
+	other := server.Client()
+	_, acquisitionIds := makeHierarchy(t, client)
+
+	acquisition, _, err := client.GetAcquisition(acquisitionIds[0])
+	t.So(err, ShouldBeNil)
+	sessionId := acquisition.SessionId
+
+	read, _, err := client.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	_, err = other.ModifySession(sessionId, &api.Session{Name: "Renamed by someone else"})
+	t.So(err, ShouldBeNil)
+
+	_, err = client.ModifySessionIfUnchanged(sessionId, read.Modified, &api.Session{Name: "Renamed by me"})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	conflict, isConflict := err.(*api.ConflictError)
+	t.So(isConflict, ShouldBeTrue)
+	t.So(conflict.Container, ShouldResemble, &api.ContainerReference{Type: "session", Id: sessionId})
+	t.So(conflict.Expected, ShouldResemble, read.Modified)
+	t.So(conflict.Actual.After(*read.Modified), ShouldBeTrue)
+
+	read, _, err = other.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(read.Name, ShouldEqual, "Renamed by someone else")
+	_, err = client.ModifySessionIfUnchanged(sessionId, read.Modified, &api.Session{Name: "Renamed by me"})
+	t.So(err, ShouldBeNil)
+
+	_, err = client.ModifySessionIfUnchanged(sessionId, nil, &api.Session{Name: "Renamed by me"})
+	t.So(err, ShouldNotBeNil)
+
+	// A change made after the check, but before the write, is refused by the server
+	read, _, err = other.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	racing := server.Client(api.AddHooks(&interleave{
+		match: func(req *http.Request) bool { return req.Method == "PUT" },
+		run: func() {
+			_, err := other.ModifySession(sessionId, &api.Session{Name: "Renamed in between"})
+			t.So(err, ShouldBeNil)
+		},
+	}))
+	_, err = racing.ModifySessionIfUnchanged(sessionId, read.Modified, &api.Session{Name: "Renamed by me"})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	conflict, isConflict = err.(*api.ConflictError)
+	t.So(isConflict, ShouldBeTrue)
+	t.So(conflict.Actual.After(*read.Modified), ShouldBeTrue)
+	read, _, err = other.GetSession(sessionId)
+	t.So(err, ShouldBeNil)
+	t.So(read.Name, ShouldEqual, "Renamed in between")
+}
+
+// interleave runs a function just before the first request that matches is sent, as if another client got there first.
+type interleave struct {
+	match func(req *http.Request) bool
+	run   func()
+
+	mutex sync.Mutex
+	done  bool
+}
+
+func (h *interleave) Request(req *http.Request) *http.Request {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if !h.done && h.match(req) {
+		h.done = true
+		h.run()
+	}
+	return req
+}
+
+func (h *interleave) Response(req *http.Request, resp *http.Response, err error) {}
+func (h *interleave) Done(stats *api.RequestStats)                               {}
+
+func (t *F) TestUpdate() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	other := server.Client()
+
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+	projectId, _, err := client.AddProject(&api.Project{Name: "Update", GroupId: groupId, Description: "a"})
+	t.So(err, ShouldBeNil)
+
+	// Another script appends to the description while the first merge is running
+	calls := 0
+	_, err = client.UpdateProject(projectId, func(project *api.Project) (*api.Project, error) {
+		calls++
+		if calls == 1 {
+			_, err := other.ModifyProject(projectId, &api.Project{Description: project.Description + "b"})
+			t.So(err, ShouldBeNil)
+		}
+		return &api.Project{Description: project.Description + "c"}, nil
+	}, nil)
+	t.So(err, ShouldBeNil)
+	t.So(calls, ShouldEqual, 2)
+	project, _, err := client.GetProject(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(project.Description, ShouldEqual, "abc")
+
+	// Attempts run out
+	calls = 0
+	_, err = client.UpdateProject(projectId, func(project *api.Project) (*api.Project, error) {
+		calls++
+		_, err := other.ModifyProject(projectId, &api.Project{Description: project.Description + "d"})
+		t.So(err, ShouldBeNil)
+		return &api.Project{Description: "lost"}, nil
+	}, &api.UpdateOptions{MaxAttempts: 2})
+	t.So(api.IsConflict(err), ShouldBeTrue)
+	t.So(calls, ShouldEqual, 2)
+	project, _, err = client.GetProject(projectId)
+	t.So(err, ShouldBeNil)
+	t.So(project.Description, ShouldEqual, "abcdd")
+
+	// Each attempt reads the project once
+	recorder := &recordingHook{}
+	_, err = server.Client(api.AddHooks(recorder)).UpdateProject(projectId, func(project *api.Project) (*api.Project, error) {
+		return &api.Project{Description: project.Description + "e"}, nil
+	}, nil)
+	t.So(err, ShouldBeNil)
+	t.So(recorder.responses, ShouldResemble, []int{200, 200})
+
+	// Nothing to change, or a reason to stop
+	_, err = client.UpdateProject(projectId, func(project *api.Project) (*api.Project, error) {
+		return nil, nil
+	}, nil)
+	t.So(err, ShouldBeNil)
+	stop := errors.New("Not today")
+	_, err = client.UpdateProject(projectId, func(project *api.Project) (*api.Project, error) {
+		return nil, stop
+	}, nil)
+	t.So(err, ShouldEqual, stop)
+
+	_, err = client.UpdateProject("000000000000000000000000", func(project *api.Project) (*api.Project, error) {
+		return project, nil
+	}, nil)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+}
@@ -29,9 +29,14 @@ func (t *F) TestBadUploads() {
 	source = UploadSourceFromString("yeats.txt", poem)
 	_, result = t.UploadSimple("not-an-endpoint", nil, source)
 
-	// Could improve this in the future
 	err := <-result
-	t.So(err.Error(), ShouldMatchRegex, "\\{\"status_code\": 404, \"message\": \"The resource could not be found.\"\\, \"request_id\": \"[^\"]+\"}")
+	t.So(err.Error(), ShouldEqual, "(404) The resource could not be found.")
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+
+	aerr, ok := api.AsError(err)
+	t.So(ok, ShouldBeTrue)
+	t.So(aerr.Method, ShouldEqual, "POST")
+	t.So(aerr.Url, ShouldEndWith, "/api/not-an-endpoint")
 }
 
 // Given an upload function, container ID, filename, and content - upload & check length
@@ -3,6 +3,9 @@ package tests
 import (
 	"errors"
 	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
 
 	. "github.com/smartystreets/assertions"
 
@@ -34,6 +37,75 @@ func (t *F) TestCoalesce() {
 	t.So(res.Error(), ShouldEqual, "(500) Unknown server error")
 }
 
+func (t *F) TestCoalesceResponse() {
+	req, err := http.NewRequest("DELETE", "https://hostname.example/api/projects/abc", nil)
+	t.So(err, ShouldBeNil)
+
+	// Api error is annotated with the request
+	resp := &http.Response{StatusCode: 409, Request: req}
+	aErr := &api.Error{Message: "Conflict", StatusCode: 409}
+	res := api.CoalesceResponse(resp, nil, aErr)
+	t.So(res.Error(), ShouldEqual, "(409) Conflict")
+	t.So(api.IsConflict(res), ShouldBeTrue)
+	t.So(api.IsNotFound(res), ShouldBeFalse)
+
+	rErr, ok := api.AsError(res)
+	t.So(ok, ShouldBeTrue)
+	t.So(rErr.Method, ShouldEqual, "DELETE")
+	t.So(rErr.Url, ShouldEqual, "https://hostname.example/api/projects/abc")
+
+	// Undecodable failures still produce an api error
+	resp = &http.Response{StatusCode: 503, Request: req}
+	res = api.CoalesceResponse(resp, errors.New("invalid character '<' looking for beginning of value"), nil)
+	t.So(res.Error(), ShouldEqual, "(503) Service Unavailable")
+	t.So(api.IsRetryable(res), ShouldBeTrue)
+	t.So(api.StatusCode(res), ShouldEqual, 503)
+
+	// Successful responses pass local errors through
+	resp = &http.Response{StatusCode: 200, Request: req}
+	err = errors.New("This is an error")
+	res = api.CoalesceResponse(resp, err, nil)
+	t.So(res, ShouldEqual, err)
+	t.So(api.IsRetryable(res), ShouldBeFalse)
+	t.So(api.StatusCode(res), ShouldEqual, 0)
+
+	t.So(api.CoalesceResponse(resp, nil, nil), ShouldBeNil)
+}
+
+// wrapped is an error that adds context to another, as fmt.Errorf does with %w.
+type wrapped struct {
+	context string
+	err     error
+}
+
+func (e *wrapped) Error() string { return e.context + ": " + e.err.Error() }
+func (e *wrapped) Unwrap() error { return e.err }
+
+func (t *F) TestAsErrorWrapped() {
+	aErr := &api.Error{Message: "Not found", StatusCode: 404}
+
+	// Wrapped errors are classified by the *Error inside
+	for _, err := range []error{
+		&url.Error{Op: "Get", URL: "https://hostname.example/api/projects/abc", Err: aErr},
+		&wrapped{"Reading project", aErr},
+		&wrapped{"Resolving path", &api.PathError{Path: "group/project", Level: "project", Err: aErr}},
+	} {
+		res, ok := api.AsError(err)
+		t.So(ok, ShouldBeTrue)
+		t.So(res, ShouldEqual, aErr)
+		t.So(api.IsNotFound(err), ShouldBeTrue)
+	}
+	_, ok := api.AsError(&wrapped{"Reading project", errors.New("This is an error")})
+	t.So(ok, ShouldBeFalse)
+
+	// A conditional change that was refused is a conflict, however it is wrapped
+	modified := time.Now()
+	conflict := &api.ConflictError{Container: &api.ContainerReference{Type: "session", Id: "abc"}, Expected: &modified}
+	t.So(api.IsConflict(conflict), ShouldBeTrue)
+	t.So(api.IsConflict(&wrapped{"Updating session", conflict}), ShouldBeTrue)
+	t.So(api.StatusCode(conflict), ShouldEqual, 409)
+}
+
 func (t *F) TestFormat() {
 	wat := api.Format(&api.File{Name: "yeats.txt"})
 	t.So(wat, ShouldEqual, "{\n\t\"name\": \"yeats.txt\"\n}")
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	. "github.com/smartystreets/assertions"
+
+	"flywheel.io/sdk/api"
+	"flywheel.io/sdk/fake"
+)
+
+// makeHierarchy creates a group with two projects, each with two sessions of two acquisitions.
+// Each acquisition has one file, and the first session of each project is tagged.
+func makeHierarchy(t *F, client *api.Client) (string, []string) {
+	groupId, _, err := client.AddGroup(&api.Group{Id: "unit-tests"})
+	t.So(err, ShouldBeNil)
+
+	var acquisitionIds []string
+	for _, p := range []string{"1", "2"} {
+		projectId, _, err := client.AddProject(&api.Project{Name: "p" + p, GroupId: groupId})
+		t.So(err, ShouldBeNil)
+
+		for _, s := range []string{"1", "2"} {
+			sessionId, _, err := client.AddSession(&api.Session{Name: "p" + p + "s" + s, ProjectId: projectId})
+			t.So(err, ShouldBeNil)
+			if s == "1" {
+				_, err = client.AddSessionTag(sessionId, "first")
+				t.So(err, ShouldBeNil)
+			}
+
+			for _, a := range []string{"1", "2"} {
+				acquisitionId, _, err := client.AddAcquisition(&api.Acquisition{Name: "p" + p + "s" + s + "a" + a, SessionId: sessionId})
+				t.So(err, ShouldBeNil)
+				_, result := client.Container(api.AcquisitionContainer, acquisitionId).Upload(UploadSourceFromString("yeats.txt", "Things fall apart"))
+				t.So(<-result, ShouldBeNil)
+				acquisitionIds = append(acquisitionIds, acquisitionId)
+			}
+		}
+	}
+	return groupId, acquisitionIds
+}
+
+// label returns the name of a walked container.
+func label(node *api.WalkNode) string {
+	switch node.Type {
+	case api.GroupContainer:
+		return node.Id
+	case api.ProjectContainer:
+		return node.Project().Name
+	case api.SessionContainer:
+		return node.Session().Name
+	case api.AcquisitionContainer:
+		return node.Acquisition().Name
+	case api.CollectionContainer:
+		return node.Collection().Name
+	}
+	return ""
+}
+
+func (t *F) TestWalk() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	groupId, acquisitionIds := makeHierarchy(t, client)
+	group := client.Container(api.GroupContainer, groupId)
+
+	// One worker visits depth-first, parents before children
+	var seen []string
+	err := client.Walk(group, func(node *api.WalkNode) error {
+		seen = append(seen, label(node))
+		return nil
+	}, nil)
+	t.So(err, ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{
+		"unit-tests",
+		"p1", "p1s1", "p1s1a1", "p1s1a2", "p1s2", "p1s2a1", "p1s2a2",
+		"p2", "p2s1", "p2s1a1", "p2s1a2", "p2s2", "p2s2a1", "p2s2a2",
+	})
+
+	// Skipping, stopping and depth
+	seen = nil
+	err = client.Walk(group, func(node *api.WalkNode) error {
+		seen = append(seen, label(node))
+		if node.Type == api.ProjectContainer && node.Project().Name == "p1" {
+			return api.SkipContainer
+		}
+		if node.Type == api.SessionContainer {
+			return api.StopWalk
+		}
+		return nil
+	}, nil)
+	t.So(err, ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{"unit-tests", "p1", "p2", "p2s1"})
+
+	seen = nil
+	err = client.Walk(group, func(node *api.WalkNode) error {
+		seen = append(seen, label(node))
+		return nil
+	}, &api.WalkOptions{MaxDepth: 1})
+	t.So(err, ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{"unit-tests", "p1", "p2"})
+
+	// Types can be left out of the callback, or of the walk
+	seen = nil
+	err = client.Walk(group, func(node *api.WalkNode) error {
+		seen = append(seen, label(node))
+		t.So(node.Parent.Parent.Type, ShouldEqual, api.ProjectContainer)
+		return nil
+	}, &api.WalkOptions{
+		Include: []api.ContainerType{api.AcquisitionContainer},
+		Filter:  map[api.ContainerType]*api.ListOptions{api.SessionContainer: {Filter: []string{"tags=first"}}},
+	})
+	t.So(err, ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{"p1s1a1", "p1s1a2", "p2s1a1", "p2s1a2"})
+
+	seen = nil
+	err = client.Walk(nil, func(node *api.WalkNode) error {
+		seen = append(seen, label(node))
+		return nil
+	}, &api.WalkOptions{Exclude: []api.ContainerType{api.SessionContainer}})
+	t.So(err, ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{"unit-tests", "p1", "p2"})
+
+	// Many workers, with each container fetched in full
+	var mutex sync.Mutex
+	var files int32
+	visited := map[string]int{}
+	err = client.Walk(group, func(node *api.WalkNode) error {
+		if node.Type == api.AcquisitionContainer {
+			atomic.AddInt32(&files, int32(len(node.Acquisition().Files)))
+		}
+		mutex.Lock()
+		visited[node.Id]++
+		mutex.Unlock()
+		return nil
+	}, &api.WalkOptions{Workers: 4, Full: true})
+	t.So(err, ShouldBeNil)
+	t.So(visited, ShouldHaveLength, 15)
+	t.So(atomic.LoadInt32(&files), ShouldEqual, 8)
+	for _, id := range acquisitionIds {
+		t.So(visited[id], ShouldEqual, 1)
+	}
+
+	// The first error ends the walk
+	broken := errors.New("broken")
+	var calls int32
+	err = client.Walk(group, func(node *api.WalkNode) error {
+		atomic.AddInt32(&calls, 1)
+		return broken
+	}, &api.WalkOptions{Workers: 4})
+	t.So(err == broken, ShouldBeTrue)
+	t.So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+
+	err = client.Walk(client.Container(api.SessionContainer, "000000000000000000000000"), func(node *api.WalkNode) error {
+		return nil
+	}, nil)
+	t.So(api.IsNotFound(err), ShouldBeTrue)
+}
+
+func (t *F) TestWalkCollection() {
+	server := fake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	_, acquisitionIds := makeHierarchy(t, client)
+
+	collectionId, _, err := client.AddCollection(&api.Collection{Name: "c"})
+	t.So(err, ShouldBeNil)
+	_, err = client.AddAcquisitionsToCollection(collectionId, acquisitionIds[:1])
+	t.So(err, ShouldBeNil)
+
+	// Only the acquisitions in the collection are walked
+	var seen []string
+	err = client.Walk(client.Container(api.CollectionContainer, collectionId), func(node *api.WalkNode) error {
+		seen = append(seen, label(node))
+		return nil
+	}, nil)
+	t.So(err, ShouldBeNil)
+	t.So(seen, ShouldResemble, []string{"c", "p1s1", "p1s1a1"})
+}
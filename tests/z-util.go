@@ -4,24 +4,69 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"flywheel.io/sdk/api"
 )
 
 func init() {
-	// Deterministically generating random numbers in parallel?
-	// Sounds like a problem for another day.
-	// Would probably use stack pointers, or ticket numbers, or something.
 	rand.Seed(time.Now().UnixNano())
 }
 
+// SdkTestSeed is the environment variable that varies the names made while recording or replaying.
+// Change it to record again against an API that still holds the containers of an earlier recording.
+const SdkTestSeed = "SdkTestSeed"
+
+// Recordings are matched by URL, and tests compare what they sent with what they get back,
+// so while recording or replaying, names must be the same on every run.
+var _, deterministicNames = os.LookupEnv(api.SdkReplayKey)
+
+var nameMutex sync.Mutex
+var nameCounts = map[string]int{}
+
+// nameSource returns the random source for a name: a fresh one normally, or while recording or replaying, one seeded by where
+// in the tests the name is made, and how many times it has been made there before. Tests run in parallel, but each runs in order.
+func nameSource() func(int) int {
+	if !deterministicNames {
+		return rand.Intn
+	}
+
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	hash := fnv.New64a()
+	hash.Write([]byte(os.Getenv(SdkTestSeed)))
+	for {
+		frame, more := frames.Next()
+		if strings.Contains(frame.File, "/tests/") {
+			hash.Write([]byte(frame.Function + ":" + strconv.Itoa(frame.Line) + "\n"))
+		}
+		if !more {
+			break
+		}
+	}
+	site := strconv.FormatUint(hash.Sum64(), 16)
+
+	nameMutex.Lock()
+	nameCounts[site]++
+	count := nameCounts[site]
+	nameMutex.Unlock()
+
+	hash.Write([]byte(strconv.Itoa(count)))
+	return rand.New(rand.NewSource(int64(hash.Sum64()))).Intn
+}
+
 // Based on a string from api.py, this user ID regex is a superset of the allowable format of a group ID, user ID, note ID, database ID.
 // It's not the consumer's business what the format of the database keys are, so we do not have a mongo DB ID regex.
 var idRegex = regexp.MustCompile("^[0-9a-zA-Z.@_-]+$")
@@ -30,9 +75,10 @@ var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 var hexRunes = []rune("0123456789abcdef")
 
 func RandStringOfLength(n int, runes []rune) string {
+	intn := nameSource()
 	b := make([]rune, n)
 	for i := range b {
-		b[i] = runes[rand.Intn(len(runes))]
+		b[i] = runes[intn(len(runes))]
 	}
 	return string(b)
 }
@@ -184,6 +230,14 @@ func HttpResponseLengthSetter(len int64) httpResponseWrapperFunc {
 	}
 }
 
+// makeServerClient returns a client for a local test server, such as an httptest.Server.
+func makeServerClient(server *httptest.Server, options ...api.ApiKeyClientOption) *api.Client {
+	address := strings.TrimPrefix(server.URL, "http://")
+	options = append([]api.ApiKeyClientOption{api.InsecureUsePlaintext}, options...)
+
+	return api.NewApiKeyClient(address+":change-me", options...)
+}
+
 // Buffer does not implement close; ioutil does not implement NopWriteCloser
 type nopWriteCloser struct {
 	io.Writer